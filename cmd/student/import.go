@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/smartcraze/student-api/internal/auth"
+	"github.com/smartcraze/student-api/internal/config"
+	httphandler "github.com/smartcraze/student-api/internal/http"
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/internal/validation"
+)
+
+const importUsage = "usage: student import [-mode all-or-nothing|best-effort] [-format csv|ndjson] <file>"
+
+// runImport creates the students in a CSV or NDJSON file, the same way
+// POST /api/students/import does.
+func runImport(ctx context.Context, db storage.Storage, policy config.PasswordPolicy, studentRules config.StudentRules, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	mode := flags.String("mode", string(httphandler.ImportAllOrNothing), "all-or-nothing or best-effort")
+	format := flags.String("format", "", "csv or ndjson, guessed from the file extension by default")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		return errors.New(importUsage)
+	}
+
+	importMode := httphandler.ImportMode(*mode)
+	if !importMode.Valid() {
+		return errors.New(importUsage)
+	}
+
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	var contentType string
+	switch *format {
+	case "csv":
+		contentType = "text/csv"
+	case "ndjson", "jsonl":
+		contentType = "application/x-ndjson"
+	default:
+		return fmt.Errorf("unknown import format %q, use -format csv or -format ndjson", *format)
+	}
+
+	passwords, err := auth.NewPasswords(policy)
+	if err != nil {
+		return err
+	}
+
+	rules, err := validation.NewStudentRules(studentRules)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	rows, err := httphandler.ParseImport(contentType, file)
+	if err != nil {
+		return err
+	}
+
+	// changes made from the command line show up as "cli" in the audit log
+	ctx = storage.WithActor(ctx, storage.Actor{Name: "cli"})
+
+	report, err := httphandler.ImportStudents(ctx, db, passwords, rules, rows, importMode)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range report.Rows {
+		if row.Status != httphandler.ImportCreated {
+			fmt.Printf("line %d: %s: %s\n", row.Line, row.Status, strings.Join(row.Errors, ", "))
+		}
+	}
+	fmt.Printf("%d rows: %d created, %d duplicate, %d invalid\n", report.Total, report.Created, report.Duplicates, report.Invalid)
+
+	if !report.Committed {
+		return errors.New("import rolled back, no students were created")
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"log/slog"
@@ -12,9 +13,14 @@ import (
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/smartcraze/student-api/internal/auth"
 	"github.com/smartcraze/student-api/internal/config"
 	httphandler "github.com/smartcraze/student-api/internal/http"
+	"github.com/smartcraze/student-api/internal/i18n"
+	"github.com/smartcraze/student-api/internal/lifecycle"
+	"github.com/smartcraze/student-api/internal/notify"
 	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/internal/validation"
 )
 
 func main() {
@@ -23,42 +29,164 @@ func main() {
 	}
 
 	cfg := config.MustLoad()
-	// database setup
-	connStr := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.User,
-		cfg.Database.Password,
-		cfg.Database.DBName,
-		cfg.Database.SSLMode,
-	)
-	db, err := storage.NewPostgresStorage(connStr)
+	// storage setup
+	db, err := openStorage(cfg)
 	if err != nil {
 		log.Fatalf("failed to initialize database: %v", err)
 	}
 	defer db.Close()
 
-	slog.Info("Database connected successfully")
+	// subcommands, e.g. `student migrate up`
+	if !flag.Parsed() {
+		flag.Parse()
+	}
+	if args := flag.Args(); len(args) > 0 {
+		switch args[0] {
+		case "migrate":
+			if err := runMigrate(context.Background(), db, args[1:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "role":
+			if err := runRole(context.Background(), db, args[1:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "import":
+			if err := runImport(context.Background(), db, cfg.PasswordPolicy, cfg.StudentRules, args[1:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "backfill-phones":
+			if err := runBackfillPhones(context.Background(), db, cfg.StudentRules, args[1:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "routes":
+			if err := printRoutes(); err != nil {
+				log.Fatal(err)
+			}
+			return
+		default:
+			log.Fatalf("unknown command: %s", args[0])
+		}
+	}
+
+	if migratable, ok := db.(storage.Migratable); ok && !cfg.SkipMigrations {
+		migrator, err := migratable.Migrator()
+		if err != nil {
+			log.Fatalf("failed to load migrations: %v", err)
+		}
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			log.Fatalf("failed to run migrations: %v", err)
+		}
+		for _, m := range applied {
+			slog.Info("Applied migration", slog.Int64("version", m.Version), slog.String("name", m.Name))
+		}
+	}
+
+	tokens, err := auth.NewTokenService(cfg.Auth)
+	if err != nil {
+		log.Fatalf("failed to set up auth: %v", err)
+	}
+
+	passwords, err := auth.NewPasswords(cfg.PasswordPolicy)
+	if err != nil {
+		log.Fatalf("failed to set up password policy: %v", err)
+	}
+
+	studentRules, err := validation.NewStudentRules(cfg.StudentRules)
+	if err != nil {
+		log.Fatalf("failed to set up student rules: %v", err)
+	}
+
+	catalog, err := i18n.Load(cfg.LocalesDir)
+	if err != nil {
+		log.Fatalf("failed to load translations: %v", err)
+	}
+	if err := httphandler.RegisterTranslations(catalog); err != nil {
+		log.Fatalf("failed to register translations: %v", err)
+	}
+	slog.Info("Loaded translations", slog.Any("locales", catalog.Locales()))
+
+	notifier, err := notify.New(cfg.Notifier.Driver, cfg.Notifier.FilePath)
+	if err != nil {
+		log.Fatalf("failed to set up notifier: %v", err)
+	}
+
+	machine, err := lifecycle.New(cfg.Lifecycle, db, notifier)
+	if err != nil {
+		log.Fatalf("failed to set up status transitions: %v", err)
+	}
+
+	// purge the trash in the background until shutdown
+	jobs, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	if cfg.Trash.Retention > 0 {
+		go runTrashPurge(jobs, db, cfg.Trash.Retention, cfg.Trash.PurgeInterval)
+	}
 
 	//setup the router
 	router := http.NewServeMux()
 
-	router.HandleFunc("GET /", func(w http.ResponseWriter, r *http.Request) {
+	// every route goes through its access policy, see httphandler.Policies
+	handle := func(pattern string, handler http.HandlerFunc) {
+		router.Handle(pattern, httphandler.Protect(pattern, tokens, db, handler))
+	}
+
+	handle("GET /", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("Welcome to student api"))
 	})
 
-	router.HandleFunc("POST /api/student/create", httphandler.CreateStudentHandler(db))
-	router.HandleFunc("GET /api/student/{id}", httphandler.GetStudentHandler(db))
-	router.HandleFunc("PUT /api/student/{id}", httphandler.UpdateStudentHandler(db))
-	router.HandleFunc("DELETE /api/student/{id}", httphandler.DeleteStudentHandler(db))
-	router.HandleFunc("GET /api/students", httphandler.ListStudentsHandler(db))
-	router.HandleFunc("GET /api/student/search", httphandler.GetStudentByEmailHandler(db))
+	handle("POST /api/student/create", httphandler.CreateStudentHandler(db, passwords, studentRules))
+	handle("GET /api/student/{id}", httphandler.GetStudentHandler(db))
+	handle("PUT /api/student/{id}", httphandler.UpdateStudentHandler(db, studentRules))
+	handle("PATCH /api/student/{id}", httphandler.PatchStudentHandler(db, studentRules))
+	handle("DELETE /api/student/{id}", httphandler.DeleteStudentHandler(db))
+	handle("GET /api/students", httphandler.ListStudentsHandler(db))
+	handle("GET /api/students/export", httphandler.ExportStudentsHandler(db))
+	handle("POST /api/students/import", httphandler.ImportStudentsHandler(db, passwords, studentRules))
+	handle("GET /api/students/trash", httphandler.ListDeletedStudentsHandler(db))
+	handle("POST /api/student/{id}/restore", httphandler.RestoreStudentHandler(db))
+	handle("DELETE /api/students/trash/{id}", httphandler.PurgeStudentHandler(db))
+	handle("GET /api/student/search", httphandler.GetStudentByEmailHandler(db))
+	handle("PUT /api/student/{id}/password", httphandler.ChangePasswordHandler(db, passwords))
+	handle("GET /api/student/{id}/history", httphandler.StudentHistoryHandler(db))
+	handle("POST /api/student/{id}/transitions", httphandler.TransitionStudentHandler(machine))
+	handle("GET /api/student/{id}/transitions", httphandler.TransitionHistoryHandler(db))
+	handle("GET /api/audit", httphandler.AuditLogHandler(db))
+
+	handle("GET /api/courses", httphandler.ListCoursesHandler(db))
+	handle("POST /api/courses", httphandler.CreateCourseHandler(db))
+	handle("GET /api/courses/{id}", httphandler.GetCourseHandler(db))
+	handle("PUT /api/courses/{id}", httphandler.UpdateCourseHandler(db))
+	handle("DELETE /api/courses/{id}", httphandler.DeleteCourseHandler(db))
+	handle("GET /api/terms", httphandler.ListTermsHandler(db))
+	handle("POST /api/terms", httphandler.CreateTermHandler(db))
+	handle("GET /api/courses/{id}/sections", httphandler.ListSectionsHandler(db))
+	handle("POST /api/courses/{id}/sections", httphandler.CreateSectionHandler(db))
+	handle("GET /api/sections/{id}", httphandler.GetSectionHandler(db))
+	handle("GET /api/sections/{id}/roster", httphandler.SectionRosterHandler(db))
+	handle("POST /api/student/{id}/enrollments", httphandler.EnrollHandler(db, notifier))
+	handle("GET /api/student/{id}/enrollments", httphandler.ListEnrollmentsHandler(db))
+	handle("DELETE /api/student/{id}/enrollments/{enrollment_id}", httphandler.DropEnrollmentHandler(db, notifier))
+	handle("PUT /api/student/{id}/enrollments/{enrollment_id}/grade", httphandler.GradeEnrollmentHandler(db))
+	handle("GET /api/courses/{id}/prerequisites", httphandler.GetPrerequisitesHandler(db))
+	handle("PUT /api/courses/{id}/prerequisites", httphandler.SetPrerequisitesHandler(db))
+	handle("PUT /api/sections/{id}/meetings", httphandler.SetSectionMeetingsHandler(db))
+
+	handle("POST /api/auth/login", httphandler.LoginHandler(db, tokens, passwords))
+	handle("POST /api/auth/refresh", httphandler.RefreshTokenHandler(db, tokens))
+	handle("POST /api/auth/logout", httphandler.LogoutHandler(db))
+	handle("POST /api/auth/forgot", httphandler.ForgotPasswordHandler(db, tokens, notifier, cfg.Auth.PasswordResetURL))
+	handle("POST /api/auth/reset", httphandler.ResetPasswordHandler(db, passwords))
 
 	// setup server
 
 	server := http.Server{
 		Addr:    cfg.Addr,
-		Handler: router,
+		Handler: httphandler.RequestID(httphandler.Localize(catalog, router)),
 	}
 	fmt.Printf("server is started:  %s", cfg.Addr)
 
@@ -74,6 +202,7 @@ func main() {
 	<-done // blocking
 
 	slog.Info("Shutting Down the server")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
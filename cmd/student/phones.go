@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/smartcraze/student-api/internal/config"
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/internal/validation"
+)
+
+const phonesUsage = "usage: student backfill-phones [-dry-run]"
+
+// runBackfillPhones converts the bare digits migration 0009 left in
+// phone_number to E.164, with the default country code the API gives
+// numbers written without one. It is safe to run again, numbers that
+// already start with a + are skipped.
+func runBackfillPhones(ctx context.Context, db storage.Storage, studentRules config.StudentRules, args []string) error {
+	flags := flag.NewFlagSet("backfill-phones", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "print the conversions without saving them")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 {
+		return errors.New(phonesUsage)
+	}
+
+	rules, err := validation.NewStudentRules(studentRules)
+	if err != nil {
+		return err
+	}
+
+	// collected first, the sqlite backend can't write while it exports
+	var bare []*storage.Student
+	err = db.ExportStudents(ctx, storage.ListOptions{}, func(student *storage.Student) error {
+		if student.PhoneNumber != "" && !strings.HasPrefix(student.PhoneNumber, "+") {
+			bare = append(bare, student)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("list students: %w", err)
+	}
+	if len(bare) > 0 && studentRules.DefaultCountryCode == "" {
+		return fmt.Errorf("%d phone numbers have no country code, set STUDENT_DEFAULT_COUNTRY_CODE", len(bare))
+	}
+
+	// changes made from the command line show up as "cli" in the audit log
+	ctx = storage.WithActor(ctx, storage.Actor{Name: "cli"})
+
+	converted, skipped := 0, 0
+	for _, student := range bare {
+		phone := student.PhoneNumber
+		if err := rules.Normalize(validation.StudentFields{PhoneNumber: &phone}); err != nil {
+			fmt.Printf("%s: skipped %s, %v\n", student.Email, student.PhoneNumber, err)
+			skipped++
+			continue
+		}
+
+		fmt.Printf("%s: %s -> %s\n", student.Email, student.PhoneNumber, phone)
+		if *dryRun {
+			converted++
+			continue
+		}
+		_, err := db.UpdateStudent(ctx, student.ID, storage.StudentUpdate{PhoneNumber: &phone, Version: student.Version})
+		if err != nil {
+			return fmt.Errorf("update %s: %w", student.Email, err)
+		}
+		converted++
+	}
+
+	if *dryRun {
+		fmt.Printf("%d phone numbers would be converted, %d skipped\n", converted, skipped)
+	} else {
+		fmt.Printf("%d phone numbers converted, %d skipped\n", converted, skipped)
+	}
+	return nil
+}
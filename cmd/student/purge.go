@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/smartcraze/student-api/internal/storage"
+)
+
+// runTrashPurge permanently deletes students that have been in the trash
+// longer than retention, every interval until ctx is cancelled.
+func runTrashPurge(ctx context.Context, db storage.Storage, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := db.PurgeDeletedStudents(ctx, time.Now().Add(-retention))
+		if err != nil {
+			slog.Error("failed to purge deleted students", slog.String("error", err.Error()))
+		} else if purged > 0 {
+			slog.Info("Purged deleted students", slog.Int("count", purged))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
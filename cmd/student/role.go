@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/smartcraze/student-api/internal/storage"
+)
+
+const roleUsage = "usage: student role <email> admin|staff|student"
+
+// runRole changes the role of an account, e.g. to promote the first admin.
+func runRole(ctx context.Context, db storage.Storage, args []string) error {
+	if len(args) != 2 {
+		return errors.New(roleUsage)
+	}
+
+	role := storage.Role(args[1])
+	if !role.Valid() {
+		return errors.New(roleUsage)
+	}
+
+	// changes made from the command line show up as "cli" in the audit log
+	ctx = storage.WithActor(ctx, storage.Actor{Name: "cli"})
+
+	student, err := db.GetStudentByEmail(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := db.SetStudentRole(ctx, student.ID, role); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s is now %s, the change applies to their next request\n", student.Email, role)
+	return nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	httphandler "github.com/smartcraze/student-api/internal/http"
+)
+
+// printRoutes prints the access policy table for auditing.
+func printRoutes() error {
+	patterns := make([]string, 0, len(httphandler.Policies))
+	for pattern := range httphandler.Policies {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ROUTE\tACCESS")
+	for _, pattern := range patterns {
+		fmt.Fprintf(w, "%s\t%s\n", pattern, httphandler.Policies[pattern])
+	}
+	return w.Flush()
+}
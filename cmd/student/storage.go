@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/smartcraze/student-api/internal/config"
+	"github.com/smartcraze/student-api/internal/storage"
+)
+
+func openStorage(cfg *config.Config) (storage.Storage, error) {
+	switch cfg.StorageDriver {
+	case "memory":
+		slog.Info("Using in-memory storage, data will be lost on shutdown")
+		return storage.NewMemoryStorage(), nil
+	case "sqlite":
+		lite, err := storage.NewSQLiteStorage(cfg.StoragePath)
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("Using sqlite storage", slog.String("path", cfg.StoragePath))
+		return lite, nil
+	case "postgres":
+		connStr := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+			cfg.Database.Host,
+			cfg.Database.Port,
+			cfg.Database.User,
+			cfg.Database.Password,
+			cfg.Database.DBName,
+			cfg.Database.SSLMode,
+		)
+		pg, err := storage.NewPostgresStorage(connStr)
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("Database connected successfully")
+		return pg, nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", cfg.StorageDriver)
+	}
+}
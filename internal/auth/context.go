@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/smartcraze/student-api/internal/storage"
+)
+
+// Identity is the authenticated caller of a request.
+type Identity struct {
+	StudentID int64
+	Email     string
+	Role      storage.Role
+}
+
+type identityKey struct{}
+
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFrom returns the caller put in the context by the auth middleware,
+// or nil for anonymous requests.
+func IdentityFrom(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
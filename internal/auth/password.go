@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/smartcraze/student-api/internal/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcrypt ignores everything after the first 72 bytes
+const maxPasswordBytes = 72
+
+// PasswordViolation is a rule a password breaks.
+type PasswordViolation struct {
+	// Rule is min_length, max_bytes, upper, lower, digit, symbol or common
+	Rule string
+	// Param is the limit of the min_length and max_bytes rules
+	Param   string
+	Message string
+}
+
+// PasswordPolicyError lists every rule a password breaks.
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return "password " + strings.Join(messages, ", ")
+}
+
+// Passwords checks new passwords against the configured policy and hashes them.
+type Passwords struct {
+	policy config.PasswordPolicy
+	cost   int
+	common map[string]struct{}
+}
+
+func NewPasswords(policy config.PasswordPolicy) (*Passwords, error) {
+	cost := policy.BcryptCost
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	p := &Passwords{policy: policy, cost: cost, common: make(map[string]struct{})}
+	if policy.CommonPasswordsFile != "" {
+		if err := p.loadCommon(policy.CommonPasswordsFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// loadCommon reads the breached/common password list, one password per line.
+func (p *Passwords) loadCommon(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open common password list: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.common[strings.ToLower(line)] = struct{}{}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read common password list: %w", err)
+	}
+	return nil
+}
+
+// Validate returns a *PasswordPolicyError if the password doesn't meet the policy.
+func (p *Passwords) Validate(password string) error {
+	var violations []PasswordViolation
+	violate := func(rule, param, message string) {
+		violations = append(violations, PasswordViolation{Rule: rule, Param: param, Message: message})
+	}
+
+	if len([]rune(password)) < p.policy.MinLength {
+		violate("min_length", strconv.Itoa(p.policy.MinLength), fmt.Sprintf("must be at least %d characters", p.policy.MinLength))
+	}
+	if len(password) > maxPasswordBytes {
+		violate("max_bytes", strconv.Itoa(maxPasswordBytes), fmt.Sprintf("must be at most %d bytes", maxPasswordBytes))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.policy.RequireUpper && !upper {
+		violate("upper", "", "must contain an uppercase letter")
+	}
+	if p.policy.RequireLower && !lower {
+		violate("lower", "", "must contain a lowercase letter")
+	}
+	if p.policy.RequireDigit && !digit {
+		violate("digit", "", "must contain a digit")
+	}
+	if p.policy.RequireSymbol && !symbol {
+		violate("symbol", "", "must contain a symbol")
+	}
+
+	if _, ok := p.common[strings.ToLower(password)]; ok {
+		violate("common", "", "is too common")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func (p *Passwords) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), p.cost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// NeedsRehash reports whether hash was made with a lower cost than the configured one.
+func (p *Passwords) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return false
+	}
+	return cost < p.cost
+}
@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/smartcraze/student-api/internal/config"
+	"github.com/smartcraze/student-api/internal/storage"
+)
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Claims are the claims carried by an access token. The subject is the student id.
+type Claims struct {
+	Email string       `json:"email"`
+	Role  storage.Role `json:"role"`
+	// Generation is the student's TokenGeneration when the token was issued
+	Generation int64 `json:"gen"`
+	jwt.RegisteredClaims
+}
+
+func (c *Claims) StudentID() (int64, error) {
+	return strconv.ParseInt(c.Subject, 10, 64)
+}
+
+type TokenService struct {
+	issuer     string
+	keyID      string
+	keys       map[string][]byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	resetTTL   time.Duration
+}
+
+func NewTokenService(cfg config.Auth) (*TokenService, error) {
+	if _, ok := cfg.SigningKeys[cfg.SigningKeyID]; !ok {
+		return nil, fmt.Errorf("signing key %q is not configured", cfg.SigningKeyID)
+	}
+
+	keys := make(map[string][]byte, len(cfg.SigningKeys))
+	for id, secret := range cfg.SigningKeys {
+		if len(secret) < 32 {
+			return nil, fmt.Errorf("signing key %q must be at least 32 bytes", id)
+		}
+		keys[id] = []byte(secret)
+	}
+
+	return &TokenService{
+		issuer:     cfg.Issuer,
+		keyID:      cfg.SigningKeyID,
+		keys:       keys,
+		accessTTL:  cfg.AccessTokenTTL,
+		refreshTTL: cfg.RefreshTokenTTL,
+		resetTTL:   cfg.PasswordResetTTL,
+	}, nil
+}
+
+// IssueAccessToken returns a signed JWT for the student and when it expires.
+func (t *TokenService) IssueAccessToken(student *storage.Student) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(t.accessTTL)
+
+	claims := Claims{
+		Email:      student.Email,
+		Role:       student.Role,
+		Generation: student.TokenGeneration,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    t.issuer,
+			Subject:   strconv.FormatInt(student.ID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = t.keyID
+
+	signed, err := token.SignedString(t.keys[t.keyID])
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	return signed, expiresAt, nil
+}
+
+func (t *TokenService) ParseAccessToken(tokenStr string) (*Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(tokenStr, &claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := t.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		return key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(t.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return &claims, nil
+}
+
+// NewRefreshToken returns a random opaque refresh token, the hash to store for
+// it and when it expires.
+func (t *TokenService) NewRefreshToken() (token, hash string, expiresAt time.Time, err error) {
+	return newOpaqueToken(t.refreshTTL)
+}
+
+// NewPasswordResetToken is like NewRefreshToken for password reset tokens.
+func (t *TokenService) NewPasswordResetToken() (token, hash string, expiresAt time.Time, err error) {
+	return newOpaqueToken(t.resetTTL)
+}
+
+func newOpaqueToken(ttl time.Duration) (token, hash string, expiresAt time.Time, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", time.Time{}, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), time.Now().Add(ttl), nil
+}
+
+// HashToken is how opaque tokens are looked up in storage without storing them.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -22,12 +23,81 @@ type Database struct {
 	SSLMode  string `yaml:"sslmode"`
 }
 
+type Auth struct {
+	Issuer string `yaml:"issuer" env:"AUTH_ISSUER" env-default:"student-api"`
+	// HMAC secrets by key id. Tokens are signed with SigningKeyID and verified
+	// with whichever key their kid header names, so old keys can be kept during rotation.
+	SigningKeys      map[string]string `yaml:"signing_keys" env:"AUTH_SIGNING_KEYS" env-required:"true"`
+	SigningKeyID     string            `yaml:"signing_key_id" env:"AUTH_SIGNING_KEY_ID" env-required:"true"`
+	AccessTokenTTL   time.Duration     `yaml:"access_token_ttl" env:"AUTH_ACCESS_TOKEN_TTL" env-default:"15m"`
+	RefreshTokenTTL  time.Duration     `yaml:"refresh_token_ttl" env:"AUTH_REFRESH_TOKEN_TTL" env-default:"720h"`
+	PasswordResetTTL time.Duration     `yaml:"password_reset_ttl" env:"AUTH_PASSWORD_RESET_TTL" env-default:"1h"`
+	// where the reset link in notifications points, ?token= is appended
+	PasswordResetURL string `yaml:"password_reset_url" env:"AUTH_PASSWORD_RESET_URL"`
+}
+
+type PasswordPolicy struct {
+	MinLength     int  `yaml:"min_length" env:"PASSWORD_MIN_LENGTH" env-default:"8"`
+	RequireUpper  bool `yaml:"require_upper" env:"PASSWORD_REQUIRE_UPPER"`
+	RequireLower  bool `yaml:"require_lower" env:"PASSWORD_REQUIRE_LOWER"`
+	RequireDigit  bool `yaml:"require_digit" env:"PASSWORD_REQUIRE_DIGIT"`
+	RequireSymbol bool `yaml:"require_symbol" env:"PASSWORD_REQUIRE_SYMBOL"`
+	// one password per line, matched case-insensitively; empty disables the check
+	CommonPasswordsFile string `yaml:"common_passwords_file" env:"PASSWORD_COMMON_FILE"`
+	// hashes below this cost are upgraded the next time the student logs in
+	BcryptCost int `yaml:"bcrypt_cost" env:"PASSWORD_BCRYPT_COST" env-default:"12"`
+}
+
+type StudentRules struct {
+	// country calling code for phone numbers written without + or 00, e.g. "1";
+	// empty rejects them
+	DefaultCountryCode string `yaml:"default_country_code" env:"STUDENT_DEFAULT_COUNTRY_CODE"`
+	// regular expression the registration number has to match, e.g. "^20[0-9]{6}$"
+	RegistrationNoPattern string `yaml:"registration_no_pattern" env:"STUDENT_REG_NO_PATTERN"`
+	// "luhn" requires the last digit of the registration number to be a Luhn check digit
+	RegistrationNoChecksum string `yaml:"registration_no_checksum" env:"STUDENT_REG_NO_CHECKSUM"`
+}
+
+// Lifecycle configures the student status transitions. Both maps are keyed
+// by transition, e.g. "applicant->active", and "*" applies to every one.
+type Lifecycle struct {
+	// guards that have to pass before the transition, e.g. ["admission_date"]
+	Guards map[string][]string `yaml:"guards"`
+	// hooks that run once the transition is made, e.g. ["notify"]
+	Hooks map[string][]string `yaml:"hooks"`
+}
+
+type Trash struct {
+	// deleted students are purged for good after this long, 0 keeps them forever
+	Retention time.Duration `yaml:"retention" env:"TRASH_RETENTION" env-default:"720h"`
+	// how often the purge job runs
+	PurgeInterval time.Duration `yaml:"purge_interval" env:"TRASH_PURGE_INTERVAL" env-default:"1h"`
+}
+
+type Notifier struct {
+	// "log" or "file"
+	Driver   string `yaml:"driver" env:"NOTIFIER_DRIVER" env-default:"log"`
+	FilePath string `yaml:"file_path" env:"NOTIFIER_FILE_PATH" env-default:"storage/outbox.txt"`
+}
+
 // struct tags serialisation
 type Config struct {
 	Env         string `yaml:"env" env:"ENV" env-required:"true" env-default:"production"`
 	StoragePath string `yaml:"storage_path" env-required:"true"`
-	HTTPServer  `yaml:"http_server"`
-	Database    `yaml:"database" env-required:"true"`
+	// storage backend: "postgres", "sqlite" (uses StoragePath) or "memory"
+	StorageDriver string `yaml:"storage_driver" env:"STORAGE_DRIVER" env-default:"postgres"`
+	// don't apply pending migrations when the server starts, run `student migrate up` instead
+	SkipMigrations bool `yaml:"skip_migrations" env:"SKIP_MIGRATIONS"`
+	// directory of <locale>.yaml translation catalogs, e.g. es.yaml
+	LocalesDir     string `yaml:"locales_dir" env:"LOCALES_DIR" env-default:"config/locales"`
+	HTTPServer     `yaml:"http_server"`
+	Database       `yaml:"database" env-required:"true"`
+	Auth           `yaml:"auth"`
+	PasswordPolicy `yaml:"password_policy"`
+	StudentRules   `yaml:"student_rules"`
+	Lifecycle      `yaml:"lifecycle"`
+	Notifier       `yaml:"notifier"`
+	Trash          `yaml:"trash"`
 }
 
 func MustLoad() *Config {
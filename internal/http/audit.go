@@ -0,0 +1,144 @@
+package httphandler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/utils/response"
+)
+
+type AuditEntryResponse struct {
+	ID        int64                          `json:"id"`
+	ActorID   *int64                         `json:"actor_id"`
+	Actor     string                         `json:"actor"`
+	Action    string                         `json:"action"`
+	StudentID int64                          `json:"student_id"`
+	Changes   map[string]storage.FieldChange `json:"changes"`
+	RequestID string                         `json:"request_id,omitempty"`
+	CreatedAt string                         `json:"created_at"`
+}
+
+type ListAuditEntriesResponse struct {
+	Entries []*AuditEntryResponse `json:"entries"`
+	Total   int                   `json:"total"`
+	Limit   int                   `json:"limit"`
+	Offset  int                   `json:"offset"`
+}
+
+func (l ListAuditEntriesResponse) Items() any {
+	return l.Entries
+}
+
+// StudentHistoryHandler lists the changes to one student, newest first. It
+// also works for students in the trash or already purged.
+func StudentHistoryHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get student ID from URL path parameter
+		idStr := r.PathValue("id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, "invalid student ID")
+			return
+		}
+
+		filter, err := parseAuditFilter(r.URL.Query())
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		filter.StudentID = &id
+
+		listAudit(w, r, store, filter)
+	}
+}
+
+// AuditLogHandler lists the whole audit log, newest first.
+func AuditLogHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := parseAuditFilter(r.URL.Query())
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		filter.StudentID, err = parseIDParam(r.URL.Query(), "student_id")
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		listAudit(w, r, store, filter)
+	}
+}
+
+func listAudit(w http.ResponseWriter, r *http.Request, store storage.Storage, filter storage.AuditFilter) {
+	limit, offset, err := parsePage(r.URL.Query())
+	if err != nil {
+		response.Error(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entries, err := store.ListAuditEntries(r.Context(), filter, limit, offset)
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+
+	total, err := store.CountAuditEntries(r.Context(), filter)
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+
+	resp := ListAuditEntriesResponse{
+		Entries: make([]*AuditEntryResponse, 0, len(entries)),
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, &AuditEntryResponse{
+			ID:        entry.ID,
+			ActorID:   entry.ActorID,
+			Actor:     entry.Actor,
+			Action:    string(entry.Action),
+			StudentID: entry.StudentID,
+			Changes:   entry.Changes,
+			RequestID: entry.RequestID,
+			CreatedAt: entry.CreatedAt.UTC().Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	response.Write(w, r, http.StatusOK, resp)
+}
+
+// parseAuditFilter reads the actor_id, action, request_id, from and to parameters.
+func parseAuditFilter(query url.Values) (storage.AuditFilter, error) {
+	var filter storage.AuditFilter
+	var err error
+
+	if filter.ActorID, err = parseIDParam(query, "actor_id"); err != nil {
+		return filter, err
+	}
+
+	if action := query.Get("action"); action != "" {
+		filter.Action = storage.AuditAction(action)
+		if !filter.Action.Valid() {
+			return filter, fmt.Errorf("invalid action parameter, must be create, update, delete, restore, purge or transition")
+		}
+	}
+
+	filter.RequestID = query.Get("request_id")
+
+	if filter.From, err = parseTimeParam(query, "from", false); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseTimeParam(query, "to", true); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,90 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/smartcraze/student-api/internal/auth"
+	"github.com/smartcraze/student-api/internal/notify"
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/internal/validation"
+	"github.com/smartcraze/student-api/utils/response"
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ForgotPasswordHandler sends a password reset token through the notifier.
+// resetURL, when set, is used to build a link with the token in the message.
+func ForgotPasswordHandler(store storage.Storage, tokens *auth.TokenService, notifier notify.Notifier, resetURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req ForgotPasswordRequest
+
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Validate request
+		if err := validate.Struct(req); err != nil {
+			response.ValidationFailed(w, r, response.FieldErrors(r.Context(), err))
+			return
+		}
+
+		// Same answer whether or not the email exists, so accounts can't be probed
+		accepted := response.Response{
+			Status: response.StatusOK,
+			Error:  "if the email is registered, a password reset link has been sent",
+		}
+
+		student, err := store.GetStudentByEmail(r.Context(), validation.NormalizeEmail(req.Email))
+		if errors.Is(err, storage.ErrNotFound) {
+			response.Write(w, r, http.StatusAccepted, accepted)
+			return
+		}
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		token, hash, expiresAt, err := tokens.NewPasswordResetToken()
+		if err != nil {
+			response.Error(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		err = store.CreatePasswordResetToken(r.Context(), &storage.PasswordResetToken{
+			StudentID: student.ID,
+			TokenHash: hash,
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		body := fmt.Sprintf("Use this token to reset your password: %s\nIt expires at %s.", token, expiresAt.Format(time.RFC1123))
+		if resetURL != "" {
+			body = fmt.Sprintf("Reset your password here: %s?token=%s\nThe link expires at %s.",
+				resetURL, url.QueryEscape(token), expiresAt.Format(time.RFC1123))
+		}
+
+		err = notifier.Notify(r.Context(), notify.Message{
+			To:      student.Email,
+			Subject: "Reset your password",
+			Body:    body,
+		})
+		if err != nil {
+			response.Error(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		response.Write(w, r, http.StatusAccepted, accepted)
+	}
+}
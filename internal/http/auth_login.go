@@ -0,0 +1,110 @@
+package httphandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/smartcraze/student-api/internal/auth"
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/internal/validation"
+	"github.com/smartcraze/student-api/utils/response"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// issueTokens creates an access token and a stored refresh token for the student.
+func issueTokens(ctx context.Context, store storage.Storage, tokens *auth.TokenService, student *storage.Student) (*TokenResponse, error) {
+	accessToken, expiresAt, err := tokens.IssueAccessToken(student)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, hash, refreshExpiresAt, err := tokens.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = store.CreateRefreshToken(ctx, &storage.RefreshToken{
+		StudentID: student.ID,
+		TokenHash: hash,
+		ExpiresAt: refreshExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(time.Until(expiresAt).Round(time.Second).Seconds()),
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func LoginHandler(store storage.Storage, tokens *auth.TokenService, passwords *auth.Passwords) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req LoginRequest
+
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Validate request
+		if err := validate.Struct(req); err != nil {
+			response.ValidationFailed(w, r, response.FieldErrors(r.Context(), err))
+			return
+		}
+
+		const invalidCredentials = "invalid email or password"
+
+		// Look up the student, an unknown email looks the same as a wrong password
+		student, err := store.GetStudentByEmail(r.Context(), validation.NormalizeEmail(req.Email))
+		if errors.Is(err, storage.ErrNotFound) {
+			response.Error(w, r, http.StatusUnauthorized, invalidCredentials)
+			return
+		}
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(student.Password), []byte(req.Password)); err != nil {
+			response.Error(w, r, http.StatusUnauthorized, invalidCredentials)
+			return
+		}
+
+		// Upgrade hashes made with an older bcrypt cost while we have the plain password
+		if passwords.NeedsRehash(student.Password) {
+			if hashedPassword, err := passwords.Hash(req.Password); err != nil {
+				slog.Error("failed to rehash password", slog.Int64("id", student.ID), slog.String("error", err.Error()))
+			} else if err := store.UpdateStudentPassword(r.Context(), student.ID, hashedPassword); err != nil {
+				slog.Error("failed to store rehashed password", slog.Int64("id", student.ID), slog.String("error", err.Error()))
+			}
+		}
+
+		resp, err := issueTokens(r.Context(), store, tokens, student)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.Write(w, r, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,41 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/smartcraze/student-api/internal/auth"
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/utils/response"
+)
+
+func LogoutHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req RefreshTokenRequest
+
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Validate request
+		if err := validate.Struct(req); err != nil {
+			response.ValidationFailed(w, r, response.FieldErrors(r.Context(), err))
+			return
+		}
+
+		// Logging out twice, or with an unknown token, is not an error
+		err = store.RevokeRefreshToken(r.Context(), auth.HashToken(req.RefreshToken))
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.Write(w, r, http.StatusOK, response.Response{
+			Status: response.StatusOK,
+			Error:  "logged out successfully",
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/smartcraze/student-api/internal/auth"
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/utils/response"
+)
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+func RefreshTokenHandler(store storage.Storage, tokens *auth.TokenService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req RefreshTokenRequest
+
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Validate request
+		if err := validate.Struct(req); err != nil {
+			response.ValidationFailed(w, r, response.FieldErrors(r.Context(), err))
+			return
+		}
+
+		const invalidToken = "invalid or expired refresh token"
+
+		hash := auth.HashToken(req.RefreshToken)
+		stored, err := store.GetRefreshToken(r.Context(), hash)
+		if errors.Is(err, storage.ErrNotFound) {
+			response.Error(w, r, http.StatusUnauthorized, invalidToken)
+			return
+		}
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		// A revoked token being used again means it leaked, end every session of the student
+		if stored.RevokedAt != nil {
+			if err := store.RevokeStudentRefreshTokens(r.Context(), stored.StudentID); err != nil {
+				writeStorageError(w, r, err)
+				return
+			}
+			response.Error(w, r, http.StatusUnauthorized, invalidToken)
+			return
+		}
+
+		if time.Now().After(stored.ExpiresAt) {
+			response.Error(w, r, http.StatusUnauthorized, invalidToken)
+			return
+		}
+
+		// Rotate: the old refresh token can only be used once
+		err = store.RevokeRefreshToken(r.Context(), hash)
+		if errors.Is(err, storage.ErrNotFound) {
+			response.Error(w, r, http.StatusUnauthorized, invalidToken)
+			return
+		}
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		student, err := store.GetStudentByID(r.Context(), stored.StudentID)
+		if errors.Is(err, storage.ErrNotFound) {
+			response.Error(w, r, http.StatusUnauthorized, invalidToken)
+			return
+		}
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		resp, err := issueTokens(r.Context(), store, tokens, student)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.Write(w, r, http.StatusOK, resp)
+	}
+}
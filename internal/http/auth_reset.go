@@ -0,0 +1,63 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/smartcraze/student-api/internal/auth"
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/utils/response"
+)
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+func ResetPasswordHandler(store storage.Storage, passwords *auth.Passwords) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req ResetPasswordRequest
+
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Validate request
+		if err := validate.Struct(req); err != nil {
+			response.ValidationFailed(w, r, response.FieldErrors(r.Context(), err))
+			return
+		}
+
+		// Enforce the password policy
+		if err := passwords.Validate(req.NewPassword); err != nil {
+			writePasswordError(w, r, "new_password", err)
+			return
+		}
+
+		// Hash password
+		hashedPassword, err := passwords.Hash(req.NewPassword)
+		if err != nil {
+			response.Error(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		// Uses up the token and signs out every session of the student
+		err = store.ResetPassword(r.Context(), auth.HashToken(req.Token), hashedPassword)
+		if errors.Is(err, storage.ErrNotFound) {
+			response.Error(w, r, http.StatusBadRequest, "invalid or expired reset token")
+			return
+		}
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.Write(w, r, http.StatusOK, response.Response{
+			Status: response.StatusOK,
+			Error:  "password reset successfully",
+		})
+	}
+}
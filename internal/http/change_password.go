@@ -0,0 +1,83 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/smartcraze/student-api/internal/auth"
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/utils/response"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+func ChangePasswordHandler(store storage.Storage, passwords *auth.Passwords) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get student ID from URL path parameter
+		idStr := r.PathValue("id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, "invalid student ID")
+			return
+		}
+
+		// Parse request body
+		var req ChangePasswordRequest
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Validate request
+		if err := validate.Struct(req); err != nil {
+			response.ValidationFailed(w, r, response.FieldErrors(r.Context(), err))
+			return
+		}
+
+		student, err := store.GetStudentByID(r.Context(), id)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(student.Password), []byte(req.CurrentPassword)); err != nil {
+			response.Error(w, r, http.StatusForbidden, "current password is incorrect")
+			return
+		}
+
+		// Enforce the password policy
+		if err := passwords.Validate(req.NewPassword); err != nil {
+			writePasswordError(w, r, "new_password", err)
+			return
+		}
+
+		// Hash password
+		hashedPassword, err := passwords.Hash(req.NewPassword)
+		if err != nil {
+			response.Error(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if err := store.UpdateStudentPassword(r.Context(), id, hashedPassword); err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		// Sign out every session, the current one included
+		if err := store.RevokeStudentRefreshTokens(r.Context(), id); err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.Write(w, r, http.StatusOK, response.Response{
+			Status: response.StatusOK,
+			Error:  "password changed successfully",
+		})
+	}
+}
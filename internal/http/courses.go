@@ -0,0 +1,170 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/utils/response"
+)
+
+// CourseRequest is the body of creating or replacing a course.
+type CourseRequest struct {
+	// Code is stored in upper case, e.g. CS101
+	Code        string `json:"code" validate:"required,max=20"`
+	Title       string `json:"title" validate:"required,max=200"`
+	Description string `json:"description,omitempty" validate:"max=2000"`
+	Credits     int    `json:"credits" validate:"min=0,max=30"`
+	Department  string `json:"department,omitempty" validate:"max=100"`
+	Capacity    int    `json:"capacity" validate:"required,gt=0,max=1000"`
+	// Active defaults to true
+	Active *bool `json:"active,omitempty"`
+}
+
+type CourseResponse struct {
+	ID          int64  `json:"id"`
+	Code        string `json:"code"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Credits     int    `json:"credits"`
+	Department  string `json:"department"`
+	Capacity    int    `json:"capacity"`
+	Active      bool   `json:"active"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+func newCourseResponse(course *storage.Course) *CourseResponse {
+	return &CourseResponse{
+		ID:          course.ID,
+		Code:        course.Code,
+		Title:       course.Title,
+		Description: course.Description,
+		Credits:     course.Credits,
+		Department:  course.Department,
+		Capacity:    course.Capacity,
+		Active:      course.Active,
+		CreatedAt:   course.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:   course.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// decodeCourse reads, normalizes and validates a CourseRequest and writes the
+// error response if it isn't valid.
+func decodeCourse(w http.ResponseWriter, r *http.Request) (*storage.Course, bool) {
+	var req CourseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, r, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	req.Code = strings.ToUpper(strings.TrimSpace(req.Code))
+	req.Title = strings.TrimSpace(req.Title)
+	req.Description = strings.TrimSpace(req.Description)
+	req.Department = strings.TrimSpace(req.Department)
+
+	if err := validate.Struct(req); err != nil {
+		response.ValidationFailed(w, r, response.FieldErrors(r.Context(), err))
+		return nil, false
+	}
+
+	course := &storage.Course{
+		Code:        req.Code,
+		Title:       req.Title,
+		Description: req.Description,
+		Credits:     req.Credits,
+		Department:  req.Department,
+		Capacity:    req.Capacity,
+		Active:      req.Active == nil || *req.Active,
+	}
+	return course, true
+}
+
+// courseID reads the {id} path value, writing a 400 if it isn't a number.
+func courseID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		response.Error(w, r, http.StatusBadRequest, "invalid course ID")
+		return 0, false
+	}
+	return id, true
+}
+
+func CreateCourseHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		course, ok := decodeCourse(w, r)
+		if !ok {
+			return
+		}
+
+		if err := store.CreateCourse(r.Context(), course); err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.Write(w, r, http.StatusCreated, newCourseResponse(course))
+	}
+}
+
+func GetCourseHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := courseID(w, r)
+		if !ok {
+			return
+		}
+
+		course, err := store.GetCourse(r.Context(), id)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.Write(w, r, http.StatusOK, newCourseResponse(course))
+	}
+}
+
+// UpdateCourseHandler replaces a course, fields left out get their defaults.
+func UpdateCourseHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := courseID(w, r)
+		if !ok {
+			return
+		}
+
+		course, ok := decodeCourse(w, r)
+		if !ok {
+			return
+		}
+
+		course.ID = id
+		if err := store.UpdateCourse(r.Context(), course); err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.Write(w, r, http.StatusOK, newCourseResponse(course))
+	}
+}
+
+// DeleteCourseHandler deletes a course for good. Courses that are no longer
+// offered can be kept around with active set to false instead.
+func DeleteCourseHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := courseID(w, r)
+		if !ok {
+			return
+		}
+
+		if err := store.DeleteCourse(r.Context(), id); err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.Write(w, r, http.StatusOK, response.Response{
+			Status: response.StatusOK,
+			Error:  "course deleted successfully",
+		})
+	}
+}
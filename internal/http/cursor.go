@@ -0,0 +1,106 @@
+package httphandler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/smartcraze/student-api/internal/storage"
+)
+
+const (
+	cursorNext = "next"
+	cursorPrev = "prev"
+)
+
+// pageCursor is the payload behind the opaque next_cursor/prev_cursor tokens.
+// It records the sort it was made for so it can't be replayed against another one.
+type pageCursor struct {
+	Direction string            `json:"d"`
+	SortBy    storage.SortField `json:"s"`
+	Desc      bool              `json:"desc,omitempty"`
+	Value     json.RawMessage   `json:"v"`
+	ID        int64             `json:"id"`
+}
+
+var errInvalidCursor = errors.New("invalid cursor parameter")
+
+// encodeCursor makes the token for position, the sort value and id of the
+// first or last row of a page.
+func encodeCursor(direction string, sortBy storage.SortField, desc bool, position storage.Cursor) string {
+	value, _ := json.Marshal(position.Value)
+	data, _ := json.Marshal(pageCursor{
+		Direction: direction,
+		SortBy:    sortBy,
+		Desc:      desc,
+		Value:     value,
+		ID:        position.ID,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor checks the token belongs to the requested sort and returns its
+// direction and position.
+func decodeCursor(token string, sortBy storage.SortField, desc bool) (string, *storage.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", nil, errInvalidCursor
+	}
+
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return "", nil, errInvalidCursor
+	}
+	if c.Direction != cursorNext && c.Direction != cursorPrev {
+		return "", nil, errInvalidCursor
+	}
+	if c.SortBy != sortBy || c.Desc != desc {
+		return "", nil, errors.New("cursor does not match sort parameters")
+	}
+
+	// Decode the value into the type Student.SortValue or Course.SortValue
+	// returns for this field
+	var value any
+	switch sortBy {
+	case storage.SortByID, storage.SortByRegistrationNo, storage.SortByCredits:
+		var v int64
+		err = json.Unmarshal(c.Value, &v)
+		value = v
+	case storage.SortByCreatedAt, storage.SortByUpdatedAt:
+		// the timestamp columns hold UTC wall time, see parseTimeParam
+		var v time.Time
+		err = json.Unmarshal(c.Value, &v)
+		value = v.UTC()
+	default:
+		var v string
+		err = json.Unmarshal(c.Value, &v)
+		value = v
+	}
+	if err != nil {
+		return "", nil, errInvalidCursor
+	}
+
+	return c.Direction, &storage.Cursor{Value: value, ID: c.ID}, nil
+}
+
+// trimPage drops the extra row a list fetches to find out whether there is
+// another page, and reports on which sides of the page there are more rows.
+// after and before say whether the page was read from a cursor.
+func trimPage[T any](rows []T, limit, offset int, after, before bool) (page []T, hasNext, hasPrev bool) {
+	hasMore := len(rows) > limit
+	if hasMore {
+		if before {
+			// paging backwards, the extra row is at the front of the page
+			rows = rows[1:]
+		} else {
+			rows = rows[:limit]
+		}
+	}
+
+	// Coming from a cursor there is always a page on the side we came from
+	if before {
+		return rows, true, hasMore
+	}
+	return rows, hasMore, offset > 0 || after
+}
@@ -0,0 +1,89 @@
+package httphandler
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/smartcraze/student-api/internal/storage"
+)
+
+func TestDecodeCursorRoundTrip(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 30, 45, 123456789, time.FixedZone("CET", 3600))
+	student := &storage.Student{
+		ID:             42,
+		FirstName:      "Ada",
+		LastName:       "Lovelace",
+		RegistrationNo: 1815,
+		Email:          "ada@example.com",
+		CreatedAt:      created,
+		UpdatedAt:      created.Add(time.Hour),
+	}
+
+	tests := []struct {
+		sortBy storage.SortField
+		want   any
+	}{
+		{storage.SortByID, int64(42)},
+		{storage.SortByRegistrationNo, int64(1815)},
+		{storage.SortByFirstName, "Ada"},
+		{storage.SortByLastName, "Lovelace"},
+		{storage.SortByEmail, "ada@example.com"},
+		// times come back in UTC, like the backends store them
+		{storage.SortByCreatedAt, created.UTC()},
+		{storage.SortByUpdatedAt, created.Add(time.Hour).UTC()},
+	}
+
+	for _, tt := range tests {
+		for _, direction := range []string{cursorNext, cursorPrev} {
+			for _, desc := range []bool{false, true} {
+				token := encodeCursor(direction, tt.sortBy, desc, studentPosition(tt.sortBy, student))
+
+				gotDirection, position, err := decodeCursor(token, tt.sortBy, desc)
+				if err != nil {
+					t.Fatalf("%s %s desc=%v: %v", tt.sortBy, direction, desc, err)
+				}
+				if gotDirection != direction {
+					t.Errorf("%s: direction %q, want %q", tt.sortBy, gotDirection, direction)
+				}
+				if position.ID != student.ID {
+					t.Errorf("%s: id %d, want %d", tt.sortBy, position.ID, student.ID)
+				}
+
+				if want, ok := tt.want.(time.Time); ok {
+					got, ok := position.Value.(time.Time)
+					if !ok || !got.Equal(want) || got.Location() != time.UTC {
+						t.Errorf("%s: value %#v, want %v", tt.sortBy, position.Value, want)
+					}
+				} else if position.Value != tt.want {
+					t.Errorf("%s: value %#v (%T), want %#v (%T)", tt.sortBy, position.Value, position.Value, tt.want, tt.want)
+				}
+			}
+		}
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	student := &storage.Student{ID: 7, LastName: "Hopper"}
+	token := encodeCursor(cursorNext, storage.SortByLastName, false, studentPosition(storage.SortByLastName, student))
+
+	tests := []struct {
+		name   string
+		token  string
+		sortBy storage.SortField
+		desc   bool
+	}{
+		{"not base64", "!!!", storage.SortByLastName, false},
+		{"not json", base64.RawURLEncoding.EncodeToString([]byte("nope")), storage.SortByLastName, false},
+		{"unknown direction", base64.RawURLEncoding.EncodeToString([]byte(`{"d":"up","s":"last_name","v":"x","id":1}`)), storage.SortByLastName, false},
+		{"other sort field", token, storage.SortByEmail, false},
+		{"other direction", token, storage.SortByLastName, true},
+		{"value of the wrong type", base64.RawURLEncoding.EncodeToString([]byte(`{"d":"next","s":"id","v":"x","id":1}`)), storage.SortByID, false},
+	}
+
+	for _, tt := range tests {
+		if _, _, err := decodeCursor(tt.token, tt.sortBy, tt.desc); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
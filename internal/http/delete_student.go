@@ -13,34 +13,40 @@ func DeleteStudentHandler(store storage.Storage) http.HandlerFunc {
 		// Get student ID from URL path parameter
 		idStr := r.PathValue("id")
 		if idStr == "" {
-			response.Writejson(w, http.StatusBadRequest, response.Response{
-				Status: response.StatusError,
-				Error:  "student ID is required",
-			})
+			response.Error(w, r, http.StatusBadRequest, "student ID is required")
 			return
 		}
 
 		id, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
-			response.Writejson(w, http.StatusBadRequest, response.Response{
-				Status: response.StatusError,
-				Error:  "invalid student ID",
-			})
+			response.Error(w, r, http.StatusBadRequest, "invalid student ID")
+			return
+		}
+
+		version, err := ifMatchVersion(r, func() (*storage.Student, error) {
+			return store.GetStudentByID(r.Context(), id)
+		})
+		if err != nil {
+			writeStorageError(w, r, err)
 			return
 		}
 
 		// Delete student from database
-		err = store.DeleteStudent(r.Context(), id)
+		err = store.DeleteStudent(r.Context(), id, version)
 		if err != nil {
-			response.Writejson(w, http.StatusNotFound, response.Response{
-				Status: response.StatusError,
-				Error:  err.Error(),
-			})
+			writeStorageError(w, r, err)
+			return
+		}
+
+		// Sign out every session, this also bumps the token generation so the
+		// access tokens already issued are rejected along with the deleted student
+		if err := store.RevokeStudentRefreshTokens(r.Context(), id); err != nil {
+			writeStorageError(w, r, err)
 			return
 		}
 
 		// Return success response
-		response.Writejson(w, http.StatusOK, response.Response{
+		response.Write(w, r, http.StatusOK, response.Response{
 			Status: response.StatusOK,
 			Error:  "student deleted successfully",
 		})
@@ -0,0 +1,285 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/smartcraze/student-api/internal/notify"
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/utils/response"
+)
+
+type EnrollRequest struct {
+	SectionID int64 `json:"section_id" validate:"required,gt=0"`
+	// Waitlist puts the student on the waitlist of a full section instead of
+	// failing, it defaults to true
+	Waitlist *bool `json:"waitlist,omitempty"`
+}
+
+type EnrollmentResponse struct {
+	ID        int64  `json:"id"`
+	StudentID int64  `json:"student_id"`
+	SectionID int64  `json:"section_id"`
+	Status    string `json:"status"`
+	// Position is the place on the waitlist
+	Position  int              `json:"position,omitempty"`
+	Grade     string           `json:"grade,omitempty"`
+	CreatedAt string           `json:"created_at"`
+	UpdatedAt string           `json:"updated_at"`
+	Section   *SectionResponse `json:"section,omitempty"`
+}
+
+type ListEnrollmentsResponse struct {
+	Enrollments []*EnrollmentResponse `json:"enrollments"`
+}
+
+func (l ListEnrollmentsResponse) Items() any {
+	return l.Enrollments
+}
+
+type GradeRequest struct {
+	Grade string `json:"grade" validate:"required,oneof=A A- B+ B B- C+ C C- D+ D D- F"`
+}
+
+// ConflictDetails identifies the section a requested section clashes with.
+type ConflictDetails struct {
+	SectionID   int64         `json:"section_id"`
+	SectionCode string        `json:"section_code"`
+	CourseCode  string        `json:"course_code"`
+	Meeting     MeetingFields `json:"meeting"`
+}
+
+// PrerequisiteDetails is a prerequisite group that isn't met, passing any
+// one of its courses meets it.
+type PrerequisiteDetails struct {
+	AnyOf []*PrerequisiteResponse `json:"any_of"`
+}
+
+// writeRejection responds 409 with every requirement an enrollment failed.
+func writeRejection(w http.ResponseWriter, r *http.Request, rejected *storage.EnrollmentRejectedError) {
+	problem := &response.Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(http.StatusConflict),
+		Status: http.StatusConflict,
+		Detail: storage.ErrEnrollmentRejected.Error(),
+	}
+	for _, reason := range rejected.Reasons {
+		switch reason.Code {
+		case storage.ReasonPrerequisite:
+			problem.Reasons = append(problem.Reasons, response.Reason{
+				Code:    reason.Code,
+				Message: "a prerequisite is not met",
+				Details: PrerequisiteDetails{AnyOf: newPrerequisiteGroup(reason.AnyOf)},
+			})
+		case storage.ReasonScheduleConflict:
+			problem.Reasons = append(problem.Reasons, response.Reason{
+				Code:    reason.Code,
+				Message: "the section meets at the same time as another section",
+				Details: ConflictDetails{
+					SectionID:   reason.Section.ID,
+					SectionCode: reason.Section.Code,
+					CourseCode:  reason.Section.CourseCode,
+					Meeting:     newMeetingFields(reason.Meeting),
+				},
+			})
+		}
+	}
+
+	response.WriteProblem(w, r, problem)
+}
+
+func newEnrollmentResponse(e *storage.Enrollment) *EnrollmentResponse {
+	resp := &EnrollmentResponse{
+		ID:        e.ID,
+		StudentID: e.StudentID,
+		SectionID: e.SectionID,
+		Status:    string(e.Status),
+		Position:  e.Position,
+		Grade:     string(e.Grade),
+		CreatedAt: e.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: e.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if e.Section != nil {
+		resp.Section = newSectionResponse(e.Section)
+	}
+	return resp
+}
+
+// EnrollHandler enrolls the student in {id} in a section, or puts them on
+// its waitlist when it is full. The students promoted from the waitlist into
+// seats that were free before them are notified.
+func EnrollHandler(store storage.Storage, notifier notify.Notifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idStr := r.PathValue("id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, "invalid student ID")
+			return
+		}
+
+		var req EnrollRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if err := validate.Struct(req); err != nil {
+			response.ValidationFailed(w, r, response.FieldErrors(r.Context(), err))
+			return
+		}
+
+		waitlist := req.Waitlist == nil || *req.Waitlist
+
+		enrollment, promoted, err := store.Enroll(r.Context(), id, req.SectionID, waitlist)
+		var rejected *storage.EnrollmentRejectedError
+		if errors.As(err, &rejected) {
+			writeRejection(w, r, rejected)
+			return
+		}
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		notifyPromotions(r, store, notifier, enrollment.SectionID, promoted)
+
+		response.Write(w, r, http.StatusCreated, newEnrollmentResponse(enrollment))
+	}
+}
+
+// ListEnrollmentsHandler lists every enrollment of the student in {id},
+// dropped ones included, oldest first.
+func ListEnrollmentsHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idStr := r.PathValue("id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, "invalid student ID")
+			return
+		}
+
+		if _, err := store.GetStudentByID(r.Context(), id); err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		enrollments, err := store.ListStudentEnrollments(r.Context(), id)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		resp := ListEnrollmentsResponse{Enrollments: make([]*EnrollmentResponse, len(enrollments))}
+		for i, e := range enrollments {
+			resp.Enrollments[i] = newEnrollmentResponse(e)
+		}
+
+		response.Write(w, r, http.StatusOK, resp)
+	}
+}
+
+// GradeEnrollmentHandler records the grade of an enrolled student, grading
+// again replaces it. Grades count towards the prerequisites of other courses.
+func GradeEnrollmentHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idStr := r.PathValue("id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, "invalid student ID")
+			return
+		}
+		enrollmentID, err := strconv.ParseInt(r.PathValue("enrollment_id"), 10, 64)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, "invalid enrollment ID")
+			return
+		}
+
+		var req GradeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		req.Grade = strings.ToUpper(strings.TrimSpace(req.Grade))
+
+		if err := validate.Struct(req); err != nil {
+			response.ValidationFailed(w, r, response.FieldErrors(r.Context(), err))
+			return
+		}
+
+		enrollment, err := store.GradeEnrollment(r.Context(), id, enrollmentID, storage.Grade(req.Grade))
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.Write(w, r, http.StatusOK, newEnrollmentResponse(enrollment))
+	}
+}
+
+// DropEnrollmentHandler drops an enrollment of the student in {id}. The
+// students promoted from the waitlist into the freed seat are notified.
+func DropEnrollmentHandler(store storage.Storage, notifier notify.Notifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idStr := r.PathValue("id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, "invalid student ID")
+			return
+		}
+		enrollmentID, err := strconv.ParseInt(r.PathValue("enrollment_id"), 10, 64)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, "invalid enrollment ID")
+			return
+		}
+
+		dropped, promoted, err := store.DropEnrollment(r.Context(), id, enrollmentID)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		// The drop is committed, so nothing past this point fails the request
+		notifyPromotions(r, store, notifier, dropped.SectionID, promoted)
+
+		response.Write(w, r, http.StatusOK, newEnrollmentResponse(dropped))
+	}
+}
+
+// notifyPromotions tells the students promoted from the waitlist of a section
+// that they got a seat. The seats are already theirs, so failures only get
+// logged.
+func notifyPromotions(r *http.Request, store storage.Storage, notifier notify.Notifier, sectionID int64, promoted []*storage.Enrollment) {
+	if len(promoted) == 0 {
+		return
+	}
+
+	section, err := store.GetSection(r.Context(), sectionID)
+	if err != nil {
+		slog.Error("failed to notify promoted students", slog.Int64("section_id", sectionID), slog.String("error", err.Error()))
+		return
+	}
+	for _, e := range promoted {
+		notifyPromoted(r, store, notifier, e, section)
+	}
+}
+
+func notifyPromoted(r *http.Request, store storage.Storage, notifier notify.Notifier, e *storage.Enrollment, section *storage.Section) {
+	student, err := store.GetStudentByID(r.Context(), e.StudentID)
+	if err == nil {
+		err = notifier.Notify(r.Context(), notify.Message{
+			To:      student.Email,
+			Subject: fmt.Sprintf("You are enrolled in %s", section.CourseCode),
+			Body: fmt.Sprintf("A seat opened up in section %s of %s %s (%s) and you have been moved off the waitlist.",
+				section.Code, section.CourseCode, section.CourseTitle, section.TermCode),
+		})
+	}
+	if err != nil {
+		slog.Error("failed to notify promoted student", slog.Int64("enrollment_id", e.ID), slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,103 @@
+package httphandler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/utils/response"
+)
+
+// storageErrorStatus maps the storage errors to the HTTP status a client should see.
+func storageErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, storage.ErrDuplicateEmail),
+		errors.Is(err, storage.ErrDuplicateRegistrationNo),
+		errors.Is(err, storage.ErrDuplicateCourseCode),
+		errors.Is(err, storage.ErrDuplicateTermCode),
+		errors.Is(err, storage.ErrDuplicateSectionCode),
+		errors.Is(err, storage.ErrCourseHasSections),
+		errors.Is(err, storage.ErrCourseInactive),
+		errors.Is(err, storage.ErrStudentNotActive),
+		errors.Is(err, storage.ErrAlreadyEnrolled),
+		errors.Is(err, storage.ErrSectionFull),
+		errors.Is(err, storage.ErrEnrollmentRejected),
+		errors.Is(err, storage.ErrPrerequisiteCycle),
+		errors.Is(err, storage.ErrCourseIsPrerequisite),
+		errors.Is(err, storage.ErrNotGradable),
+		errors.Is(err, storage.ErrEnrollmentGraded),
+		errors.Is(err, storage.ErrConflict),
+		errors.Is(err, storage.ErrInvalidTransition),
+		errors.Is(err, storage.ErrStatusMismatch):
+		return http.StatusConflict
+	case errors.Is(err, storage.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// storageErrorDetail is what a client is told about err: the storage error
+// itself, without the context the storage layer wrapped it in, so it reads
+// the same from every handler and the catalog can translate it.
+func storageErrorDetail(err error) string {
+	for _, target := range []error{
+		storage.ErrDuplicateEmail,
+		storage.ErrDuplicateRegistrationNo,
+		storage.ErrDuplicateCourseCode,
+		storage.ErrDuplicateTermCode,
+		storage.ErrDuplicateSectionCode,
+		storage.ErrCourseHasSections,
+		storage.ErrCourseInactive,
+		storage.ErrStudentNotActive,
+		storage.ErrAlreadyEnrolled,
+		storage.ErrSectionFull,
+		storage.ErrEnrollmentRejected,
+		storage.ErrPrerequisiteCycle,
+		storage.ErrCourseIsPrerequisite,
+		storage.ErrNotGradable,
+		storage.ErrEnrollmentGraded,
+		storage.ErrVersionMismatch,
+		storage.ErrConflict,
+		storage.ErrInvalidTransition,
+		storage.ErrStatusMismatch,
+	} {
+		if errors.Is(err, target) {
+			return target.Error()
+		}
+	}
+	return err.Error()
+}
+
+func writeStorageError(w http.ResponseWriter, r *http.Request, err error) {
+	status := storageErrorStatus(err)
+	problem := &response.Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: storageErrorDetail(err),
+	}
+
+	// Driver errors stay in the log, the request id ties the response to them
+	if status == http.StatusInternalServerError {
+		slog.Error("storage error", slog.String("error", err.Error()), slog.String("request_id", storage.RequestIDFrom(r.Context())))
+		problem.Detail = ""
+	}
+
+	// Name the field that clashes, so a form can highlight it
+	switch {
+	case errors.Is(err, storage.ErrDuplicateEmail):
+		problem.Errors = []response.FieldError{response.NewFieldError(r.Context(), "email", "unique", "", "email is already registered")}
+	case errors.Is(err, storage.ErrDuplicateRegistrationNo):
+		problem.Errors = []response.FieldError{response.NewFieldError(r.Context(), "reg_no", "unique", "", "reg_no is already taken")}
+	case errors.Is(err, storage.ErrDuplicateCourseCode),
+		errors.Is(err, storage.ErrDuplicateTermCode),
+		errors.Is(err, storage.ErrDuplicateSectionCode):
+		problem.Errors = []response.FieldError{response.NewFieldError(r.Context(), "code", "unique", "", "code is already taken")}
+	}
+
+	response.WriteProblem(w, r, problem)
+}
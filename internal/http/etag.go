@@ -0,0 +1,87 @@
+package httphandler
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/smartcraze/student-api/internal/storage"
+)
+
+// studentETag is a strong entity tag for the student's current version.
+func studentETag(student *storage.Student) string {
+	return `"` + strconv.FormatInt(student.Version, 10) + `"`
+}
+
+// parseETags returns the versions listed in an If-Match or If-None-Match
+// header, and whether it is "*". Weak tags are skipped unless weak is true,
+// If-Match only uses the strong comparison.
+func parseETags(header string, weak bool) (versions []int64, any bool) {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return nil, true
+		}
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+
+		// tags that aren't ours can never match, versions start at 1 and
+		// 0 would make the write unconditional
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+		if err != nil || version < 1 {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	return versions, false
+}
+
+// ifMatchVersion turns If-Match into the version a write has to be
+// conditional on, 0 when there is no header or it is "*". load is only
+// called when the header lists several tags.
+func ifMatchVersion(r *http.Request, load func() (*storage.Student, error)) (int64, error) {
+	header := strings.Join(r.Header.Values("If-Match"), ",")
+	if header == "" {
+		return 0, nil
+	}
+
+	versions, any := parseETags(header, false)
+	if any {
+		return 0, nil
+	}
+
+	switch len(versions) {
+	case 0:
+		return 0, storage.ErrVersionMismatch
+	case 1:
+		return versions[0], nil
+	}
+
+	student, err := load()
+	if err != nil {
+		return 0, err
+	}
+	if !slices.Contains(versions, student.Version) {
+		return 0, storage.ErrVersionMismatch
+	}
+	return student.Version, nil
+}
+
+// notModified reports whether If-None-Match matches the student's current version.
+func notModified(r *http.Request, student *storage.Student) bool {
+	header := strings.Join(r.Header.Values("If-None-Match"), ",")
+	if header == "" {
+		return false
+	}
+
+	versions, any := parseETags(header, true)
+	return any || slices.Contains(versions, student.Version)
+}
@@ -0,0 +1,162 @@
+package httphandler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/smartcraze/student-api/internal/storage"
+)
+
+func TestParseETags(t *testing.T) {
+	tests := []struct {
+		header   string
+		weak     bool
+		versions []int64
+		any      bool
+	}{
+		{`"3"`, false, []int64{3}, false},
+		{`"3", "5" ,"7"`, false, []int64{3, 5, 7}, false},
+		{`W/"3", "5"`, false, []int64{5}, false},
+		{`W/"3", "5"`, true, []int64{3, 5}, false},
+		{`*`, false, nil, true},
+		{`"3", *`, false, nil, true},
+		// malformed tags and tags of other servers are skipped
+		{`3`, false, nil, false},
+		{`"3`, false, nil, false},
+		{`"`, false, nil, false},
+		{`"abc", "4"`, false, []int64{4}, false},
+		{`W/3`, true, nil, false},
+		{`w/"3"`, true, nil, false},
+		{`"0", "-2"`, false, nil, false},
+		{`,,`, false, nil, false},
+	}
+
+	for _, tt := range tests {
+		versions, any := parseETags(tt.header, tt.weak)
+		if !reflect.DeepEqual(versions, tt.versions) || any != tt.any {
+			t.Errorf("parseETags(%q, %v) = %v, %v, want %v, %v", tt.header, tt.weak, versions, any, tt.versions, tt.any)
+		}
+	}
+}
+
+func TestIfMatchVersion(t *testing.T) {
+	current := &storage.Student{ID: 1, Version: 4}
+
+	tests := []struct {
+		name    string
+		headers []string
+		want    int64
+		err     error
+		loaded  bool
+	}{
+		{"no header", nil, 0, nil, false},
+		{"any", []string{"*"}, 0, nil, false},
+		{"one tag", []string{`"2"`}, 2, nil, false},
+		// If-Match uses the strong comparison
+		{"weak tag", []string{`W/"4"`}, 0, storage.ErrVersionMismatch, false},
+		{"malformed", []string{`four`}, 0, storage.ErrVersionMismatch, false},
+		{"version 0", []string{`"0"`}, 0, storage.ErrVersionMismatch, false},
+		{"list with the current version", []string{`"3", "4"`}, 4, nil, true},
+		{"repeated headers", []string{`"3"`, `"4"`}, 4, nil, true},
+		{"list without it", []string{`"2", "3"`}, 0, storage.ErrVersionMismatch, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/student/1", nil)
+			for _, h := range tt.headers {
+				req.Header.Add("If-Match", h)
+			}
+
+			loaded := false
+			version, err := ifMatchVersion(req, func() (*storage.Student, error) {
+				loaded = true
+				return current, nil
+			})
+			if version != tt.want || !errors.Is(err, tt.err) {
+				t.Errorf("got %d, %v, want %d, %v", version, err, tt.want, tt.err)
+			}
+			if loaded != tt.loaded {
+				t.Errorf("loaded the student: %v, want %v", loaded, tt.loaded)
+			}
+		})
+	}
+
+	// a failing load is returned as it is
+	req := httptest.NewRequest(http.MethodPut, "/api/student/1", nil)
+	req.Header.Set("If-Match", `"1", "2"`)
+	if _, err := ifMatchVersion(req, func() (*storage.Student, error) { return nil, storage.ErrNotFound }); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("got %v, want the load error", err)
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	student := &storage.Student{ID: 1, Version: 4}
+
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{`"4"`, true},
+		// If-None-Match uses the weak comparison
+		{`W/"4"`, true},
+		{`"3"`, false},
+		{`"3", W/"4"`, true},
+		{`*`, true},
+		{`4`, false},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/student/1", nil)
+		if tt.header != "" {
+			req.Header.Set("If-None-Match", tt.header)
+		}
+		if got := notModified(req, student); got != tt.want {
+			t.Errorf("notModified(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+// Runs the conditional requests through the handlers.
+func TestConditionalRequests(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	student := &storage.Student{FirstName: "Ada", LastName: "Lovelace", RegistrationNo: 1, PhoneNumber: "+15550100", Email: "ada@example.com", Password: "hash"}
+	if err := store.CreateStudent(context.Background(), student); err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/student/{id}", GetStudentHandler(store))
+	mux.Handle("DELETE /api/student/{id}", DeleteStudentHandler(store))
+
+	send := func(method, header, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/student/1", nil)
+		req.Header.Set(header, etag)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send(http.MethodGet, "If-None-Match", `"1"`)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 || rec.Header().Get("ETag") != `"1"` {
+		t.Errorf("got %d with ETag %q and %q, want an empty 304", rec.Code, rec.Header().Get("ETag"), rec.Body)
+	}
+	if rec := send(http.MethodGet, "If-None-Match", `"2"`); rec.Code != http.StatusOK {
+		t.Errorf("got %d for another version, want 200", rec.Code)
+	}
+
+	if rec := send(http.MethodDelete, "If-Match", `"2"`); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("got %d for a stale If-Match, want 412: %s", rec.Code, rec.Body)
+	}
+	if rec := send(http.MethodDelete, "If-Match", `"0"`); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("got %d for If-Match version 0, want 412: %s", rec.Code, rec.Body)
+	}
+	if rec := send(http.MethodDelete, "If-Match", `"1"`); rec.Code != http.StatusOK {
+		t.Errorf("got %d for the current version, want 200: %s", rec.Code, rec.Body)
+	}
+}
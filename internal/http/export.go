@@ -0,0 +1,308 @@
+package httphandler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/internal/validation"
+	"github.com/smartcraze/student-api/utils/response"
+	"github.com/xuri/excelize/v2"
+)
+
+const xlsxType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// exportColumn is a column of an export, named like the StudentResponse field.
+type exportColumn struct {
+	name  string
+	value func(*storage.Student) any
+}
+
+// exportColumns are the columns that can be exported, in their default
+// order. The password is deliberately not one of them.
+var exportColumns = []exportColumn{
+	{"id", func(s *storage.Student) any { return s.ID }},
+	{"first_name", func(s *storage.Student) any { return s.FirstName }},
+	{"last_name", func(s *storage.Student) any { return s.LastName }},
+	{"reg_no", func(s *storage.Student) any { return s.RegistrationNo }},
+	{"phone_number", func(s *storage.Student) any { return s.PhoneNumber }},
+	{"email", func(s *storage.Student) any { return s.Email }},
+	{"role", func(s *storage.Student) any { return string(s.Role) }},
+	{"created_at", func(s *storage.Student) any { return s.CreatedAt.UTC().Format(time.RFC3339) }},
+	{"updated_at", func(s *storage.Student) any { return s.UpdatedAt.UTC().Format(time.RFC3339) }},
+	{"status", func(s *storage.Student) any { return string(s.Status) }},
+	{"date_of_birth", func(s *storage.Student) any { return formatDate(s.DateOfBirth) }},
+	{"gender", func(s *storage.Student) any { return s.Gender }},
+	{"admission_date", func(s *storage.Student) any { return formatDate(s.AdmissionDate) }},
+}
+
+// parseExportColumns reads the comma separated columns parameter, every
+// column by default.
+func parseExportColumns(query url.Values) ([]exportColumn, error) {
+	str := query.Get("columns")
+	if str == "" {
+		return exportColumns, nil
+	}
+
+	var columns []exportColumn
+	var names []string
+	for _, name := range strings.Split(str, ",") {
+		name = strings.TrimSpace(name)
+		if name == "password" {
+			return nil, fmt.Errorf("the password column can't be exported")
+		}
+		if slices.Contains(names, name) {
+			return nil, fmt.Errorf("duplicate column %q", name)
+		}
+
+		i := slices.IndexFunc(exportColumns, func(c exportColumn) bool { return c.name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		columns = append(columns, exportColumns[i])
+		names = append(names, name)
+	}
+
+	return columns, nil
+}
+
+// exportWriter writes the rows of an export in one file format.
+type exportWriter interface {
+	WriteHeader(names []string) error
+	WriteRow(values []any) error
+	Close() error
+}
+
+type csvExport struct {
+	w *csv.Writer
+}
+
+func (e *csvExport) WriteHeader(names []string) error {
+	return e.w.Write(names)
+}
+
+func (e *csvExport) WriteRow(values []any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			record[i] = csvSafe(s)
+		} else {
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvExport) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// csvSafe keeps spreadsheet programs from running a text cell as a formula.
+// Phone numbers start with a + but are left alone, escaped they couldn't be
+// imported again.
+func csvSafe(s string) string {
+	if s != "" && !validation.IsE164(s) && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+type ndjsonExport struct {
+	w     io.Writer
+	names []string
+}
+
+func (e *ndjsonExport) WriteHeader(names []string) error {
+	e.names = names
+	return nil
+}
+
+// WriteRow writes an object with the keys in column order, which a map
+// wouldn't keep.
+func (e *ndjsonExport) WriteRow(values []any) error {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(e.names[i])
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteString("}\n")
+
+	_, err := e.w.Write(b.Bytes())
+	return err
+}
+
+func (e *ndjsonExport) Close() error {
+	return nil
+}
+
+// xlsxExport streams rows into the sheet, excelize keeps large sheets in a
+// temporary file. The workbook can only be written out once it is complete.
+type xlsxExport struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXExport(w io.Writer) (*xlsxExport, error) {
+	file := excelize.NewFile()
+	if err := file.SetSheetName("Sheet1", "Students"); err != nil {
+		return nil, err
+	}
+
+	stream, err := file.NewStreamWriter("Students")
+	if err != nil {
+		return nil, err
+	}
+
+	return &xlsxExport{w: w, file: file, stream: stream}, nil
+}
+
+func (e *xlsxExport) WriteHeader(names []string) error {
+	values := make([]any, len(names))
+	for i, name := range names {
+		values[i] = name
+	}
+	return e.WriteRow(values)
+}
+
+func (e *xlsxExport) WriteRow(values []any) error {
+	e.row++
+	cell, err := excelize.CoordinatesToCellName(1, e.row)
+	if err != nil {
+		return err
+	}
+	return e.stream.SetRow(cell, values)
+}
+
+func (e *xlsxExport) Close() error {
+	if err := e.stream.Flush(); err != nil {
+		return err
+	}
+	return e.file.Write(e.w)
+}
+
+// countingWriter counts the bytes written through it, to tell whether a
+// response has started.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// ExportStudentsHandler streams every student matching the list filters as
+// csv (default), ndjson or xlsx. The columns parameter picks the columns.
+func ExportStudentsHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		format := query.Get("format")
+		if format == "" {
+			format = "csv"
+		}
+
+		filter, err := parseStudentFilter(query)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		sortBy, sortDesc, err := parseStudentSort(query)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		columns, err := parseExportColumns(query)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		body := &countingWriter{w: w}
+		var out exportWriter
+		var contentType string
+		switch format {
+		case "csv":
+			out, contentType = &csvExport{w: csv.NewWriter(body)}, csvType
+		case "ndjson":
+			out, contentType = &ndjsonExport{w: body}, ndjsonType
+		case "xlsx":
+			xlsx, err := newXLSXExport(body)
+			if err != nil {
+				response.Error(w, r, http.StatusInternalServerError, err.Error())
+				return
+			}
+			// removes the temporary file, also when the export fails
+			defer xlsx.file.Close()
+			out, contentType = xlsx, xlsxType
+		default:
+			response.Error(w, r, http.StatusBadRequest, "invalid format parameter, must be csv, ndjson or xlsx")
+			return
+		}
+
+		names := make([]string, len(columns))
+		for i, column := range columns {
+			names[i] = column.name
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="students-%s.%s"`, time.Now().UTC().Format("20060102"), format))
+
+		opts := storage.ListOptions{Filter: filter, SortBy: sortBy, SortDesc: sortDesc}
+		err = out.WriteHeader(names)
+		if err == nil {
+			err = store.ExportStudents(r.Context(), opts, func(student *storage.Student) error {
+				values := make([]any, len(columns))
+				for i, column := range columns {
+					values[i] = column.value(student)
+				}
+				return out.WriteRow(values)
+			})
+		}
+		if err == nil {
+			err = out.Close()
+		}
+
+		if err == nil {
+			return
+		}
+
+		// Nothing was sent yet, e.g. the query failed, so there is still
+		// time for a proper error response
+		if body.n == 0 {
+			w.Header().Del("Content-Disposition")
+			writeStorageError(w, r, err)
+			return
+		}
+
+		// The status is long gone, so cut the response short for the client to notice
+		slog.Error("student export failed", slog.String("error", err.Error()))
+		panic(http.ErrAbortHandler)
+	}
+}
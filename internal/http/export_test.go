@@ -0,0 +1,166 @@
+package httphandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/smartcraze/student-api/internal/auth"
+	"github.com/smartcraze/student-api/internal/config"
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/internal/validation"
+)
+
+func TestCSVSafe(t *testing.T) {
+	tests := map[string]string{
+		"Ada":           "Ada",
+		"=HYPERLINK(1)": "'=HYPERLINK(1)",
+		"@SUM(A1)":      "'@SUM(A1)",
+		"-2+3":          "'-2+3",
+		"+1+cmd":        "'+1+cmd",
+		"+14155550199":  "+14155550199",
+		"+0123456789":   "'+0123456789",
+		"":              "",
+		"\tHidden":      "'\tHidden",
+	}
+	for in, want := range tests {
+		if got := csvSafe(in); got != want {
+			t.Errorf("csvSafe(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// A CSV export has the columns of an import, and a student exported with an
+// E.164 phone number must import again unchanged.
+func TestExportCSVRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	store := storage.NewMemoryStorage()
+	err := store.CreateStudent(ctx, &storage.Student{
+		FirstName:      "Ada",
+		LastName:       "Lovelace",
+		RegistrationNo: 1815,
+		PhoneNumber:    "+14155550199",
+		Email:          "ada@example.com",
+		Password:       "hash",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/students/export?format=csv&columns=first_name,last_name,reg_no,phone_number,email", nil)
+	rec := httptest.NewRecorder()
+	ExportStudentsHandler(store)(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if !strings.Contains(rec.Body.String(), ",+14155550199,") {
+		t.Fatalf("phone number is escaped in %q", rec.Body)
+	}
+
+	rows, err := ParseImport(csvType, rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range rows {
+		rows[i].Student.Password = "correct horse battery"
+	}
+
+	passwords, err := auth.NewPasswords(config.PasswordPolicy{MinLength: 8, BcryptCost: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := validation.NewStudentRules(config.StudentRules{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imported := storage.NewMemoryStorage()
+	report, err := ImportStudents(ctx, imported, passwords, rules, rows, ImportAllOrNothing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Committed || report.Created != 1 {
+		t.Fatalf("import report is %+v", report)
+	}
+
+	student, err := imported.GetStudentByID(ctx, report.Rows[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if student.PhoneNumber != "+14155550199" || student.RegistrationNo != 1815 || student.Email != "ada@example.com" {
+		t.Errorf("imported student is %+v", student)
+	}
+}
+
+// failingExport fails ExportStudents after handing out the first rows.
+type failingExport struct {
+	storage.Storage
+	rows int
+}
+
+func (s failingExport) ExportStudents(ctx context.Context, opts storage.ListOptions, fn func(*storage.Student) error) error {
+	return s.Storage.ExportStudents(ctx, opts, func(student *storage.Student) error {
+		if s.rows == 0 {
+			return errors.New("connection reset")
+		}
+		s.rows--
+		return fn(student)
+	})
+}
+
+func TestExportFailure(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	for i := range 3 {
+		err := store.CreateStudent(context.Background(), &storage.Student{
+			FirstName:      "Ada",
+			LastName:       "Lovelace",
+			RegistrationNo: 100 + i,
+			PhoneNumber:    fmt.Sprintf("+1555000%04d", i),
+			Email:          fmt.Sprintf("s%d@example.com", i),
+			Password:       "hash",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// nothing has reached the client when the first row fails, csv and xlsx
+	// still buffer their header
+	for _, format := range []string{"csv", "ndjson", "xlsx"} {
+		t.Run(format, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/students/export?format="+format, nil)
+			rec := httptest.NewRecorder()
+			ExportStudentsHandler(failingExport{Storage: store})(rec, req)
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Errorf("status %d, want 500: %s", rec.Code, rec.Body)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/problem+json" {
+				t.Errorf("Content-Type %q, want a problem", got)
+			}
+			if got := rec.Header().Get("Content-Disposition"); got != "" {
+				t.Errorf("error response is an attachment: %q", got)
+			}
+		})
+	}
+
+	// once rows went out the response can only be cut short
+	t.Run("after the first row", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/students/export?format=ndjson", nil)
+		rec := httptest.NewRecorder()
+		defer func() {
+			if p := recover(); p != http.ErrAbortHandler {
+				t.Errorf("got %v, want the handler aborted", p)
+			}
+			if rec.Code != http.StatusOK || strings.Count(rec.Body.String(), `"email"`) != 1 {
+				t.Errorf("got %d with %q, want the first row sent", rec.Code, rec.Body)
+			}
+		}()
+		ExportStudentsHandler(failingExport{Storage: store, rows: 1})(rec, req)
+	})
+}
@@ -13,10 +13,50 @@ type StudentResponse struct {
 	FirstName      string `json:"first_name"`
 	LastName       string `json:"last_name"`
 	RegistrationNo int    `json:"reg_no"`
-	PhoneNumber    int64  `json:"phone_number"`
+	PhoneNumber    string `json:"phone_number"`
 	Email          string `json:"email"`
+	Role           string `json:"role"`
 	CreatedAt      string `json:"created_at"`
 	UpdatedAt      string `json:"updated_at"`
+	DeletedAt      string `json:"deleted_at,omitempty"`
+	DateOfBirth    string `json:"date_of_birth,omitempty"`
+	Gender         string `json:"gender,omitempty"`
+	// Address is left out when every part of it is empty
+	Address       *AddressFields `json:"address,omitempty"`
+	Status        string         `json:"status"`
+	AdmissionDate string         `json:"admission_date,omitempty"`
+	// Guardians are left out of lists
+	Guardians []GuardianResponse `json:"guardians,omitempty"`
+}
+
+// newStudentResponse describes student without its password.
+func newStudentResponse(student *storage.Student) *StudentResponse {
+	resp := &StudentResponse{
+		ID:             student.ID,
+		FirstName:      student.FirstName,
+		LastName:       student.LastName,
+		RegistrationNo: student.RegistrationNo,
+		PhoneNumber:    student.PhoneNumber,
+		Email:          student.Email,
+		Role:           string(student.Role),
+		CreatedAt:      student.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:      student.UpdatedAt.Format("2006-01-02 15:04:05"),
+		DateOfBirth:    formatDate(student.DateOfBirth),
+		Gender:         student.Gender,
+		Status:         string(student.Status),
+		AdmissionDate:  formatDate(student.AdmissionDate),
+	}
+	if student.DeletedAt != nil {
+		resp.DeletedAt = student.DeletedAt.Format("2006-01-02 15:04:05")
+	}
+	if student.Address != (storage.Address{}) {
+		address := addressFields(student.Address)
+		resp.Address = &address
+	}
+	if student.Guardians != nil {
+		resp.Guardians = guardianResponses(student.Guardians)
+	}
+	return resp
 }
 
 func GetStudentHandler(store storage.Storage) http.HandlerFunc {
@@ -24,44 +64,38 @@ func GetStudentHandler(store storage.Storage) http.HandlerFunc {
 		// Get student ID from URL path parameter
 		idStr := r.PathValue("id")
 		if idStr == "" {
-			response.Writejson(w, http.StatusBadRequest, response.Response{
-				Status: response.StatusError,
-				Error:  "student ID is required",
-			})
+			response.Error(w, r, http.StatusBadRequest, "student ID is required")
 			return
 		}
 
 		id, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
-			response.Writejson(w, http.StatusBadRequest, response.Response{
-				Status: response.StatusError,
-				Error:  "invalid student ID",
-			})
+			response.Error(w, r, http.StatusBadRequest, "invalid student ID")
 			return
 		}
 
 		// Get student from database
 		student, err := store.GetStudentByID(r.Context(), id)
 		if err != nil {
-			response.Writejson(w, http.StatusNotFound, response.Response{
-				Status: response.StatusError,
-				Error:  err.Error(),
-			})
+			writeStorageError(w, r, err)
 			return
 		}
 
-		// Return student without password
-		resp := StudentResponse{
-			ID:             student.ID,
-			FirstName:      student.FirstName,
-			LastName:       student.LastName,
-			RegistrationNo: student.RegistrationNo,
-			PhoneNumber:    student.PhoneNumber,
-			Email:          student.Email,
-			CreatedAt:      student.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt:      student.UpdatedAt.Format("2006-01-02 15:04:05"),
+		w.Header().Set("ETag", studentETag(student))
+		if notModified(r, student) {
+			w.WriteHeader(http.StatusNotModified)
+			return
 		}
 
-		response.Writejson(w, http.StatusOK, resp)
+		student.Guardians, err = store.ListGuardians(r.Context(), id)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		// Return student without password
+		resp := newStudentResponse(student)
+
+		response.Write(w, r, http.StatusOK, resp)
 	}
 }
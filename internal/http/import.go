@@ -0,0 +1,403 @@
+package httphandler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/smartcraze/student-api/internal/auth"
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/internal/validation"
+	"github.com/smartcraze/student-api/utils/response"
+)
+
+const (
+	csvType    = "text/csv"
+	ndjsonType = "application/x-ndjson"
+
+	// maxImportRows and maxImportBytes bound a single import request
+	maxImportRows  = 5000
+	maxImportBytes = 10 << 20
+)
+
+type ImportMode string
+
+const (
+	// ImportAllOrNothing creates no student at all if any row fails
+	ImportAllOrNothing ImportMode = "all-or-nothing"
+	// ImportBestEffort creates every row that can be created
+	ImportBestEffort ImportMode = "best-effort"
+)
+
+func (m ImportMode) Valid() bool {
+	return m == ImportAllOrNothing || m == ImportBestEffort
+}
+
+// Row statuses in an ImportReport
+const (
+	ImportCreated    = "created"
+	ImportDuplicate  = "duplicate"
+	ImportInvalid    = "invalid"
+	ImportRolledBack = "rolled_back"
+)
+
+// ImportRow is one student read from an import file.
+type ImportRow struct {
+	// Line in the file the row starts on
+	Line    int
+	Student CreateStudent
+	// Err is set when the row couldn't be read, e.g. a number column holds text
+	Err error
+}
+
+type ImportRowResult struct {
+	Line   int      `json:"line"`
+	Status string   `json:"status"`
+	ID     int64    `json:"id,omitempty"`
+	Email  string   `json:"email,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+type ImportReport struct {
+	Mode ImportMode `json:"mode"`
+	// Committed is false when an all-or-nothing import was rolled back
+	Committed  bool              `json:"committed"`
+	Total      int               `json:"total"`
+	Created    int               `json:"created"`
+	Duplicates int               `json:"duplicates"`
+	Invalid    int               `json:"invalid"`
+	Rows       []ImportRowResult `json:"rows"`
+}
+
+func (r ImportReport) Items() any {
+	return r.Rows
+}
+
+// importColumns are the CSV header names, the same as the JSON field names
+// of CreateStudent.
+var importColumns = []string{"first_name", "last_name", "reg_no", "phone_number", "email", "password"}
+
+// ParseImport reads the rows of a CSV file with a header line, or of an
+// NDJSON file with one student object per line. Rows that can't be decoded
+// are returned with Err set, only a malformed file fails as a whole.
+func ParseImport(format string, r io.Reader) ([]ImportRow, error) {
+	var rows []ImportRow
+	var err error
+
+	switch format {
+	case csvType:
+		rows, err = parseImportCSV(r)
+	case ndjsonType:
+		rows, err = parseImportNDJSON(r)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("import file has no rows")
+	}
+	if len(rows) > maxImportRows {
+		return nil, fmt.Errorf("import file has %d rows, at most %d are allowed", len(rows), maxImportRows)
+	}
+
+	return rows, nil
+}
+
+func parseImportCSV(r io.Reader) ([]ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("import file has no rows")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	// Spreadsheet programs like to start the file with a byte order mark
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(importColumns, name) {
+			return nil, fmt.Errorf("unknown CSV column %q, expected %s", name, strings.Join(importColumns, ", "))
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("duplicate CSV column %q", name)
+		}
+		columns[name] = i
+	}
+
+	var rows []ImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := ImportRow{Line: line}
+		switch {
+		case errors.Is(err, csv.ErrFieldCount):
+			row.Err = fmt.Errorf("expected %d fields, got %d", len(header), len(record))
+		case err != nil:
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		default:
+			row.Student, row.Err = studentFromRecord(record, columns)
+		}
+
+		rows = append(rows, row)
+		if len(rows) > maxImportRows {
+			break
+		}
+	}
+
+	return rows, nil
+}
+
+func studentFromRecord(record []string, columns map[string]int) (CreateStudent, error) {
+	var student CreateStudent
+	field := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	student.FirstName = field("first_name")
+	student.LastName = field("last_name")
+	student.Email = field("email")
+	student.PhoneNumber = field("phone_number")
+	// passwords are taken as they are, spaces included
+	if i, ok := columns["password"]; ok {
+		student.Password = record[i]
+	}
+
+	var err error
+	if str := field("reg_no"); str != "" {
+		if student.RegistrationNo, err = strconv.Atoi(str); err != nil {
+			return student, fmt.Errorf("field reg_no must be a number")
+		}
+	}
+
+	return student, nil
+}
+
+func parseImportNDJSON(r io.Reader) ([]ImportRow, error) {
+	reader := bufio.NewReader(r)
+
+	var rows []ImportRow
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read import file: %w", err)
+		}
+
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 {
+			row := ImportRow{Line: line}
+			decoder := json.NewDecoder(bytes.NewReader(trimmed))
+			decoder.DisallowUnknownFields()
+			if err := decoder.Decode(&row.Student); err != nil {
+				row.Err = err
+			} else if decoder.More() {
+				row.Err = errors.New("expected one JSON object per line")
+			}
+
+			rows = append(rows, row)
+			if len(rows) > maxImportRows {
+				break
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	return rows, nil
+}
+
+// ImportStudents normalizes and validates rows with the same rules as
+// CreateStudentHandler and creates the valid ones in a single transaction.
+func ImportStudents(ctx context.Context, store storage.Storage, passwords *auth.Passwords, rules *validation.StudentRules, rows []ImportRow, mode ImportMode) (*ImportReport, error) {
+	report := &ImportReport{
+		Mode:  mode,
+		Total: len(rows),
+		Rows:  make([]ImportRowResult, len(rows)),
+	}
+
+	// Validate every row first
+	var valid []int
+	for i := range rows {
+		row := &rows[i]
+		result := &report.Rows[i]
+		result.Line = row.Line
+
+		if row.Err != nil {
+			result.Errors = []string{response.Translate(ctx, row.Err.Error())}
+		} else if errs := validateStudent(ctx, rules, &row.Student, row.Student.fields()); len(errs) > 0 {
+			for _, fe := range errs {
+				result.Errors = append(result.Errors, fe.Message)
+			}
+		} else if err := passwords.Validate(row.Student.Password); err != nil {
+			errs, ok := passwordFieldErrors(ctx, "password", err)
+			if !ok {
+				errs = response.FieldErrors(ctx, err)
+			}
+			for _, fe := range errs {
+				result.Errors = append(result.Errors, fe.Message)
+			}
+		}
+
+		result.Email = row.Student.Email
+		if result.Errors != nil {
+			result.Status = ImportInvalid
+			report.Invalid++
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	// An all-or-nothing import with invalid rows can stop here
+	if mode == ImportAllOrNothing && report.Invalid > 0 {
+		for _, i := range valid {
+			report.Rows[i].Status = ImportRolledBack
+			report.Rows[i].Errors = []string{response.Translate(ctx, storage.ErrRolledBack.Error())}
+		}
+		return report, nil
+	}
+
+	// Hashing dominates the cost of an import, so spread it over all CPUs
+	students := make([]*storage.Student, len(valid))
+	hashErrs := make([]error, len(valid))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for n, i := range valid {
+		req := rows[i].Student
+		students[n] = &storage.Student{
+			FirstName:      req.FirstName,
+			LastName:       req.LastName,
+			RegistrationNo: req.RegistrationNo,
+			PhoneNumber:    req.PhoneNumber,
+			Email:          req.Email,
+			Profile:        newProfile(req.DateOfBirth, req.Gender, req.Address, req.AdmissionDate),
+			Status:         storage.StudentStatus(req.Status),
+			Guardians:      storageGuardians(req.Guardians),
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(student *storage.Student, password string, err *error) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			student.Password, *err = passwords.Hash(password)
+		}(students[n], req.Password, &hashErrs[n])
+	}
+	wg.Wait()
+
+	for _, err := range hashErrs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	errs, err := store.ImportStudents(ctx, students, mode == ImportAllOrNothing)
+	if err != nil {
+		return nil, err
+	}
+
+	for n, i := range valid {
+		result := &report.Rows[i]
+		switch err := errs[n]; {
+		case err == nil:
+			result.Status = ImportCreated
+			result.ID = students[n].ID
+			report.Created++
+		case errors.Is(err, storage.ErrRolledBack):
+			result.Status = ImportRolledBack
+			result.Errors = []string{response.Translate(ctx, storage.ErrRolledBack.Error())}
+		case errors.Is(err, storage.ErrDuplicateEmail):
+			result.Status = ImportDuplicate
+			result.Errors = []string{response.Translate(ctx, storage.ErrDuplicateEmail.Error())}
+			report.Duplicates++
+		case errors.Is(err, storage.ErrDuplicateRegistrationNo):
+			result.Status = ImportDuplicate
+			result.Errors = []string{response.Translate(ctx, storage.ErrDuplicateRegistrationNo.Error())}
+			report.Duplicates++
+		default:
+			result.Status = ImportInvalid
+			result.Errors = []string{response.Translate(ctx, storageErrorDetail(err))}
+			report.Invalid++
+		}
+	}
+
+	report.Committed = mode == ImportBestEffort || report.Duplicates+report.Invalid == 0
+
+	return report, nil
+}
+
+// ImportStudentsHandler creates students in bulk from a CSV or NDJSON body,
+// chosen by Content-Type. The mode query parameter is all-or-nothing by
+// default or best-effort.
+func ImportStudentsHandler(store storage.Storage, passwords *auth.Passwords, rules *validation.StudentRules) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		mode := ImportMode(r.URL.Query().Get("mode"))
+		if mode == "" {
+			mode = ImportAllOrNothing
+		}
+		if !mode.Valid() {
+			response.Error(w, r, http.StatusBadRequest, "invalid mode parameter, must be all-or-nothing or best-effort")
+			return
+		}
+
+		contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if contentType == "application/ndjson" {
+			contentType = ndjsonType
+		}
+		if contentType != csvType && contentType != ndjsonType {
+			response.Error(w, r, http.StatusUnsupportedMediaType, "content type must be "+csvType+" or "+ndjsonType)
+			return
+		}
+
+		rows, err := ParseImport(contentType, http.MaxBytesReader(w, r.Body, maxImportBytes))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Error(w, r, http.StatusRequestEntityTooLarge, err.Error())
+			return
+		}
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		report, err := ImportStudents(r.Context(), store, passwords, rules, rows, mode)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		// A rolled back import created nothing, the report says why
+		status := http.StatusOK
+		if !report.Committed {
+			status = http.StatusUnprocessableEntity
+		}
+		response.Write(w, r, status, report)
+	}
+}
@@ -0,0 +1,114 @@
+package httphandler
+
+import (
+	"net/http"
+
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/utils/response"
+)
+
+type ListCoursesResponse struct {
+	Courses []*CourseResponse `json:"courses"`
+	Total   int               `json:"total"`
+	Limit   int               `json:"limit"`
+	Offset  int               `json:"offset"`
+	// Opaque tokens for the cursor parameter, empty when there is no such page
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// Items makes the list renderable as CSV, one row per course.
+func (l ListCoursesResponse) Items() any {
+	return l.Courses
+}
+
+func coursePosition(sortBy storage.SortField, course *storage.Course) storage.Cursor {
+	return storage.Cursor{Value: course.SortValue(sortBy), ID: course.ID}
+}
+
+// ListCoursesHandler lists the course catalogue, sorted by code unless the
+// sort parameter says otherwise. It takes the same paging parameters as
+// ListStudentsHandler.
+func ListCoursesHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse query parameters for pagination
+		limit, offset, err := parsePage(r.URL.Query())
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		cursorStr := r.URL.Query().Get("cursor")
+
+		// Parse filters and sort order
+		filter, err := parseCourseFilter(r.URL.Query())
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		sortBy, sortDesc, err := parseSort(r.URL.Query(), courseSortFields, storage.SortByCode, false)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Fetch one extra row to find out whether there is another page
+		opts := storage.CourseListOptions{
+			Filter:   filter,
+			SortBy:   sortBy,
+			SortDesc: sortDesc,
+			Limit:    limit + 1,
+			Offset:   offset,
+		}
+
+		// Parse cursor, it takes precedence over offset
+		if cursorStr != "" {
+			direction, position, err := decodeCursor(cursorStr, sortBy, sortDesc)
+			if err != nil {
+				response.Error(w, r, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			if direction == cursorNext {
+				opts.After = position
+			} else {
+				opts.Before = position
+			}
+			offset = 0
+		}
+
+		courses, err := store.ListCourses(r.Context(), opts)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		total, err := store.CountCourses(r.Context(), filter)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		courses, hasNext, hasPrev := trimPage(courses, limit, offset, opts.After != nil, opts.Before != nil)
+
+		resp := ListCoursesResponse{
+			Courses: make([]*CourseResponse, 0, len(courses)),
+			Total:   total,
+			Limit:   limit,
+			Offset:  offset,
+		}
+		for _, course := range courses {
+			resp.Courses = append(resp.Courses, newCourseResponse(course))
+		}
+		if len(courses) > 0 {
+			if hasNext {
+				resp.NextCursor = encodeCursor(cursorNext, sortBy, sortDesc, coursePosition(sortBy, courses[len(courses)-1]))
+			}
+			if hasPrev {
+				resp.PrevCursor = encodeCursor(cursorPrev, sortBy, sortDesc, coursePosition(sortBy, courses[0]))
+			}
+		}
+
+		response.Write(w, r, http.StatusOK, resp)
+	}
+}
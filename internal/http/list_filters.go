@@ -0,0 +1,200 @@
+package httphandler
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/smartcraze/student-api/internal/storage"
+)
+
+// Sortable fields by their JSON name
+var studentSortFields = map[string]storage.SortField{
+	"id":         storage.SortByID,
+	"first_name": storage.SortByFirstName,
+	"last_name":  storage.SortByLastName,
+	"reg_no":     storage.SortByRegistrationNo,
+	"email":      storage.SortByEmail,
+	"created_at": storage.SortByCreatedAt,
+	"updated_at": storage.SortByUpdatedAt,
+}
+
+// Sortable course fields by their JSON name
+var courseSortFields = map[string]storage.SortField{
+	"id":         storage.SortByID,
+	"code":       storage.SortByCode,
+	"title":      storage.SortByTitle,
+	"department": storage.SortByDepartment,
+	"credits":    storage.SortByCredits,
+	"created_at": storage.SortByCreatedAt,
+}
+
+// parseStudentFilter reads the student list filters from the query string:
+// name, status, reg_no_min, reg_no_max, created_from, created_to, updated_from, updated_to and q.
+func parseStudentFilter(query url.Values) (storage.StudentFilter, error) {
+	filter := storage.StudentFilter{
+		NamePrefix: strings.TrimSpace(query.Get("name")),
+		Query:      strings.TrimSpace(query.Get("q")),
+	}
+
+	if status := query.Get("status"); status != "" {
+		filter.Status = storage.StudentStatus(status)
+		if !filter.Status.Valid() {
+			return filter, fmt.Errorf("invalid status parameter")
+		}
+	}
+
+	var err error
+	if filter.RegistrationNoMin, err = parseIntParam(query, "reg_no_min"); err != nil {
+		return filter, err
+	}
+	if filter.RegistrationNoMax, err = parseIntParam(query, "reg_no_max"); err != nil {
+		return filter, err
+	}
+	if filter.CreatedFrom, err = parseTimeParam(query, "created_from", false); err != nil {
+		return filter, err
+	}
+	if filter.CreatedTo, err = parseTimeParam(query, "created_to", true); err != nil {
+		return filter, err
+	}
+	if filter.UpdatedFrom, err = parseTimeParam(query, "updated_from", false); err != nil {
+		return filter, err
+	}
+	if filter.UpdatedTo, err = parseTimeParam(query, "updated_to", true); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// parseStudentSort reads sort and order from the query string. The default is
+// newest first; an explicit sort field is ascending unless order=desc.
+func parseStudentSort(query url.Values) (storage.SortField, bool, error) {
+	return parseSort(query, studentSortFields, storage.SortByCreatedAt, true)
+}
+
+// parseSort reads sort and order, with fields keyed by their JSON name. Without
+// either parameter the list is sorted by def, descending if defDesc is set;
+// otherwise it is ascending unless order=desc.
+func parseSort(query url.Values, fields map[string]storage.SortField, def storage.SortField, defDesc bool) (storage.SortField, bool, error) {
+	sortStr := query.Get("sort")
+	orderStr := query.Get("order")
+
+	if sortStr == "" && orderStr == "" {
+		return def, defDesc, nil
+	}
+
+	field := def
+	if sortStr != "" {
+		var ok bool
+		field, ok = fields[sortStr]
+		if !ok {
+			return "", false, fmt.Errorf("invalid sort parameter")
+		}
+	}
+
+	switch orderStr {
+	case "", "asc":
+		return field, false, nil
+	case "desc":
+		return field, true, nil
+	default:
+		return "", false, fmt.Errorf("invalid order parameter")
+	}
+}
+
+// parseCourseFilter reads the course list filters from the query string:
+// department, active and q.
+func parseCourseFilter(query url.Values) (storage.CourseFilter, error) {
+	filter := storage.CourseFilter{
+		Department: strings.TrimSpace(query.Get("department")),
+		Query:      strings.TrimSpace(query.Get("q")),
+	}
+
+	if str := query.Get("active"); str != "" {
+		active, err := strconv.ParseBool(str)
+		if err != nil {
+			return filter, fmt.Errorf("invalid active parameter")
+		}
+		filter.Active = &active
+	}
+
+	return filter, nil
+}
+
+// parsePage reads the limit and offset parameters, limit defaults to 10 and is capped at 100.
+func parsePage(query url.Values) (limit, offset int, err error) {
+	limit = 10
+	if str := query.Get("limit"); str != "" {
+		limit, err = strconv.Atoi(str)
+		if err != nil || limit < 1 {
+			return 0, 0, fmt.Errorf("invalid limit parameter")
+		}
+	}
+
+	if str := query.Get("offset"); str != "" {
+		offset, err = strconv.Atoi(str)
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("invalid offset parameter")
+		}
+	}
+
+	// Limit max results to prevent excessive queries
+	if limit > 100 {
+		limit = 100
+	}
+
+	return limit, offset, nil
+}
+
+func parseIDParam(query url.Values, name string) (*int64, error) {
+	str := query.Get(name)
+	if str == "" {
+		return nil, nil
+	}
+
+	v, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter", name)
+	}
+	return &v, nil
+}
+
+func parseIntParam(query url.Values, name string) (*int, error) {
+	str := query.Get(name)
+	if str == "" {
+		return nil, nil
+	}
+
+	v, err := strconv.Atoi(str)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter", name)
+	}
+	return &v, nil
+}
+
+// parseTimeParam accepts RFC 3339 timestamps or plain dates. A plain date used
+// as an upper bound covers the whole day.
+func parseTimeParam(query url.Values, name string, endOfDay bool) (*time.Time, error) {
+	str := query.Get(name)
+	if str == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		t, err = time.Parse(time.DateOnly, str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s parameter", name)
+		}
+		if endOfDay {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+	}
+
+	// every backend stores its timestamps as UTC wall time
+	t = t.UTC()
+	return &t, nil
+}
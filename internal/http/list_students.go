@@ -2,7 +2,6 @@ package httphandler
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/smartcraze/student-api/internal/storage"
 	"github.com/smartcraze/student-api/utils/response"
@@ -13,78 +12,114 @@ type ListStudentsResponse struct {
 	Total    int                `json:"total"`
 	Limit    int                `json:"limit"`
 	Offset   int                `json:"offset"`
+	// Opaque tokens for the cursor parameter, empty when there is no such page
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// Items makes the list renderable as CSV, one row per student.
+func (l ListStudentsResponse) Items() any {
+	return l.Students
+}
+
+func studentPosition(sortBy storage.SortField, student *storage.Student) storage.Cursor {
+	return storage.Cursor{Value: student.SortValue(sortBy), ID: student.ID}
 }
 
 func ListStudentsHandler(store storage.Storage) http.HandlerFunc {
+	return listStudents(store, false)
+}
+
+// ListDeletedStudentsHandler lists the trash, with the same parameters as ListStudentsHandler.
+func ListDeletedStudentsHandler(store storage.Storage) http.HandlerFunc {
+	return listStudents(store, true)
+}
+
+func listStudents(store storage.Storage, deleted bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Parse query parameters for pagination
-		limitStr := r.URL.Query().Get("limit")
-		offsetStr := r.URL.Query().Get("offset")
-
-		// Default values
-		limit := 10
-		offset := 0
-
-		// Parse limit
-		if limitStr != "" {
-			parsedLimit, err := strconv.Atoi(limitStr)
-			if err != nil || parsedLimit < 1 {
-				response.Writejson(w, http.StatusBadRequest, response.Response{
-					Status: response.StatusError,
-					Error:  "invalid limit parameter",
-				})
-				return
-			}
-			limit = parsedLimit
+		limit, offset, err := parsePage(r.URL.Query())
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		cursorStr := r.URL.Query().Get("cursor")
+
+		// Parse filters and sort order
+		filter, err := parseStudentFilter(r.URL.Query())
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
 		}
+		filter.Deleted = deleted
 
-		// Parse offset
-		if offsetStr != "" {
-			parsedOffset, err := strconv.Atoi(offsetStr)
-			if err != nil || parsedOffset < 0 {
-				response.Writejson(w, http.StatusBadRequest, response.Response{
-					Status: response.StatusError,
-					Error:  "invalid offset parameter",
-				})
+		sortBy, sortDesc, err := parseStudentSort(r.URL.Query())
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Fetch one extra row to find out whether there is another page
+		opts := storage.ListOptions{
+			Filter:   filter,
+			SortBy:   sortBy,
+			SortDesc: sortDesc,
+			Limit:    limit + 1,
+			Offset:   offset,
+		}
+
+		// Parse cursor, it takes precedence over offset
+		if cursorStr != "" {
+			direction, position, err := decodeCursor(cursorStr, sortBy, sortDesc)
+			if err != nil {
+				response.Error(w, r, http.StatusBadRequest, err.Error())
 				return
 			}
-			offset = parsedOffset
-		}
 
-		// Limit max results to prevent excessive queries
-		if limit > 100 {
-			limit = 100
+			if direction == cursorNext {
+				opts.After = position
+			} else {
+				opts.Before = position
+			}
+			offset = 0
 		}
 
 		// Get students from database
-		students, err := store.ListStudents(r.Context(), limit, offset)
+		students, err := store.ListStudents(r.Context(), opts)
 		if err != nil {
-			response.Writejson(w, http.StatusInternalServerError, response.GeneralError(err))
+			writeStorageError(w, r, err)
 			return
 		}
 
+		total, err := store.CountStudents(r.Context(), filter)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		students, hasNext, hasPrev := trimPage(students, limit, offset, opts.After != nil, opts.Before != nil)
+
 		// Convert to response format (without passwords)
 		studentResponses := make([]*StudentResponse, 0, len(students))
 		for _, student := range students {
-			studentResponses = append(studentResponses, &StudentResponse{
-				ID:             student.ID,
-				FirstName:      student.FirstName,
-				LastName:       student.LastName,
-				RegistrationNo: student.RegistrationNo,
-				PhoneNumber:    student.PhoneNumber,
-				Email:          student.Email,
-				CreatedAt:      student.CreatedAt.Format("2006-01-02 15:04:05"),
-				UpdatedAt:      student.UpdatedAt.Format("2006-01-02 15:04:05"),
-			})
+			studentResponses = append(studentResponses, newStudentResponse(student))
 		}
 
 		resp := ListStudentsResponse{
 			Students: studentResponses,
-			Total:    len(studentResponses),
+			Total:    total,
 			Limit:    limit,
 			Offset:   offset,
 		}
+		if len(students) > 0 {
+			if hasNext {
+				resp.NextCursor = encodeCursor(cursorNext, sortBy, sortDesc, studentPosition(sortBy, students[len(students)-1]))
+			}
+			if hasPrev {
+				resp.PrevCursor = encodeCursor(cursorPrev, sortBy, sortDesc, studentPosition(sortBy, students[0]))
+			}
+		}
 
-		response.Writejson(w, http.StatusOK, resp)
+		response.Write(w, r, http.StatusOK, resp)
 	}
 }
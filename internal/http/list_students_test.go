@@ -0,0 +1,145 @@
+package httphandler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/smartcraze/student-api/internal/storage"
+)
+
+func listPage(t *testing.T, handler http.HandlerFunc, query url.Values) ListStudentsResponse {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/students?"+query.Encode(), nil)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s: status %d: %s", req.URL, rec.Code, rec.Body)
+	}
+
+	var resp ListStudentsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func pageIDs(resp ListStudentsResponse) []int64 {
+	ids := make([]int64, len(resp.Students))
+	for i, student := range resp.Students {
+		ids[i] = student.ID
+	}
+	return ids
+}
+
+// Walks every page with next_cursor and back with prev_cursor. The handler
+// fetches one row more than the limit to find out whether there is another
+// page, that row must never be shown.
+func TestListStudentsCursorPaging(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	lastNames := []string{"Lee", "Adams", "Lee", "Brown", "Adams", "Lee", "Young"}
+	for i, lastName := range lastNames {
+		err := store.CreateStudent(context.Background(), &storage.Student{
+			FirstName:      fmt.Sprintf("First%d", i),
+			LastName:       lastName,
+			RegistrationNo: 100 + i,
+			PhoneNumber:    fmt.Sprintf("+1555000%04d", i),
+			Email:          fmt.Sprintf("s%d@example.com", i),
+			Password:       "hash",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	handler := ListStudentsHandler(store)
+
+	tests := []struct {
+		sort  string
+		order string
+		limit int
+		want  []int64
+	}{
+		{"last_name", "asc", 2, []int64{2, 5, 4, 1, 3, 6, 7}},
+		{"last_name", "desc", 3, []int64{7, 6, 3, 1, 4, 5, 2}},
+		{"id", "asc", 7, []int64{1, 2, 3, 4, 5, 6, 7}},
+		{"id", "desc", 1, []int64{7, 6, 5, 4, 3, 2, 1}},
+		{"reg_no", "asc", 10, []int64{1, 2, 3, 4, 5, 6, 7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %s limit %d", tt.sort, tt.order, tt.limit), func(t *testing.T) {
+			query := url.Values{"sort": {tt.sort}, "order": {tt.order}, "limit": {fmt.Sprint(tt.limit)}}
+
+			var forward []int64
+			var pages []ListStudentsResponse
+			resp := listPage(t, handler, query)
+			for {
+				if resp.Total != len(tt.want) {
+					t.Fatalf("total %d, want %d", resp.Total, len(tt.want))
+				}
+				if len(resp.Students) > tt.limit {
+					t.Fatalf("page has %d students, limit is %d", len(resp.Students), tt.limit)
+				}
+				if len(pages) == 0 && resp.PrevCursor != "" {
+					t.Fatal("the first page has a prev_cursor")
+				}
+				forward = append(forward, pageIDs(resp)...)
+				pages = append(pages, resp)
+				if resp.NextCursor == "" {
+					break
+				}
+				query.Set("cursor", resp.NextCursor)
+				resp = listPage(t, handler, query)
+			}
+			if !slices.Equal(forward, tt.want) {
+				t.Fatalf("paging forward got %v, want %v", forward, tt.want)
+			}
+
+			// Back from the last page, each page must match the one seen going forward
+			for i := len(pages) - 1; i > 0; i-- {
+				query.Set("cursor", pages[i].PrevCursor)
+				resp := listPage(t, handler, query)
+				if !slices.Equal(pageIDs(resp), pageIDs(pages[i-1])) {
+					t.Fatalf("page %d going back is %v, going forward it was %v", i-1, pageIDs(resp), pageIDs(pages[i-1]))
+				}
+				if resp.NextCursor == "" {
+					t.Fatalf("page %d going back has no next_cursor", i-1)
+				}
+				if (i-1 > 0) != (resp.PrevCursor != "") {
+					t.Fatalf("page %d going back: prev_cursor %q", i-1, resp.PrevCursor)
+				}
+			}
+		})
+	}
+}
+
+func TestListStudentsOffsetPaging(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	for i := range 5 {
+		err := store.CreateStudent(context.Background(), &storage.Student{
+			FirstName:      "First",
+			LastName:       "Last",
+			RegistrationNo: 100 + i,
+			PhoneNumber:    "+15550000",
+			Email:          fmt.Sprintf("s%d@example.com", i),
+			Password:       "hash",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resp := listPage(t, ListStudentsHandler(store), url.Values{"sort": {"id"}, "limit": {"2"}, "offset": {"2"}})
+	if got := pageIDs(resp); !slices.Equal(got, []int64{3, 4}) {
+		t.Errorf("got %v, want [3 4]", got)
+	}
+	if resp.NextCursor == "" || resp.PrevCursor == "" {
+		t.Errorf("a middle page needs both cursors, got next %q prev %q", resp.NextCursor, resp.PrevCursor)
+	}
+}
@@ -0,0 +1,102 @@
+package httphandler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/smartcraze/student-api/internal/auth"
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/utils/response"
+)
+
+// Protect wraps a handler with authentication and the access policy
+// registered for its pattern in Policies. It panics when there is no policy,
+// so a route can't be exposed by accident.
+func Protect(pattern string, tokens *auth.TokenService, store storage.Storage, next http.HandlerFunc) http.Handler {
+	policy, ok := Policies[pattern]
+	if !ok {
+		panic(fmt.Sprintf("no access policy for route %q", pattern))
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, err := authenticate(r, tokens, store)
+		if err != nil && !errors.Is(err, auth.ErrInvalidToken) {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		// Public routes ignore bad tokens, e.g. an expired one sent to login
+		actor := storage.Actor{Name: "anonymous"}
+		if identity != nil {
+			r = r.WithContext(auth.WithIdentity(r.Context(), identity))
+			actor = storage.Actor{ID: identity.StudentID, Name: identity.Email}
+		}
+		r = r.WithContext(storage.WithActor(r.Context(), actor))
+		if policy.Public {
+			next(w, r)
+			return
+		}
+
+		if identity == nil {
+			msg := "authentication required"
+			if err != nil {
+				msg = err.Error()
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="student-api"`)
+			response.Error(w, r, http.StatusUnauthorized, msg)
+			return
+		}
+
+		if !policy.allows(identity, r.PathValue("id")) {
+			response.Error(w, r, http.StatusForbidden, "you are not allowed to access this resource")
+			return
+		}
+
+		next(w, r)
+	})
+}
+
+// authenticate reads the bearer token and checks it against the student it
+// was issued to. Tokens stop working once the student is deleted or their
+// sessions are ended, and role changes apply right away. It returns nil and
+// no error when the request has no Authorization header.
+func authenticate(r *http.Request, tokens *auth.TokenService, store storage.Storage) (*auth.Identity, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return nil, nil
+	}
+
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, auth.ErrInvalidToken
+	}
+
+	claims, err := tokens.ParseAccessToken(strings.TrimSpace(token))
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := claims.StudentID()
+	if err != nil {
+		return nil, auth.ErrInvalidToken
+	}
+
+	student, err := store.GetStudentByID(r.Context(), id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, auth.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if student.TokenGeneration != claims.Generation {
+		return nil, auth.ErrInvalidToken
+	}
+
+	return &auth.Identity{
+		StudentID: student.ID,
+		Email:     student.Email,
+		Role:      student.Role,
+	}, nil
+}
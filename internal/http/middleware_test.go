@@ -0,0 +1,217 @@
+package httphandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smartcraze/student-api/internal/auth"
+	"github.com/smartcraze/student-api/internal/config"
+	"github.com/smartcraze/student-api/internal/storage"
+)
+
+func testTokens(t *testing.T, keyID string) *auth.TokenService {
+	t.Helper()
+
+	tokens, err := auth.NewTokenService(config.Auth{
+		Issuer:         "student-api",
+		SigningKeys:    map[string]string{keyID: strings.Repeat(keyID, 32)},
+		SigningKeyID:   keyID,
+		AccessTokenTTL: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokens
+}
+
+// seedRoles creates one student with each role, and a second student.
+func seedRoles(t *testing.T, store storage.Storage) map[string]*storage.Student {
+	t.Helper()
+	ctx := context.Background()
+
+	roles := map[string]storage.Role{"admin": storage.RoleAdmin, "staff": storage.RoleStaff, "ada": storage.RoleStudent, "bob": storage.RoleStudent}
+	students := map[string]*storage.Student{}
+	i := 0
+	for name, role := range roles {
+		student := &storage.Student{
+			FirstName:      name,
+			LastName:       "Test",
+			RegistrationNo: 100 + i,
+			PhoneNumber:    fmt.Sprintf("+1555000%04d", i),
+			Email:          name + "@example.com",
+			Password:       "hash",
+		}
+		i++
+		if err := store.CreateStudent(ctx, student); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SetStudentRole(ctx, student.ID, role); err != nil {
+			t.Fatal(err)
+		}
+		student.Role = role
+		students[name] = student
+	}
+	return students
+}
+
+func bearer(t *testing.T, tokens *auth.TokenService, student *storage.Student) string {
+	t.Helper()
+
+	token, _, err := tokens.IssueAccessToken(student)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + token
+}
+
+func TestProtect(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	tokens := testTokens(t, "k1")
+	students := seedRoles(t, store)
+
+	// the handler answers with who the request is acting for
+	echo := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, storage.ActorFrom(r.Context()).Name)
+	}
+	mux := http.NewServeMux()
+	for _, pattern := range []string{"POST /api/auth/login", "GET /api/student/{id}", "DELETE /api/student/{id}", "PUT /api/student/{id}/password"} {
+		mux.Handle(pattern, Protect(pattern, tokens, store, echo))
+	}
+
+	path := func(name string) string {
+		return fmt.Sprintf("/api/student/%d", students[name].ID)
+	}
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		// who the token is issued to, "" sends none
+		as     string
+		header string
+		want   int
+		body   string
+	}{
+		{"public without a token", http.MethodPost, "/api/auth/login", "", "", http.StatusOK, "anonymous"},
+		{"public ignores a bad token", http.MethodPost, "/api/auth/login", "", "Bearer nonsense", http.StatusOK, "anonymous"},
+		{"no token", http.MethodGet, path("ada"), "", "", http.StatusUnauthorized, "authentication required"},
+		{"malformed token", http.MethodGet, path("ada"), "", "Bearer nonsense", http.StatusUnauthorized, "invalid or expired token"},
+		{"wrong scheme", http.MethodGet, path("ada"), "", "Basic YWRhOmFkYQ==", http.StatusUnauthorized, "invalid or expired token"},
+		{"owner", http.MethodGet, path("ada"), "ada", "", http.StatusOK, "ada@example.com"},
+		{"another student", http.MethodGet, path("bob"), "ada", "", http.StatusForbidden, "not allowed"},
+		{"staff reads anyone", http.MethodGet, path("bob"), "staff", "", http.StatusOK, "staff@example.com"},
+		{"staff may not delete", http.MethodDelete, path("bob"), "staff", "", http.StatusForbidden, "not allowed"},
+		{"owner may not delete", http.MethodDelete, path("ada"), "ada", "", http.StatusForbidden, "not allowed"},
+		{"admin deletes", http.MethodDelete, path("bob"), "admin", "", http.StatusOK, "admin@example.com"},
+		// owner only, the roles of admins don't help
+		{"owner only route", http.MethodPut, path("ada") + "/password", "ada", "", http.StatusOK, "ada@example.com"},
+		{"owner only route as admin", http.MethodPut, path("ada") + "/password", "admin", "", http.StatusForbidden, "not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.as != "" {
+				req.Header.Set("Authorization", bearer(t, tokens, students[tt.as]))
+			} else if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.body) {
+				t.Errorf("body %q doesn't contain %q", rec.Body, tt.body)
+			}
+			if rec.Code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without a WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestProtectPanicsWithoutPolicy(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("a route without a policy was protected")
+		}
+	}()
+	Protect("GET /api/unlisted", testTokens(t, "k1"), storage.NewMemoryStorage(), func(http.ResponseWriter, *http.Request) {})
+}
+
+// authenticate reloads the student on every request, so a token stops
+// working as soon as the student is signed out or deleted, and a role change
+// applies right away.
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStorage()
+	tokens := testTokens(t, "k1")
+	students := seedRoles(t, store)
+
+	request := func(header string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api/students", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		return req
+	}
+
+	identity, err := authenticate(request(""), tokens, store)
+	if identity != nil || err != nil {
+		t.Errorf("got %+v and %v without a token, want neither", identity, err)
+	}
+
+	ada := bearer(t, tokens, students["ada"])
+	identity, err = authenticate(request(ada), tokens, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.StudentID != students["ada"].ID || identity.Role != storage.RoleStudent {
+		t.Errorf("identity is %+v", identity)
+	}
+
+	// the role in the token is ignored for the stored one
+	if err := store.SetStudentRole(ctx, students["ada"].ID, storage.RoleStaff); err != nil {
+		t.Fatal(err)
+	}
+	if identity, err = authenticate(request(ada), tokens, store); err != nil || identity.Role != storage.RoleStaff {
+		t.Errorf("got %+v and %v after a role change, want staff", identity, err)
+	}
+
+	// a key the service doesn't know
+	other := bearer(t, testTokens(t, "k2"), students["ada"])
+	if _, err := authenticate(request(other), tokens, store); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("got %v for an unknown signing key, want ErrInvalidToken", err)
+	}
+
+	// signing out bumps the token generation
+	if err := store.RevokeStudentRefreshTokens(ctx, students["ada"].ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := authenticate(request(ada), tokens, store); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("got %v for a stale token generation, want ErrInvalidToken", err)
+	}
+	refreshed, err := store.GetStudentByID(ctx, students["ada"].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := authenticate(request(bearer(t, tokens, refreshed)), tokens, store); err != nil {
+		t.Errorf("got %v for a token of the current generation", err)
+	}
+
+	// a deleted student's token of the current generation
+	bob := bearer(t, tokens, students["bob"])
+	if err := store.DeleteStudent(ctx, students["bob"].ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := authenticate(request(bob), tokens, store); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("got %v for a deleted student, want ErrInvalidToken", err)
+	}
+}
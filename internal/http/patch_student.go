@@ -0,0 +1,226 @@
+package httphandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/internal/validation"
+	"github.com/smartcraze/student-api/utils/response"
+)
+
+const (
+	mergePatchType = "application/merge-patch+json"
+	jsonPatchType  = "application/json-patch+json"
+)
+
+// applyPatch applies a merge patch (RFC 7396) or JSON Patch (RFC 6902)
+// document to doc, depending on the request content type.
+func applyPatch(contentType string, doc, patch []byte) ([]byte, error) {
+	switch contentType {
+	case mergePatchType:
+		return jsonpatch.MergePatch(doc, patch)
+	default:
+		ops, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, err
+		}
+		return ops.Apply(doc)
+	}
+}
+
+func PatchStudentHandler(store storage.Storage, rules *validation.StudentRules) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get student ID from URL path parameter
+		idStr := r.PathValue("id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, "invalid student ID")
+			return
+		}
+
+		contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if contentType != mergePatchType && contentType != jsonPatchType {
+			w.Header().Set("Accept-Patch", mergePatchType+", "+jsonPatchType)
+			response.Error(w, r, http.StatusUnsupportedMediaType, "content type must be "+mergePatchType+" or "+jsonPatchType)
+			return
+		}
+
+		patch, err := io.ReadAll(r.Body)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		student, err := store.GetStudentByID(r.Context(), id)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		// A stale If-Match fails before the patch is even applied
+		version, err := ifMatchVersion(r, func() (*storage.Student, error) {
+			return student, nil
+		})
+		if err == nil && version != 0 && version != student.Version {
+			err = storage.ErrVersionMismatch
+		}
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		guardians, err := store.ListGuardians(r.Context(), id)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		// Patch the editable fields, in the same shape the PUT endpoint takes
+		current := UpdateStudentRequest{
+			FirstName:      student.FirstName,
+			LastName:       student.LastName,
+			RegistrationNo: student.RegistrationNo,
+			PhoneNumber:    student.PhoneNumber,
+			Email:          student.Email,
+			DateOfBirth:    formatDate(student.DateOfBirth),
+			Gender:         student.Gender,
+			Address:        addressFields(student.Address),
+			AdmissionDate:  formatDate(student.AdmissionDate),
+			Guardians:      guardianFields(guardians),
+		}
+		doc, err := json.Marshal(current)
+		if err != nil {
+			response.Error(w, r, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		patched, err := applyPatch(contentType, doc, patch)
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			response.Error(w, r, http.StatusConflict, err.Error())
+			return
+		}
+		if err != nil {
+			response.Error(w, r, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+
+		// Password and role have their own endpoints, so anything else is rejected
+		var req UpdateStudentRequest
+		decoder := json.NewDecoder(bytes.NewReader(patched))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&req); err != nil {
+			response.Error(w, r, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+
+		// Only the changed fields are normalized, validated and written. The patch
+		// was applied to this version, so the write is always conditional on it.
+		update := storage.StudentUpdate{Version: student.Version}
+		var fields validation.StudentFields
+		var changed []string
+		if req.FirstName != current.FirstName {
+			update.FirstName = &req.FirstName
+			fields.FirstName = &req.FirstName
+			changed = append(changed, "FirstName")
+		}
+		if req.LastName != current.LastName {
+			update.LastName = &req.LastName
+			fields.LastName = &req.LastName
+			changed = append(changed, "LastName")
+		}
+		if req.RegistrationNo != current.RegistrationNo {
+			update.RegistrationNo = &req.RegistrationNo
+			fields.RegistrationNo = &req.RegistrationNo
+			changed = append(changed, "RegistrationNo")
+		}
+		if req.PhoneNumber != current.PhoneNumber {
+			update.PhoneNumber = &req.PhoneNumber
+			fields.PhoneNumber = &req.PhoneNumber
+			changed = append(changed, "PhoneNumber")
+		}
+		if req.Email != current.Email {
+			update.Email = &req.Email
+			fields.Email = &req.Email
+			changed = append(changed, "Email")
+		}
+
+		if req.DateOfBirth != current.DateOfBirth {
+			fields.DateOfBirth = &req.DateOfBirth
+			changed = append(changed, "DateOfBirth")
+		}
+		if req.Gender != current.Gender {
+			fields.Gender = &req.Gender
+			changed = append(changed, "Gender")
+		}
+		if req.Address != current.Address {
+			fields.Country = &req.Address.Country
+			changed = append(changed, "Address.Line1", "Address.Line2", "Address.City", "Address.Region", "Address.PostalCode", "Address.Country")
+		}
+		if req.AdmissionDate != current.AdmissionDate {
+			changed = append(changed, "AdmissionDate")
+		}
+		profileChanged := req.DateOfBirth != current.DateOfBirth || req.Gender != current.Gender ||
+			req.Address != current.Address || req.AdmissionDate != current.AdmissionDate
+
+		if req.AdmissionDate != current.AdmissionDate && !isStaff(r.Context()) {
+			response.Error(w, r, http.StatusForbidden, errStaffOnly)
+			return
+		}
+
+		guardiansChanged := !slices.Equal(req.Guardians, current.Guardians)
+		if guardiansChanged {
+			fields.Guardians = guardianRules(req.Guardians)
+			changed = append(changed, "Guardians")
+			for i := range req.Guardians {
+				for _, field := range []string{"Name", "Relationship", "PhoneNumber", "Email"} {
+					changed = append(changed, fmt.Sprintf("Guardians[%d].%s", i, field))
+				}
+			}
+		}
+
+		if len(changed) > 0 {
+			if errs := validateStudent(r.Context(), rules, &req, fields, changed...); len(errs) > 0 {
+				response.ValidationFailed(w, r, errs)
+				return
+			}
+		}
+
+		// The profile and guardians are replaced as a whole, from the normalized request
+		if profileChanged {
+			profile := newProfile(req.DateOfBirth, req.Gender, req.Address, req.AdmissionDate)
+			update.Profile = &profile
+		}
+		if guardiansChanged {
+			replaced := storageGuardians(req.Guardians)
+			update.Guardians = &replaced
+		}
+
+		student, err = store.UpdateStudent(r.Context(), id, update)
+		if errors.Is(err, storage.ErrVersionMismatch) && version == 0 {
+			// the client didn't ask for a precondition, so it is just a lost race
+			err = storage.ErrConflict
+		}
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+		if student.Guardians == nil {
+			student.Guardians = guardians
+		}
+
+		// Return updated student
+		w.Header().Set("ETag", studentETag(student))
+		resp := newStudentResponse(student)
+
+		response.Write(w, r, http.StatusOK, resp)
+	}
+}
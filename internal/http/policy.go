@@ -0,0 +1,111 @@
+package httphandler
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/smartcraze/student-api/internal/auth"
+	"github.com/smartcraze/student-api/internal/storage"
+)
+
+// Policy says who may call a route.
+type Policy struct {
+	// Public routes don't need a token
+	Public bool
+	// Roles that may call the route
+	Roles []storage.Role
+	// Owner also lets the student named by the {id} path value call the route
+	Owner bool
+}
+
+var (
+	adminOnly     = []storage.Role{storage.RoleAdmin}
+	adminAndStaff = []storage.Role{storage.RoleAdmin, storage.RoleStaff}
+	everyone      = []storage.Role{storage.RoleAdmin, storage.RoleStaff, storage.RoleStudent}
+)
+
+// Policies is the access table for every route, keyed by its ServeMux
+// pattern. A route without an entry here can't be registered.
+var Policies = map[string]Policy{
+	"GET /": {Public: true},
+
+	"POST /api/auth/login":   {Public: true},
+	"POST /api/auth/refresh": {Public: true},
+	"POST /api/auth/logout":  {Public: true},
+	"POST /api/auth/forgot":  {Public: true},
+	"POST /api/auth/reset":   {Public: true},
+
+	// self-registration, new accounts always get the student role
+	"POST /api/student/create": {Public: true},
+	"GET /api/student/{id}":    {Roles: adminAndStaff, Owner: true},
+	"PUT /api/student/{id}":    {Roles: adminOnly, Owner: true},
+	"PATCH /api/student/{id}":  {Roles: adminOnly, Owner: true},
+	"DELETE /api/student/{id}": {Roles: adminOnly},
+	"GET /api/students":        {Roles: adminAndStaff},
+	"GET /api/students/export": {Roles: adminAndStaff},
+	// bulk accounts, unlike self-registration
+	"POST /api/students/import": {Roles: adminOnly},
+	// the trash is admin only, like deleting
+	"GET /api/students/trash":         {Roles: adminOnly},
+	"POST /api/student/{id}/restore":  {Roles: adminOnly},
+	"DELETE /api/students/trash/{id}": {Roles: adminOnly},
+	"GET /api/student/search":         {Roles: adminAndStaff},
+	// requires the current password, so only the student themselves
+	"PUT /api/student/{id}/password": {Owner: true},
+	"GET /api/student/{id}/history":  {Roles: adminAndStaff, Owner: true},
+	// the lifecycle guards can narrow a transition down to admins
+	"POST /api/student/{id}/transitions": {Roles: adminAndStaff},
+	"GET /api/student/{id}/transitions":  {Roles: adminAndStaff, Owner: true},
+	"GET /api/audit":                     {Roles: adminOnly},
+
+	// every signed in student can browse the catalogue
+	"GET /api/courses":         {Roles: everyone},
+	"GET /api/courses/{id}":    {Roles: everyone},
+	"POST /api/courses":        {Roles: adminAndStaff},
+	"PUT /api/courses/{id}":    {Roles: adminAndStaff},
+	"DELETE /api/courses/{id}": {Roles: adminOnly},
+
+	"GET /api/terms":                                          {Roles: everyone},
+	"POST /api/terms":                                         {Roles: adminAndStaff},
+	"GET /api/courses/{id}/sections":                          {Roles: everyone},
+	"POST /api/courses/{id}/sections":                         {Roles: adminAndStaff},
+	"GET /api/sections/{id}":                                  {Roles: everyone},
+	"GET /api/sections/{id}/roster":                           {Roles: adminAndStaff},
+	"POST /api/student/{id}/enrollments":                      {Roles: adminAndStaff, Owner: true},
+	"GET /api/student/{id}/enrollments":                       {Roles: adminAndStaff, Owner: true},
+	"DELETE /api/student/{id}/enrollments/{enrollment_id}":    {Roles: adminAndStaff, Owner: true},
+	"PUT /api/student/{id}/enrollments/{enrollment_id}/grade": {Roles: adminAndStaff},
+	"GET /api/courses/{id}/prerequisites":                     {Roles: everyone},
+	"PUT /api/courses/{id}/prerequisites":                     {Roles: adminAndStaff},
+	"PUT /api/sections/{id}/meetings":                         {Roles: adminAndStaff},
+}
+
+func (p Policy) allows(identity *auth.Identity, pathID string) bool {
+	if p.Public {
+		return true
+	}
+	if identity == nil {
+		return false
+	}
+	if slices.Contains(p.Roles, identity.Role) {
+		return true
+	}
+	return p.Owner && pathID == strconv.FormatInt(identity.StudentID, 10)
+}
+
+// String describes the policy for `student routes`.
+func (p Policy) String() string {
+	if p.Public {
+		return "public"
+	}
+
+	var who []string
+	for _, role := range p.Roles {
+		who = append(who, string(role))
+	}
+	if p.Owner {
+		who = append(who, "owner")
+	}
+	return strings.Join(who, ", ")
+}
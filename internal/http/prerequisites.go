@@ -0,0 +1,134 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/utils/response"
+)
+
+type PrerequisiteFields struct {
+	CourseID int64 `json:"course_id" validate:"required,gt=0"`
+	// MinGrade defaults to the lowest passing grade
+	MinGrade string `json:"min_grade,omitempty" validate:"omitempty,oneof=A A- B+ B B- C+ C C- D+ D D- F"`
+}
+
+// PrerequisitesRequest replaces the prerequisites of a course. Every group
+// must be met, a group by passing any one of its courses.
+type PrerequisitesRequest struct {
+	Groups [][]PrerequisiteFields `json:"groups" validate:"max=10,dive,min=1,max=10,dive"`
+}
+
+type PrerequisiteResponse struct {
+	CourseID   int64  `json:"course_id"`
+	CourseCode string `json:"course_code"`
+	MinGrade   string `json:"min_grade"`
+}
+
+type PrerequisitesResponse struct {
+	Groups [][]*PrerequisiteResponse `json:"groups"`
+}
+
+func newPrerequisiteGroup(group []storage.Prerequisite) []*PrerequisiteResponse {
+	resp := make([]*PrerequisiteResponse, len(group))
+	for i, p := range group {
+		resp[i] = &PrerequisiteResponse{CourseID: p.CourseID, CourseCode: p.CourseCode, MinGrade: string(p.MinGrade)}
+	}
+	return resp
+}
+
+func newPrerequisitesResponse(groups [][]storage.Prerequisite) PrerequisitesResponse {
+	resp := PrerequisitesResponse{Groups: make([][]*PrerequisiteResponse, len(groups))}
+	for i, group := range groups {
+		resp.Groups[i] = newPrerequisiteGroup(group)
+	}
+	return resp
+}
+
+func GetPrerequisitesHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := courseID(w, r)
+		if !ok {
+			return
+		}
+
+		if _, err := store.GetCourse(r.Context(), id); err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		groups, err := store.ListPrerequisites(r.Context(), id)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.Write(w, r, http.StatusOK, newPrerequisitesResponse(groups))
+	}
+}
+
+// SetPrerequisitesHandler replaces the prerequisites of the course in {id},
+// an empty list of groups removes them.
+func SetPrerequisitesHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := courseID(w, r)
+		if !ok {
+			return
+		}
+
+		var req PrerequisitesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		for _, group := range req.Groups {
+			for i := range group {
+				group[i].MinGrade = strings.ToUpper(strings.TrimSpace(group[i].MinGrade))
+			}
+		}
+
+		if err := validate.Struct(req); err != nil {
+			response.ValidationFailed(w, r, response.FieldErrors(r.Context(), err))
+			return
+		}
+
+		groups := make([][]storage.Prerequisite, len(req.Groups))
+		for i, group := range req.Groups {
+			seen := map[int64]bool{}
+			for _, p := range group {
+				if seen[p.CourseID] {
+					field := fmt.Sprintf("groups[%d]", i)
+					response.ValidationFailed(w, r, []response.FieldError{
+						response.NewFieldError(r.Context(), field, "distinct", "course_id", field+" lists the same course_id twice"),
+					})
+					return
+				}
+				seen[p.CourseID] = true
+
+				grade := storage.Grade(p.MinGrade)
+				if grade == "" {
+					grade = storage.PassingGrade
+				}
+				groups[i] = append(groups[i], storage.Prerequisite{CourseID: p.CourseID, MinGrade: grade})
+			}
+		}
+
+		if err := store.SetPrerequisites(r.Context(), id, groups); err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		// Read them back for the course codes
+		stored, err := store.ListPrerequisites(r.Context(), id)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.Write(w, r, http.StatusOK, newPrerequisitesResponse(stored))
+	}
+}
@@ -0,0 +1,157 @@
+package httphandler
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/smartcraze/student-api/internal/auth"
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/internal/validation"
+)
+
+// AddressFields is the postal address of a student, every part is optional.
+type AddressFields struct {
+	Line1      string `json:"line1,omitempty" validate:"max=200"`
+	Line2      string `json:"line2,omitempty" validate:"max=200"`
+	City       string `json:"city,omitempty" validate:"max=100"`
+	Region     string `json:"region,omitempty" validate:"max=100"`
+	PostalCode string `json:"postal_code,omitempty" validate:"max=20"`
+	Country    string `json:"country,omitempty" validate:"omitempty,iso3166_1_alpha2"`
+}
+
+// GuardianFields is a guardian or emergency contact of a student.
+type GuardianFields struct {
+	Name             string `json:"name" validate:"required,max=200"`
+	Relationship     string `json:"relationship" validate:"required,max=50"`
+	PhoneNumber      string `json:"phone_number" validate:"required"`
+	Email            string `json:"email,omitempty" validate:"omitempty,email,max=255"`
+	EmergencyContact bool   `json:"emergency_contact"`
+}
+
+type GuardianResponse struct {
+	ID               int64  `json:"id"`
+	Name             string `json:"name"`
+	Relationship     string `json:"relationship"`
+	PhoneNumber      string `json:"phone_number"`
+	Email            string `json:"email,omitempty"`
+	EmergencyContact bool   `json:"emergency_contact"`
+}
+
+// errStaffOnly is the 403 detail for a student setting their own status or
+// admission date.
+const errStaffOnly = "only admins and staff can set status and admission_date"
+
+// isStaff reports whether the caller is an admin or staff member. Only they
+// may set the enrollment status and admission date.
+func isStaff(ctx context.Context) bool {
+	identity := auth.IdentityFrom(ctx)
+	return identity != nil && slices.Contains(adminAndStaff, identity.Role)
+}
+
+// parseDate reads an optional date the validator has already checked with
+// datetime=2006-01-02, nil when it is empty.
+func parseDate(str string) *time.Time {
+	if str == "" {
+		return nil
+	}
+	t, err := time.Parse(time.DateOnly, str)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.DateOnly)
+}
+
+// newProfile builds the stored profile from the fields of a request.
+func newProfile(dateOfBirth, gender string, address AddressFields, admissionDate string) storage.Profile {
+	return storage.Profile{
+		DateOfBirth:   parseDate(dateOfBirth),
+		Gender:        gender,
+		Address:       address.address(),
+		AdmissionDate: parseDate(admissionDate),
+	}
+}
+
+func (a AddressFields) address() storage.Address {
+	return storage.Address{
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+}
+
+func addressFields(a storage.Address) AddressFields {
+	return AddressFields{
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+}
+
+// guardianRules points the student rules at the guardians of a request.
+func guardianRules(guardians []GuardianFields) []validation.GuardianFields {
+	fields := make([]validation.GuardianFields, len(guardians))
+	for i := range guardians {
+		g := &guardians[i]
+		fields[i] = validation.GuardianFields{Name: &g.Name, PhoneNumber: &g.PhoneNumber, Email: &g.Email}
+	}
+	return fields
+}
+
+// storageGuardians converts the guardians of a request, an empty list
+// removes every guardian.
+func storageGuardians(guardians []GuardianFields) []storage.Guardian {
+	stored := make([]storage.Guardian, len(guardians))
+	for i, g := range guardians {
+		stored[i] = storage.Guardian{
+			Name:             g.Name,
+			Relationship:     g.Relationship,
+			PhoneNumber:      g.PhoneNumber,
+			Email:            g.Email,
+			EmergencyContact: g.EmergencyContact,
+		}
+	}
+	return stored
+}
+
+func guardianFields(guardians []storage.Guardian) []GuardianFields {
+	fields := make([]GuardianFields, len(guardians))
+	for i, g := range guardians {
+		fields[i] = GuardianFields{
+			Name:             g.Name,
+			Relationship:     g.Relationship,
+			PhoneNumber:      g.PhoneNumber,
+			Email:            g.Email,
+			EmergencyContact: g.EmergencyContact,
+		}
+	}
+	return fields
+}
+
+func guardianResponses(guardians []storage.Guardian) []GuardianResponse {
+	resp := make([]GuardianResponse, len(guardians))
+	for i, g := range guardians {
+		resp[i] = GuardianResponse{
+			ID:               g.ID,
+			Name:             g.Name,
+			Relationship:     g.Relationship,
+			PhoneNumber:      g.PhoneNumber,
+			Email:            g.Email,
+			EmergencyContact: g.EmergencyContact,
+		}
+	}
+	return resp
+}
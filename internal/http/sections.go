@@ -0,0 +1,362 @@
+package httphandler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/utils/response"
+)
+
+type SectionRequest struct {
+	TermID int64  `json:"term_id" validate:"required,gt=0"`
+	Code   string `json:"code" validate:"required,max=20"`
+	// Capacity defaults to the capacity of the course
+	Capacity int             `json:"capacity,omitempty" validate:"omitempty,gt=0,max=1000"`
+	Meetings []MeetingFields `json:"meetings,omitempty" validate:"max=20,dive"`
+}
+
+// MeetingFields is a weekly class of a section, times are HH:MM.
+type MeetingFields struct {
+	Day      string `json:"day" validate:"required,oneof=mon tue wed thu fri sat sun"`
+	StartsAt string `json:"starts_at" validate:"required,datetime=15:04"`
+	EndsAt   string `json:"ends_at" validate:"required,datetime=15:04"`
+	Room     string `json:"room,omitempty" validate:"max=50"`
+}
+
+type MeetingsRequest struct {
+	Meetings []MeetingFields `json:"meetings" validate:"max=20,dive"`
+}
+
+type SectionResponse struct {
+	ID          int64  `json:"id"`
+	CourseID    int64  `json:"course_id"`
+	CourseCode  string `json:"course_code"`
+	CourseTitle string `json:"course_title"`
+	TermID      int64  `json:"term_id"`
+	TermCode    string `json:"term_code"`
+	Code        string `json:"code"`
+	Capacity    int    `json:"capacity"`
+	Enrolled    int    `json:"enrolled"`
+	Waitlisted  int    `json:"waitlisted"`
+	Available   int    `json:"available"`
+	// Meetings are in the order of the week, from Sunday
+	Meetings  []MeetingFields `json:"meetings"`
+	CreatedAt string          `json:"created_at"`
+}
+
+type ListSectionsResponse struct {
+	Sections []*SectionResponse `json:"sections"`
+}
+
+func (l ListSectionsResponse) Items() any {
+	return l.Sections
+}
+
+type RosterEntryResponse struct {
+	EnrollmentID   int64  `json:"enrollment_id"`
+	StudentID      int64  `json:"student_id"`
+	FirstName      string `json:"first_name"`
+	LastName       string `json:"last_name"`
+	Email          string `json:"email"`
+	RegistrationNo int    `json:"reg_no"`
+	Status         string `json:"status"`
+	// Position is the place on the waitlist
+	Position   int    `json:"position,omitempty"`
+	EnrolledAt string `json:"enrolled_at"`
+}
+
+type RosterResponse struct {
+	Section  *SectionResponse       `json:"section"`
+	Enrolled []*RosterEntryResponse `json:"enrolled"`
+	Waitlist []*RosterEntryResponse `json:"waitlist"`
+}
+
+// Items makes the roster renderable as CSV, the enrolled students first and
+// then the waitlist.
+func (r RosterResponse) Items() any {
+	return append(append([]*RosterEntryResponse{}, r.Enrolled...), r.Waitlist...)
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func newMeetingFields(m storage.Meeting) MeetingFields {
+	return MeetingFields{
+		Day:      strings.ToLower(m.Weekday.String()[:3]),
+		StartsAt: fmt.Sprintf("%02d:%02d", m.StartsAt/60, m.StartsAt%60),
+		EndsAt:   fmt.Sprintf("%02d:%02d", m.EndsAt/60, m.EndsAt%60),
+		Room:     m.Room,
+	}
+}
+
+// parseMeetings converts meetings that passed validation, and checks that
+// each ends after it starts.
+func parseMeetings(ctx context.Context, meetings []MeetingFields) ([]storage.Meeting, []response.FieldError) {
+	minutes := func(hhmm string) int {
+		t, _ := time.Parse("15:04", hhmm)
+		return t.Hour()*60 + t.Minute()
+	}
+
+	parsed := make([]storage.Meeting, len(meetings))
+	var errs []response.FieldError
+	for i, m := range meetings {
+		parsed[i] = storage.Meeting{
+			Weekday:  weekdays[m.Day],
+			StartsAt: minutes(m.StartsAt),
+			EndsAt:   minutes(m.EndsAt),
+			Room:     m.Room,
+		}
+		if parsed[i].EndsAt <= parsed[i].StartsAt {
+			field := fmt.Sprintf("meetings[%d].ends_at", i)
+			errs = append(errs, response.NewFieldError(ctx, field, "after", "starts_at", field+" must be after starts_at"))
+		}
+	}
+	return parsed, errs
+}
+
+func newSectionResponse(section *storage.Section) *SectionResponse {
+	meetings := make([]MeetingFields, len(section.Meetings))
+	for i, m := range section.Meetings {
+		meetings[i] = newMeetingFields(m)
+	}
+
+	return &SectionResponse{
+		ID:          section.ID,
+		CourseID:    section.CourseID,
+		CourseCode:  section.CourseCode,
+		CourseTitle: section.CourseTitle,
+		TermID:      section.TermID,
+		TermCode:    section.TermCode,
+		Code:        section.Code,
+		Capacity:    section.Capacity,
+		Enrolled:    section.Enrolled,
+		Waitlisted:  section.Waitlisted,
+		Available:   section.Available(),
+		Meetings:    meetings,
+		CreatedAt:   section.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// sectionID reads the {id} path value, writing a 400 if it isn't a number.
+func sectionID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		response.Error(w, r, http.StatusBadRequest, "invalid section ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// CreateSectionHandler offers the course in {id} in a term.
+func CreateSectionHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := courseID(w, r)
+		if !ok {
+			return
+		}
+
+		var req SectionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		req.Code = strings.ToUpper(strings.TrimSpace(req.Code))
+		for i := range req.Meetings {
+			req.Meetings[i].Day = strings.ToLower(strings.TrimSpace(req.Meetings[i].Day))
+		}
+
+		if err := validate.Struct(req); err != nil {
+			response.ValidationFailed(w, r, response.FieldErrors(r.Context(), err))
+			return
+		}
+		meetings, errs := parseMeetings(r.Context(), req.Meetings)
+		if len(errs) > 0 {
+			response.ValidationFailed(w, r, errs)
+			return
+		}
+
+		course, err := store.GetCourse(r.Context(), id)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		section := &storage.Section{
+			CourseID: id,
+			TermID:   req.TermID,
+			Code:     req.Code,
+			Capacity: req.Capacity,
+			Meetings: meetings,
+		}
+		if section.Capacity == 0 {
+			section.Capacity = course.Capacity
+		}
+
+		if err := store.CreateSection(r.Context(), section); err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		// Read it back for the course and term codes
+		created, err := store.GetSection(r.Context(), section.ID)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.Write(w, r, http.StatusCreated, newSectionResponse(created))
+	}
+}
+
+// ListSectionsHandler lists the sections of the course in {id}, the latest
+// term first.
+func ListSectionsHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := courseID(w, r)
+		if !ok {
+			return
+		}
+
+		if _, err := store.GetCourse(r.Context(), id); err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		sections, err := store.ListSections(r.Context(), id)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		resp := ListSectionsResponse{Sections: make([]*SectionResponse, len(sections))}
+		for i, section := range sections {
+			resp.Sections[i] = newSectionResponse(section)
+		}
+
+		response.Write(w, r, http.StatusOK, resp)
+	}
+}
+
+func GetSectionHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := sectionID(w, r)
+		if !ok {
+			return
+		}
+
+		section, err := store.GetSection(r.Context(), id)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.Write(w, r, http.StatusOK, newSectionResponse(section))
+	}
+}
+
+// SetSectionMeetingsHandler replaces the weekly classes of the section in
+// {id}. The students already in it are not checked for clashes.
+func SetSectionMeetingsHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := sectionID(w, r)
+		if !ok {
+			return
+		}
+
+		var req MeetingsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		for i := range req.Meetings {
+			req.Meetings[i].Day = strings.ToLower(strings.TrimSpace(req.Meetings[i].Day))
+		}
+
+		if err := validate.Struct(req); err != nil {
+			response.ValidationFailed(w, r, response.FieldErrors(r.Context(), err))
+			return
+		}
+		meetings, errs := parseMeetings(r.Context(), req.Meetings)
+		if len(errs) > 0 {
+			response.ValidationFailed(w, r, errs)
+			return
+		}
+
+		if err := store.SetSectionMeetings(r.Context(), id, meetings); err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		section, err := store.GetSection(r.Context(), id)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.Write(w, r, http.StatusOK, newSectionResponse(section))
+	}
+}
+
+// SectionRosterHandler lists the students enrolled in a section and its
+// waitlist, in the order seats will be given out.
+func SectionRosterHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := sectionID(w, r)
+		if !ok {
+			return
+		}
+
+		section, err := store.GetSection(r.Context(), id)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		roster, err := store.ListSectionRoster(r.Context(), id)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		resp := RosterResponse{
+			Section:  newSectionResponse(section),
+			Enrolled: []*RosterEntryResponse{},
+			Waitlist: []*RosterEntryResponse{},
+		}
+		for _, entry := range roster {
+			e := &RosterEntryResponse{
+				EnrollmentID:   entry.ID,
+				StudentID:      entry.StudentID,
+				FirstName:      entry.FirstName,
+				LastName:       entry.LastName,
+				Email:          entry.Email,
+				RegistrationNo: entry.RegistrationNo,
+				Status:         string(entry.Status),
+				Position:       entry.Position,
+				EnrolledAt:     entry.CreatedAt.Format("2006-01-02 15:04:05"),
+			}
+			if entry.Status == storage.EnrollmentWaitlisted {
+				resp.Waitlist = append(resp.Waitlist, e)
+			} else {
+				resp.Enrolled = append(resp.Enrolled, e)
+			}
+		}
+
+		response.Write(w, r, http.StatusOK, resp)
+	}
+}
@@ -5,44 +5,77 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/go-playground/validator/v10"
+	"github.com/smartcraze/student-api/internal/auth"
 	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/internal/validation"
 	"github.com/smartcraze/student-api/utils/response"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type CreateStudent struct {
-	Id             int64     `json:"id,omitempty"`
-	FirstName      string    `json:"first_name" validate:"required"`
-	LastName       string    `json:"last_name"  validate:"required"`
-	RegistrationNo int       `json:"reg_no" validate:"required"`
-	PhoneNumber    int64     `json:"phone_number" validate:"required"`
-	Email          string    `json:"email" validate:"required,email"`
-	Password       string    `json:"password,omitempty" validate:"required"`
-	CreatedAt      time.Time `json:"created_at,omitempty"`
+	Id             int64         `json:"id,omitempty"`
+	FirstName      string        `json:"first_name" validate:"required,max=100"`
+	LastName       string        `json:"last_name"  validate:"required,max=100"`
+	RegistrationNo int           `json:"reg_no" validate:"required,gt=0"`
+	PhoneNumber    string        `json:"phone_number" validate:"required"`
+	Email          string        `json:"email" validate:"required,email,max=255"`
+	Password       string        `json:"password,omitempty" validate:"required"`
+	CreatedAt      time.Time     `json:"created_at,omitempty"`
+	DateOfBirth    string        `json:"date_of_birth,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Gender         string        `json:"gender,omitempty" validate:"max=50"`
+	Address        AddressFields `json:"address"`
+	// Status and AdmissionDate can only be set by admins and staff, students
+	// start out as applicants
+	Status        string           `json:"status,omitempty" validate:"omitempty,oneof=applicant active suspended graduated withdrawn"`
+	AdmissionDate string           `json:"admission_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Guardians     []GuardianFields `json:"guardians,omitempty" validate:"max=10,dive"`
 }
 
-func CreateStudentHandler(store storage.Storage) http.HandlerFunc {
+// fields points the student rules at the editable fields of the request.
+func (req *CreateStudent) fields() validation.StudentFields {
+	return validation.StudentFields{
+		FirstName:      &req.FirstName,
+		LastName:       &req.LastName,
+		RegistrationNo: &req.RegistrationNo,
+		PhoneNumber:    &req.PhoneNumber,
+		Email:          &req.Email,
+		DateOfBirth:    &req.DateOfBirth,
+		Gender:         &req.Gender,
+		Country:        &req.Address.Country,
+		Guardians:      guardianRules(req.Guardians),
+	}
+}
+
+func CreateStudentHandler(store storage.Storage, passwords *auth.Passwords, rules *validation.StudentRules) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req CreateStudent
 
 		err := json.NewDecoder(r.Body).Decode(&req)
 		if err != nil {
-			response.Writejson(w, http.StatusBadRequest, response.GeneralError(err))
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Normalize and validate request
+		if errs := validateStudent(r.Context(), rules, &req, req.fields()); len(errs) > 0 {
+			response.ValidationFailed(w, r, errs)
+			return
+		}
+
+		if (req.Status != "" || req.AdmissionDate != "") && !isStaff(r.Context()) {
+			response.Error(w, r, http.StatusForbidden, errStaffOnly)
 			return
 		}
 
-		// Validate request
-		validate := validator.New()
-		if err := validate.Struct(req); err != nil {
-			response.Writejson(w, http.StatusBadRequest, response.ValidationError(err.(validator.ValidationErrors)))
+		// Enforce the password policy
+		if err := passwords.Validate(req.Password); err != nil {
+			writePasswordError(w, r, "password", err)
 			return
 		}
 
 		// Hash password
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		hashedPassword, err := passwords.Hash(req.Password)
 		if err != nil {
-			response.Writejson(w, http.StatusInternalServerError, response.GeneralError(err))
+			response.Error(w, r, http.StatusInternalServerError, err.Error())
 			return
 		}
 
@@ -53,12 +86,15 @@ func CreateStudentHandler(store storage.Storage) http.HandlerFunc {
 			RegistrationNo: req.RegistrationNo,
 			PhoneNumber:    req.PhoneNumber,
 			Email:          req.Email,
-			Password:       string(hashedPassword),
+			Password:       hashedPassword,
+			Profile:        newProfile(req.DateOfBirth, req.Gender, req.Address, req.AdmissionDate),
+			Status:         storage.StudentStatus(req.Status),
+			Guardians:      storageGuardians(req.Guardians),
 		}
 
 		err = store.CreateStudent(r.Context(), student)
 		if err != nil {
-			response.Writejson(w, http.StatusInternalServerError, response.GeneralError(err))
+			writeStorageError(w, r, err)
 			return
 		}
 
@@ -66,7 +102,8 @@ func CreateStudentHandler(store storage.Storage) http.HandlerFunc {
 		req.Id = student.ID
 		req.CreatedAt = student.CreatedAt
 		req.Password = "" // Don't send password back
+		req.Status = string(student.Status)
 
-		response.Writejson(w, http.StatusCreated, req)
+		response.Write(w, r, http.StatusCreated, req)
 	}
 }
@@ -0,0 +1,103 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/utils/response"
+)
+
+type TermRequest struct {
+	// Code is stored in upper case, e.g. 2026-FA
+	Code     string `json:"code" validate:"required,max=20"`
+	Name     string `json:"name" validate:"required,max=100"`
+	StartsOn string `json:"starts_on" validate:"required,datetime=2006-01-02"`
+	EndsOn   string `json:"ends_on" validate:"required,datetime=2006-01-02"`
+}
+
+type TermResponse struct {
+	ID        int64  `json:"id"`
+	Code      string `json:"code"`
+	Name      string `json:"name"`
+	StartsOn  string `json:"starts_on"`
+	EndsOn    string `json:"ends_on"`
+	CreatedAt string `json:"created_at"`
+}
+
+type ListTermsResponse struct {
+	Terms []*TermResponse `json:"terms"`
+}
+
+func (l ListTermsResponse) Items() any {
+	return l.Terms
+}
+
+func newTermResponse(term *storage.Term) *TermResponse {
+	return &TermResponse{
+		ID:        term.ID,
+		Code:      term.Code,
+		Name:      term.Name,
+		StartsOn:  formatDate(&term.StartsOn),
+		EndsOn:    formatDate(&term.EndsOn),
+		CreatedAt: term.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func CreateTermHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req TermRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		req.Code = strings.ToUpper(strings.TrimSpace(req.Code))
+		req.Name = strings.TrimSpace(req.Name)
+
+		if err := validate.Struct(req); err != nil {
+			response.ValidationFailed(w, r, response.FieldErrors(r.Context(), err))
+			return
+		}
+
+		// the dates passed the datetime rule, so they parse
+		term := &storage.Term{
+			Code:     req.Code,
+			Name:     req.Name,
+			StartsOn: *parseDate(req.StartsOn),
+			EndsOn:   *parseDate(req.EndsOn),
+		}
+		if !term.EndsOn.After(term.StartsOn) {
+			response.ValidationFailed(w, r, []response.FieldError{
+				response.NewFieldError(r.Context(), "ends_on", "after", "starts_on", "ends_on must be after starts_on"),
+			})
+			return
+		}
+
+		if err := store.CreateTerm(r.Context(), term); err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.Write(w, r, http.StatusCreated, newTermResponse(term))
+	}
+}
+
+// ListTermsHandler lists every term, the latest to start first.
+func ListTermsHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		terms, err := store.ListTerms(r.Context())
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		resp := ListTermsResponse{Terms: make([]*TermResponse, len(terms))}
+		for i, term := range terms {
+			resp.Terms[i] = newTermResponse(term)
+		}
+
+		response.Write(w, r, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,136 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/smartcraze/student-api/internal/lifecycle"
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/utils/response"
+)
+
+type TransitionRequest struct {
+	To     string `json:"to" validate:"required,oneof=applicant active suspended graduated withdrawn"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type TransitionResponse struct {
+	ID        int64  `json:"id"`
+	StudentID int64  `json:"student_id"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Reason    string `json:"reason"`
+	ActorID   *int64 `json:"actor_id"`
+	Actor     string `json:"actor"`
+	RequestID string `json:"request_id,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+type TransitionHistoryResponse struct {
+	Status string `json:"status"`
+	// Next lists the statuses the student can move to from Status
+	Next        []string              `json:"next"`
+	Transitions []*TransitionResponse `json:"transitions"`
+}
+
+func (h TransitionHistoryResponse) Items() any {
+	return h.Transitions
+}
+
+func newTransitionResponse(t *storage.StatusTransition) *TransitionResponse {
+	return &TransitionResponse{
+		ID:        t.ID,
+		StudentID: t.StudentID,
+		From:      string(t.From),
+		To:        string(t.To),
+		Reason:    t.Reason,
+		ActorID:   t.ActorID,
+		Actor:     t.Actor,
+		RequestID: t.RequestID,
+		CreatedAt: t.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// TransitionStudentHandler changes the status of a student, the only way to
+// do so once they exist.
+func TransitionStudentHandler(machine *lifecycle.Machine) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get student ID from URL path parameter
+		idStr := r.PathValue("id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, "invalid student ID")
+			return
+		}
+
+		var req TransitionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if err := validate.Struct(req); err != nil {
+			response.ValidationFailed(w, r, response.FieldErrors(r.Context(), err))
+			return
+		}
+
+		_, transition, err := machine.Transition(r.Context(), id, storage.StudentStatus(req.To), req.Reason)
+		var guardErr *lifecycle.GuardError
+		if errors.As(err, &guardErr) {
+			status := http.StatusConflict
+			if guardErr.Guard == lifecycle.GuardAdmin {
+				status = http.StatusForbidden
+			}
+			response.Error(w, r, status, guardErr.Message)
+			return
+		}
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.Write(w, r, http.StatusCreated, newTransitionResponse(transition))
+	}
+}
+
+// TransitionHistoryHandler lists the status changes of a student, oldest
+// first, along with the statuses they can move to next.
+func TransitionHistoryHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get student ID from URL path parameter
+		idStr := r.PathValue("id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, "invalid student ID")
+			return
+		}
+
+		student, err := store.GetStudentByID(r.Context(), id)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		transitions, err := store.ListStatusTransitions(r.Context(), id)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		resp := TransitionHistoryResponse{
+			Status:      string(student.Status),
+			Next:        []string{},
+			Transitions: make([]*TransitionResponse, len(transitions)),
+		}
+		for _, status := range student.Status.NextStatuses() {
+			resp.Next = append(resp.Next, string(status))
+		}
+		for i, transition := range transitions {
+			resp.Transitions[i] = newTransitionResponse(transition)
+		}
+
+		response.Write(w, r, http.StatusOK, resp)
+	}
+}
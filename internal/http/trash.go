@@ -0,0 +1,57 @@
+package httphandler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/utils/response"
+)
+
+func RestoreStudentHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get student ID from URL path parameter
+		idStr := r.PathValue("id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, "invalid student ID")
+			return
+		}
+
+		// Fails with a conflict if a live student took the email or registration number meanwhile
+		student, err := store.RestoreStudent(r.Context(), id)
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		// Return restored student
+		w.Header().Set("ETag", studentETag(student))
+		resp := newStudentResponse(student)
+
+		response.Write(w, r, http.StatusOK, resp)
+	}
+}
+
+func PurgeStudentHandler(store storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get student ID from URL path parameter
+		idStr := r.PathValue("id")
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			response.Error(w, r, http.StatusBadRequest, "invalid student ID")
+			return
+		}
+
+		// Only students already in the trash can be purged
+		if err := store.PurgeStudent(r.Context(), id); err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
+
+		response.Write(w, r, http.StatusOK, response.Response{
+			Status: response.StatusOK,
+			Error:  "student purged permanently",
+		})
+	}
+}
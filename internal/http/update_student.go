@@ -2,40 +2,56 @@ package httphandler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/smartcraze/student-api/internal/storage"
+	"github.com/smartcraze/student-api/internal/validation"
 	"github.com/smartcraze/student-api/utils/response"
 )
 
 type UpdateStudentRequest struct {
-	FirstName      string `json:"first_name" validate:"required"`
-	LastName       string `json:"last_name" validate:"required"`
-	RegistrationNo int    `json:"reg_no" validate:"required"`
-	PhoneNumber    int64  `json:"phone_number" validate:"required"`
-	Email          string `json:"email" validate:"required,email"`
+	FirstName      string        `json:"first_name" validate:"required,max=100"`
+	LastName       string        `json:"last_name" validate:"required,max=100"`
+	RegistrationNo int           `json:"reg_no" validate:"required,gt=0"`
+	PhoneNumber    string        `json:"phone_number" validate:"required"`
+	Email          string        `json:"email" validate:"required,email,max=255"`
+	DateOfBirth    string        `json:"date_of_birth,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Gender         string        `json:"gender,omitempty" validate:"max=50"`
+	Address        AddressFields `json:"address"`
+	// AdmissionDate is kept when left out, only admins and staff can change it.
+	// The status has its own endpoint, see TransitionStudentHandler.
+	AdmissionDate string           `json:"admission_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Guardians     []GuardianFields `json:"guardians" validate:"max=10,dive"`
 }
 
-func UpdateStudentHandler(store storage.Storage) http.HandlerFunc {
+func (req *UpdateStudentRequest) fields() validation.StudentFields {
+	return validation.StudentFields{
+		FirstName:      &req.FirstName,
+		LastName:       &req.LastName,
+		RegistrationNo: &req.RegistrationNo,
+		PhoneNumber:    &req.PhoneNumber,
+		Email:          &req.Email,
+		DateOfBirth:    &req.DateOfBirth,
+		Gender:         &req.Gender,
+		Country:        &req.Address.Country,
+		Guardians:      guardianRules(req.Guardians),
+	}
+}
+
+func UpdateStudentHandler(store storage.Storage, rules *validation.StudentRules) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Get student ID from URL path parameter
 		idStr := r.PathValue("id")
 		if idStr == "" {
-			response.Writejson(w, http.StatusBadRequest, response.Response{
-				Status: response.StatusError,
-				Error:  "student ID is required",
-			})
+			response.Error(w, r, http.StatusBadRequest, "student ID is required")
 			return
 		}
 
 		id, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
-			response.Writejson(w, http.StatusBadRequest, response.Response{
-				Status: response.StatusError,
-				Error:  "invalid student ID",
-			})
+			response.Error(w, r, http.StatusBadRequest, "invalid student ID")
 			return
 		}
 
@@ -43,53 +59,71 @@ func UpdateStudentHandler(store storage.Storage) http.HandlerFunc {
 		var req UpdateStudentRequest
 		err = json.NewDecoder(r.Body).Decode(&req)
 		if err != nil {
-			response.Writejson(w, http.StatusBadRequest, response.GeneralError(err))
+			response.Error(w, r, http.StatusBadRequest, err.Error())
 			return
 		}
 
-		// Validate request
-		validate := validator.New()
-		if err := validate.Struct(req); err != nil {
-			response.Writejson(w, http.StatusBadRequest, response.ValidationError(err.(validator.ValidationErrors)))
+		// Normalize and validate request
+		if errs := validateStudent(r.Context(), rules, &req, req.fields()); len(errs) > 0 {
+			response.ValidationFailed(w, r, errs)
 			return
 		}
 
-		// Check if student exists
-		existingStudent, err := store.GetStudentByID(r.Context(), id)
+		student, err := store.GetStudentByID(r.Context(), id)
 		if err != nil {
-			response.Writejson(w, http.StatusNotFound, response.Response{
-				Status: response.StatusError,
-				Error:  "student not found",
-			})
+			writeStorageError(w, r, err)
 			return
 		}
 
-		// Update student data
-		existingStudent.FirstName = req.FirstName
-		existingStudent.LastName = req.LastName
-		existingStudent.RegistrationNo = req.RegistrationNo
-		existingStudent.PhoneNumber = req.PhoneNumber
-		existingStudent.Email = req.Email
+		version, err := ifMatchVersion(r, func() (*storage.Student, error) {
+			return student, nil
+		})
+		if err != nil {
+			writeStorageError(w, r, err)
+			return
+		}
 
-		// Save updated student
-		err = store.UpdateStudent(r.Context(), existingStudent)
+		// The admission date is kept when left out
+		if req.AdmissionDate == "" {
+			req.AdmissionDate = formatDate(student.AdmissionDate)
+		}
+		if req.AdmissionDate != formatDate(student.AdmissionDate) && !isStaff(r.Context()) {
+			response.Error(w, r, http.StatusForbidden, errStaffOnly)
+			return
+		}
+
+		// Replace every editable field. The kept fields were read at this
+		// version, so the write is conditional on it.
+		profile := newProfile(req.DateOfBirth, req.Gender, req.Address, req.AdmissionDate)
+		guardians := storageGuardians(req.Guardians)
+		update := storage.StudentUpdate{
+			FirstName:      &req.FirstName,
+			LastName:       &req.LastName,
+			RegistrationNo: &req.RegistrationNo,
+			PhoneNumber:    &req.PhoneNumber,
+			Email:          &req.Email,
+			Profile:        &profile,
+			Guardians:      &guardians,
+			Version:        version,
+		}
+		if version == 0 {
+			update.Version = student.Version
+		}
+
+		student, err = store.UpdateStudent(r.Context(), id, update)
+		if errors.Is(err, storage.ErrVersionMismatch) && version == 0 {
+			// the client didn't ask for a precondition, so it is just a lost race
+			err = storage.ErrConflict
+		}
 		if err != nil {
-			response.Writejson(w, http.StatusInternalServerError, response.GeneralError(err))
+			writeStorageError(w, r, err)
 			return
 		}
 
 		// Return updated student
-		resp := StudentResponse{
-			ID:             existingStudent.ID,
-			FirstName:      existingStudent.FirstName,
-			LastName:       existingStudent.LastName,
-			RegistrationNo: existingStudent.RegistrationNo,
-			PhoneNumber:    existingStudent.PhoneNumber,
-			Email:          existingStudent.Email,
-			CreatedAt:      existingStudent.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt:      existingStudent.UpdatedAt.Format("2006-01-02 15:04:05"),
-		}
+		w.Header().Set("ETag", studentETag(student))
+		resp := newStudentResponse(student)
 
-		response.Writejson(w, http.StatusOK, resp)
+		response.Write(w, r, http.StatusOK, resp)
 	}
 }
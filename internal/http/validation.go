@@ -0,0 +1,103 @@
+package httphandler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/smartcraze/student-api/internal/auth"
+	"github.com/smartcraze/student-api/internal/i18n"
+	"github.com/smartcraze/student-api/internal/validation"
+	"github.com/smartcraze/student-api/utils/response"
+)
+
+// validate checks request bodies. It names fields by their JSON name, so
+// clients can match errors to what they sent.
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+	return v
+}
+
+// passwordFieldErrors describes each password rule err says is broken as
+// an error on field, e.g. a password_min_length error.
+func passwordFieldErrors(ctx context.Context, field string, err error) ([]response.FieldError, bool) {
+	var policyErr *auth.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return nil, false
+	}
+
+	errs := make([]response.FieldError, 0, len(policyErr.Violations))
+	for _, violation := range policyErr.Violations {
+		errs = append(errs, response.NewFieldError(ctx, field, "password_"+violation.Rule, violation.Param, field+" "+violation.Message))
+	}
+	return errs, true
+}
+
+// writePasswordError reports each broken password rule as an error on field.
+func writePasswordError(w http.ResponseWriter, r *http.Request, field string, err error) {
+	errs, ok := passwordFieldErrors(r.Context(), field, err)
+	if !ok {
+		response.Error(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	response.ValidationFailed(w, r, errs)
+}
+
+// validateStudent normalizes the student fields f points at and checks req
+// against its validate tags and the configured student rules. With fields
+// only those struct fields are checked, as a PATCH only checks what changed.
+func validateStudent(ctx context.Context, rules *validation.StudentRules, req any, f validation.StudentFields, fields ...string) []response.FieldError {
+	var errs []response.FieldError
+
+	normalizeErr := rules.Normalize(f)
+
+	var err error
+	if len(fields) > 0 {
+		err = validate.StructPartial(req, fields...)
+	} else {
+		err = validate.Struct(req)
+	}
+	if err != nil {
+		errs = response.FieldErrors(ctx, err)
+	}
+
+	var rulesErr *validation.Error
+	if errors.As(normalizeErr, &rulesErr) {
+		for _, violation := range rulesErr.Violations {
+			errs = append(errs, response.NewFieldError(ctx, violation.Field, violation.Rule, violation.Param, violation.Field+" "+violation.Message))
+		}
+	}
+	return errs
+}
+
+// RegisterTranslations registers the catalog's validation messages with the
+// validator of the request bodies.
+func RegisterTranslations(catalog *i18n.Catalog) error {
+	return catalog.RegisterValidation(validate)
+}
+
+// Localize writes the problems of next in the catalog language the client
+// prefers in Accept-Language, English by default.
+func Localize(catalog *i18n.Catalog, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t := catalog.Match(r.Header.Get("Accept-Language"))
+		w.Header().Add("Vary", "Accept-Language")
+		w.Header().Set("Content-Language", t.Locale())
+		next.ServeHTTP(w, r.WithContext(response.WithTranslator(r.Context(), t)))
+	})
+}
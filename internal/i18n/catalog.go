@@ -0,0 +1,229 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/de"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/locales/pt"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	de_translations "github.com/go-playground/validator/v10/translations/de"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+	fr_translations "github.com/go-playground/validator/v10/translations/fr"
+	pt_translations "github.com/go-playground/validator/v10/translations/pt"
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLocale is used when the client accepts none of the catalog's languages.
+const DefaultLocale = "en"
+
+// builtin are the languages the validator ships messages for. They cover
+// the rules a catalog file has no message for. Any other language can still
+// be added with a catalog file, it just relies on the file alone.
+var builtin = map[string]struct {
+	locale     func() locales.Translator
+	validation func(*validator.Validate, ut.Translator) error
+}{
+	"en": {en.New, en_translations.RegisterDefaultTranslations},
+	"es": {es.New, es_translations.RegisterDefaultTranslations},
+	"fr": {fr.New, fr_translations.RegisterDefaultTranslations},
+	"de": {de.New, de_translations.RegisterDefaultTranslations},
+	"pt": {pt.New, pt_translations.RegisterDefaultTranslations},
+}
+
+// catalogFile is the layout of a <locale>.yaml file in the locales directory.
+type catalogFile struct {
+	// Validation messages by rule, {0} is the field and {1} the parameter.
+	// A rule.string or rule.items message is preferred for strings and lists.
+	Validation map[string]string `yaml:"validation"`
+	// Messages translates the English messages of the API
+	Messages map[string]string `yaml:"messages"`
+}
+
+// Catalog holds the translations of every language in the locales directory.
+type Catalog struct {
+	tags        []language.Tag
+	translators []ut.Translator
+	validation  []map[string]string
+	matcher     language.Matcher
+}
+
+// Load reads every <locale>.yaml file of dir, e.g. es.yaml or pt-BR.yaml.
+// English is always available, with the API's own messages if there is no
+// en.yaml.
+func Load(dir string) (*Catalog, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	uni := ut.New(en.New())
+	c := &Catalog{}
+	add := func(tag language.Tag, f *catalogFile) error {
+		name := tag.String()
+		base, _ := tag.Base()
+
+		locale := en.New()
+		if b, ok := builtin[base.String()]; ok {
+			locale = b.locale()
+		}
+		// the locale only supplies plural rules here, so a close relative will do
+		if locale.Locale() != name {
+			locale = renamedLocale{locale, name}
+		}
+		if err := uni.AddTranslator(locale, true); err != nil {
+			return err
+		}
+		trans, _ := uni.GetTranslator(name)
+
+		for msg, text := range f.Messages {
+			if err := trans.Add(msg, text, true); err != nil {
+				return fmt.Errorf("message %q: %w", msg, err)
+			}
+		}
+		for rule, text := range f.Validation {
+			if err := trans.Add(validationKey(rule), text, true); err != nil {
+				return fmt.Errorf("validation message %q: %w", rule, err)
+			}
+		}
+
+		c.tags = append(c.tags, tag)
+		c.translators = append(c.translators, trans)
+		c.validation = append(c.validation, f.Validation)
+		return nil
+	}
+
+	files := make(map[language.Tag]*catalogFile)
+	var tags []language.Tag
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".yaml")
+		tag, err := language.Parse(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid locale file name %s: %w", path, err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read locale file: %w", err)
+		}
+		f := &catalogFile{}
+		if err := yaml.Unmarshal(data, f); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		files[tag] = f
+		tags = append(tags, tag)
+	}
+
+	// The default goes first, the matcher falls back to it
+	fallback := language.MustParse(DefaultLocale)
+	f, ok := files[fallback]
+	if !ok {
+		f = &catalogFile{}
+	}
+	if err := add(fallback, f); err != nil {
+		return nil, fmt.Errorf("locale %s: %w", fallback, err)
+	}
+	for _, tag := range tags {
+		if tag == fallback {
+			continue
+		}
+		if err := add(tag, files[tag]); err != nil {
+			return nil, fmt.Errorf("locale %s: %w", tag, err)
+		}
+	}
+
+	c.matcher = language.NewMatcher(c.tags)
+	return c, nil
+}
+
+// Locales lists the languages of the catalog, the default first.
+func (c *Catalog) Locales() []string {
+	names := make([]string, len(c.tags))
+	for i, tag := range c.tags {
+		names[i] = tag.String()
+	}
+	return names
+}
+
+// RegisterValidation registers the catalog's validation messages with v,
+// on top of the validator's own messages for the languages it ships.
+func (c *Catalog) RegisterValidation(v *validator.Validate) error {
+	for i, trans := range c.translators {
+		base, _ := c.tags[i].Base()
+		if b, ok := builtin[base.String()]; ok {
+			if err := b.validation(v, trans); err != nil {
+				return fmt.Errorf("locale %s: %w", c.tags[i], err)
+			}
+		}
+
+		for rule := range c.validation[i] {
+			tag, _, _ := strings.Cut(rule, ".")
+			err := v.RegisterTranslation(tag, trans, func(ut.Translator) error { return nil }, translateFieldError)
+			if err != nil {
+				return fmt.Errorf("locale %s: %w", c.tags[i], err)
+			}
+		}
+	}
+	return nil
+}
+
+// Match picks the catalog language that best fits an Accept-Language header.
+func (c *Catalog) Match(acceptLanguage string) *Translator {
+	tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	_, i, _ := c.matcher.Match(tags...)
+	return &Translator{locale: c.tags[i].String(), trans: c.translators[i]}
+}
+
+// translateFieldError is the validator's translation function for the
+// catalog's rules.
+func translateFieldError(trans ut.Translator, fe validator.FieldError) string {
+	if msg, ok := translateRule(trans, fe.Tag(), fe.Kind(), fe.Field(), fe.Param()); ok {
+		return msg
+	}
+	return fe.Error()
+}
+
+func translateRule(trans ut.Translator, tag string, kind reflect.Kind, field, param string) (string, bool) {
+	keys := []string{validationKey(tag)}
+	switch kind {
+	case reflect.String:
+		keys = []string{validationKey(tag + ".string"), keys[0]}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		keys = []string{validationKey(tag + ".items"), keys[0]}
+	}
+
+	for _, key := range keys {
+		if msg, err := trans.T(key, field, param); err == nil {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// validationKey keeps the catalog's rules apart from the keys the
+// validator's own translations use.
+func validationKey(rule string) string {
+	return "validation:" + rule
+}
+
+// renamedLocale serves a language without a locales package under its own name.
+type renamedLocale struct {
+	locales.Translator
+	name string
+}
+
+func (l renamedLocale) Locale() string {
+	return l.name
+}
@@ -0,0 +1,177 @@
+package i18n
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type signup struct {
+	Name string   `validate:"required"`
+	Tags []string `validate:"min=2"`
+}
+
+// newValidator registers the catalog with a validator, which can only be
+// done once per catalog.
+func newValidator(t *testing.T, c *Catalog) *validator.Validate {
+	t.Helper()
+
+	v := validator.New()
+	if err := c.RegisterValidation(v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// fieldErrors validates an empty signup and translates its errors, a rule
+// without a message gets none.
+func fieldErrors(t *testing.T, v *validator.Validate, trans *Translator) map[string]string {
+	t.Helper()
+
+	var errs validator.ValidationErrors
+	if !errors.As(v.Struct(signup{}), &errs) {
+		t.Fatal("an empty signup passed validation")
+	}
+	msgs := map[string]string{}
+	for _, fe := range errs {
+		if msg, ok := trans.FieldError(fe); ok {
+			msgs[fe.Field()] = msg
+		}
+	}
+	return msgs
+}
+
+// The catalog the server ships, one message of each built-in language.
+func TestShippedCatalog(t *testing.T) {
+	c, err := Load(filepath.Join("..", "..", "config", "locales"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Locales(); len(got) != 2 || got[0] != "en" || got[1] != "es" {
+		t.Fatalf("locales are %v, want en first and es", got)
+	}
+	v := newValidator(t, c)
+
+	tests := []struct {
+		acceptLanguage string
+		locale         string
+		title          string
+		rule           string
+		name           string
+		tags           string
+	}{
+		{"en-US", "en", "Not Found", "reg_no is required", "Name is required", "Tags must have at least 2 items"},
+		{"es", "es", "No encontrado", "reg_no es obligatorio", "Name es obligatorio", "Tags debe tener al menos 2 elementos"},
+		{"es-MX, en;q=0.5", "es", "No encontrado", "reg_no es obligatorio", "Name es obligatorio", "Tags debe tener al menos 2 elementos"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			trans := c.Match(tt.acceptLanguage)
+			if trans.Locale() != tt.locale {
+				t.Errorf("matched %s, want %s", trans.Locale(), tt.locale)
+			}
+			if got := trans.Message("Not Found"); got != tt.title {
+				t.Errorf("title is %q, want %q", got, tt.title)
+			}
+			if got, ok := trans.Rule("required", "reg_no", ""); !ok || got != tt.rule {
+				t.Errorf("rule is %q, want %q", got, tt.rule)
+			}
+
+			msgs := fieldErrors(t, v, trans)
+			if msgs["Name"] != tt.name || msgs["Tags"] != tt.tags {
+				t.Errorf("field errors are %q", msgs)
+			}
+		})
+	}
+}
+
+func writeCatalog(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestCatalogFallback(t *testing.T) {
+	dir := writeCatalog(t, map[string]string{
+		"en.yaml": "validation:\n  required: \"{0} is required\"\nmessages: {}\n",
+		// no validation messages and only one of the API's
+		"es.yaml": "messages:\n  Not Found: \"No encontrado\"\n",
+	})
+	c, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a language the catalog doesn't have gets the default
+	for _, acceptLanguage := range []string{"", "fr-CA", "*", "not a language"} {
+		if got := c.Match(acceptLanguage).Locale(); got != DefaultLocale {
+			t.Errorf("Match(%q) is %s, want %s", acceptLanguage, got, DefaultLocale)
+		}
+	}
+
+	es := c.Match("es")
+	if got := es.Message("Not Found"); got != "No encontrado" {
+		t.Errorf("translated message is %q", got)
+	}
+	// a message missing from the file is sent in English
+	if got := es.Message("Conflict"); got != "Conflict" {
+		t.Errorf("untranslated message is %q, want it in English", got)
+	}
+	// as is a rule outside the validator, which has no message at all
+	if got, ok := es.Rule("required", "reg_no", ""); ok {
+		t.Errorf("got %q for a rule the file doesn't have", got)
+	}
+
+	// the validator's own Spanish messages cover the rules the file misses
+	msgs := fieldErrors(t, newValidator(t, c), es)
+	if msgs["Name"] == "" || msgs["Name"] == "Name is required" {
+		t.Errorf("required message is %q, want the validator's Spanish one", msgs["Name"])
+	}
+}
+
+// A language the validator has no messages for relies on its file alone,
+// and English is there without an en.yaml.
+func TestCatalogWithoutBuiltinLanguage(t *testing.T) {
+	dir := writeCatalog(t, map[string]string{
+		"nl.yaml": "validation:\n  required: \"{0} is verplicht\"\nmessages:\n  Not Found: \"Niet gevonden\"\n",
+	})
+	c, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Locales(); len(got) != 2 || got[0] != "en" || got[1] != "nl" {
+		t.Fatalf("locales are %v, want en and nl", got)
+	}
+
+	nl := c.Match("nl-BE")
+	if nl.Locale() != "nl" || nl.Message("Not Found") != "Niet gevonden" {
+		t.Errorf("got %s with %q", nl.Locale(), nl.Message("Not Found"))
+	}
+	if msgs := fieldErrors(t, newValidator(t, c), nl); msgs["Name"] != "Name is verplicht" || msgs["Tags"] != "" {
+		t.Errorf("field errors are %q, want only the required one", msgs)
+	}
+	if got := c.Match("en").Message("Not Found"); got != "Not Found" {
+		t.Errorf("English message is %q", got)
+	}
+}
+
+func TestLoadRejectsBadFiles(t *testing.T) {
+	for name, files := range map[string]map[string]string{
+		"file name": {"not_a_locale!.yaml": "messages: {}\n"},
+		"yaml":      {"es.yaml": "messages: [\n"},
+	} {
+		if _, err := Load(writeCatalog(t, files)); err == nil {
+			t.Errorf("loaded a catalog with a bad %s", name)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package i18n
+
+import (
+	"reflect"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// Translator translates into one language of a Catalog.
+type Translator struct {
+	locale string
+	trans  ut.Translator
+}
+
+// Locale is the BCP 47 tag of the language, e.g. es.
+func (t *Translator) Locale() string {
+	return t.locale
+}
+
+// Message translates one of the API's English messages, it is returned as
+// is when the catalog has no translation.
+func (t *Translator) Message(msg string) string {
+	if text, err := t.trans.T(msg); err == nil {
+		return text
+	}
+	return msg
+}
+
+// Rule explains a rule checked outside the validator, ok is false when the
+// catalog has no message for it.
+func (t *Translator) Rule(tag, field, param string) (string, bool) {
+	return translateRule(t.trans, tag, reflect.Invalid, field, param)
+}
+
+// FieldError explains an error of a validator the catalog is registered
+// with, ok is false when there is no message for its rule.
+func (t *Translator) FieldError(fe validator.FieldError) (string, bool) {
+	msg := fe.Translate(t.trans)
+	return msg, msg != fe.Error()
+}
@@ -0,0 +1,235 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/smartcraze/student-api/internal/auth"
+	"github.com/smartcraze/student-api/internal/config"
+	"github.com/smartcraze/student-api/internal/notify"
+	"github.com/smartcraze/student-api/internal/storage"
+)
+
+// Guards that can be configured for a transition
+const (
+	// GuardAdmin only lets admins make the transition, staff can make the others
+	GuardAdmin            = "admin"
+	GuardAdmissionDate    = "admission_date"
+	GuardDateOfBirth      = "date_of_birth"
+	GuardEmergencyContact = "emergency_contact"
+)
+
+// Hooks that can be configured for a transition
+const (
+	// HookNotify tells the student about their new status
+	HookNotify = "notify"
+	// HookEndSessions logs the student out everywhere
+	HookEndSessions = "end_sessions"
+)
+
+// anyTransition is the key of the guards and hooks of every transition
+const anyTransition = "*"
+
+// Change is a status transition. Guards see the student before it, hooks
+// after it along with the recorded Transition.
+type Change struct {
+	Student    *storage.Student
+	From       storage.StudentStatus
+	To         storage.StudentStatus
+	Reason     string
+	Transition *storage.StatusTransition
+}
+
+// Guard checks whether a transition may be made and returns a *GuardError
+// when it may not.
+type Guard func(ctx context.Context, change *Change) error
+
+// Hook runs after a transition is committed. A failing hook is logged, the
+// transition stands.
+type Hook func(ctx context.Context, change *Change) error
+
+// GuardError is the guard that stopped a transition.
+type GuardError struct {
+	Guard   string
+	Message string
+}
+
+func (e *GuardError) Error() string {
+	return e.Message
+}
+
+type namedGuard struct {
+	name  string
+	guard Guard
+}
+
+type namedHook struct {
+	name string
+	hook Hook
+}
+
+// Machine makes the status transitions of students, with the guards and
+// hooks configured for each of them. The transitions themselves are fixed,
+// see storage.StudentStatus.NextStatuses.
+type Machine struct {
+	store  storage.Storage
+	guards map[string][]namedGuard
+	hooks  map[string][]namedHook
+}
+
+func New(cfg config.Lifecycle, store storage.Storage, notifier notify.Notifier) (*Machine, error) {
+	m := &Machine{
+		store:  store,
+		guards: make(map[string][]namedGuard),
+		hooks:  make(map[string][]namedHook),
+	}
+
+	guards := map[string]Guard{
+		GuardAdmin:            requireAdmin,
+		GuardAdmissionDate:    requireAdmissionDate,
+		GuardDateOfBirth:      requireDateOfBirth,
+		GuardEmergencyContact: m.requireEmergencyContact,
+	}
+	hooks := map[string]Hook{
+		HookNotify:      notifyStudent(notifier),
+		HookEndSessions: m.endSessions,
+	}
+
+	for key, names := range cfg.Guards {
+		if err := checkTransitionKey(key); err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			guard, ok := guards[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown guard %q for transition %q", name, key)
+			}
+			m.guards[key] = append(m.guards[key], namedGuard{name, guard})
+		}
+	}
+
+	for key, names := range cfg.Hooks {
+		if err := checkTransitionKey(key); err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			hook, ok := hooks[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown hook %q for transition %q", name, key)
+			}
+			m.hooks[key] = append(m.hooks[key], namedHook{name, hook})
+		}
+	}
+
+	return m, nil
+}
+
+func transitionKey(from, to storage.StudentStatus) string {
+	return string(from) + "->" + string(to)
+}
+
+// checkTransitionKey makes sure a configured key names a transition students
+// can actually make, so a typo doesn't silently drop a guard.
+func checkTransitionKey(key string) error {
+	if key == anyTransition {
+		return nil
+	}
+	from, to, ok := strings.Cut(key, "->")
+	if !ok || !storage.StudentStatus(from).CanTransitionTo(storage.StudentStatus(to)) {
+		return fmt.Errorf("unknown transition %q, must be \"*\" or like \"applicant->active\"", key)
+	}
+	return nil
+}
+
+// Transition moves a student to status to. The guards of the transition run
+// first, then the status is changed if the student is still in the status
+// the guards saw, and finally the hooks run.
+func (m *Machine) Transition(ctx context.Context, id int64, to storage.StudentStatus, reason string) (*storage.Student, *storage.StatusTransition, error) {
+	student, err := m.store.GetStudentByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	from := student.Status
+	if !from.CanTransitionTo(to) {
+		return nil, nil, storage.ErrInvalidTransition
+	}
+
+	key := transitionKey(from, to)
+	change := &Change{Student: student, From: from, To: to, Reason: reason}
+	for _, g := range slices.Concat(m.guards[anyTransition], m.guards[key]) {
+		if err := g.guard(ctx, change); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	student, transition, err := m.store.TransitionStudent(ctx, id, from, to, reason)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	change.Student = student
+	change.Transition = transition
+	for _, h := range slices.Concat(m.hooks[anyTransition], m.hooks[key]) {
+		if err := h.hook(ctx, change); err != nil {
+			slog.Error("Transition hook failed",
+				slog.String("hook", h.name),
+				slog.String("transition", key),
+				slog.Int64("student_id", id),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	return student, transition, nil
+}
+
+func requireAdmin(ctx context.Context, change *Change) error {
+	if identity := auth.IdentityFrom(ctx); identity == nil || identity.Role != storage.RoleAdmin {
+		return &GuardError{Guard: GuardAdmin, Message: "only admins can make this status change"}
+	}
+	return nil
+}
+
+func requireAdmissionDate(ctx context.Context, change *Change) error {
+	if change.Student.AdmissionDate == nil {
+		return &GuardError{Guard: GuardAdmissionDate, Message: "the student has no admission date"}
+	}
+	return nil
+}
+
+func requireDateOfBirth(ctx context.Context, change *Change) error {
+	if change.Student.DateOfBirth == nil {
+		return &GuardError{Guard: GuardDateOfBirth, Message: "the student has no date of birth"}
+	}
+	return nil
+}
+
+func (m *Machine) requireEmergencyContact(ctx context.Context, change *Change) error {
+	guardians, err := m.store.ListGuardians(ctx, change.Student.ID)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(guardians, func(g storage.Guardian) bool { return g.EmergencyContact }) {
+		return &GuardError{Guard: GuardEmergencyContact, Message: "the student has no emergency contact"}
+	}
+	return nil
+}
+
+func notifyStudent(notifier notify.Notifier) Hook {
+	return func(ctx context.Context, change *Change) error {
+		return notifier.Notify(ctx, notify.Message{
+			To:      change.Student.Email,
+			Subject: "Your enrollment status has changed",
+			Body: fmt.Sprintf("Hello %s,\n\nYour enrollment status changed from %s to %s.\n\nReason: %s",
+				change.Student.FirstName, change.From, change.To, change.Reason),
+		})
+	}
+}
+
+func (m *Machine) endSessions(ctx context.Context, change *Change) error {
+	return m.store.RevokeStudentRefreshTokens(ctx, change.Student.ID)
+}
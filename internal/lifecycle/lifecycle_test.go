@@ -0,0 +1,153 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/smartcraze/student-api/internal/auth"
+	"github.com/smartcraze/student-api/internal/config"
+	"github.com/smartcraze/student-api/internal/notify"
+	"github.com/smartcraze/student-api/internal/storage"
+)
+
+// outbox keeps the messages sent by the notify hook.
+type outbox struct {
+	messages []notify.Message
+}
+
+func (o *outbox) Notify(ctx context.Context, msg notify.Message) error {
+	o.messages = append(o.messages, msg)
+	return nil
+}
+
+func newStudent(t *testing.T, store storage.Storage) *storage.Student {
+	t.Helper()
+
+	student := &storage.Student{
+		FirstName:      "Ada",
+		LastName:       "Lovelace",
+		RegistrationNo: 42,
+		PhoneNumber:    "+15550100",
+		Email:          "ada@example.com",
+		Password:       "hash",
+	}
+	if err := store.CreateStudent(context.Background(), student); err != nil {
+		t.Fatal(err)
+	}
+	return student
+}
+
+func TestTransitionRunsGuardsAndHooks(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	sent := &outbox{}
+	machine, err := New(config.Lifecycle{
+		Guards: map[string][]string{"applicant->active": {GuardAdmissionDate}},
+		Hooks:  map[string][]string{"*": {HookNotify}},
+	}, store, sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	student := newStudent(t, store)
+
+	_, _, err = machine.Transition(ctx, student.ID, storage.StatusActive, "admitted")
+	var guardErr *GuardError
+	if !errors.As(err, &guardErr) || guardErr.Guard != GuardAdmissionDate {
+		t.Fatalf("got %v, want the admission_date guard to fail", err)
+	}
+	if len(sent.messages) != 0 {
+		t.Errorf("hooks ran for a guarded transition, sent %+v", sent.messages)
+	}
+
+	admitted := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := store.UpdateStudent(ctx, student.ID, storage.StudentUpdate{Profile: &storage.Profile{AdmissionDate: &admitted}}); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, transition, err := machine.Transition(ctx, student.ID, storage.StatusActive, "admitted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status != storage.StatusActive || transition.From != storage.StatusApplicant || transition.Reason != "admitted" {
+		t.Errorf("got status %q and transition %+v", updated.Status, transition)
+	}
+	if len(sent.messages) != 1 || sent.messages[0].To != student.Email {
+		t.Errorf("notify hook sent %+v, want one message to %s", sent.messages, student.Email)
+	}
+
+	// graduation is final
+	if _, _, err := machine.Transition(ctx, student.ID, storage.StatusGraduated, "finished"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := machine.Transition(ctx, student.ID, storage.StatusActive, "back"); !errors.Is(err, storage.ErrInvalidTransition) {
+		t.Errorf("got %v leaving graduated, want ErrInvalidTransition", err)
+	}
+
+	history, err := store.ListStatusTransitions(ctx, student.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[1].To != storage.StatusGraduated {
+		t.Errorf("history is %+v, want applicant->active->graduated", history)
+	}
+}
+
+func TestAdminGuard(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	machine, err := New(config.Lifecycle{
+		Guards: map[string][]string{"applicant->withdrawn": {GuardAdmin}},
+	}, store, &outbox{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	student := newStudent(t, store)
+	staff := auth.WithIdentity(context.Background(), &auth.Identity{StudentID: 100, Role: storage.RoleStaff})
+	admin := auth.WithIdentity(context.Background(), &auth.Identity{StudentID: 101, Role: storage.RoleAdmin})
+
+	_, _, err = machine.Transition(staff, student.ID, storage.StatusWithdrawn, "left")
+	var guardErr *GuardError
+	if !errors.As(err, &guardErr) || guardErr.Guard != GuardAdmin {
+		t.Fatalf("staff got %v, want the admin guard to fail", err)
+	}
+	if _, _, err := machine.Transition(admin, student.ID, storage.StatusWithdrawn, "left"); err != nil {
+		t.Errorf("admin got %v", err)
+	}
+}
+
+func TestStaleStatusIsRejected(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	student := newStudent(t, store)
+	ctx := context.Background()
+
+	if _, _, err := store.TransitionStudent(ctx, student.ID, storage.StatusApplicant, storage.StatusWithdrawn, "left"); err != nil {
+		t.Fatal(err)
+	}
+	// a second writer that read the student before the first one committed
+	if _, _, err := store.TransitionStudent(ctx, student.ID, storage.StatusApplicant, storage.StatusActive, "admitted"); !errors.Is(err, storage.ErrStatusMismatch) {
+		t.Errorf("got %v, want ErrStatusMismatch", err)
+	}
+}
+
+func TestNewRejectsBadConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Lifecycle
+	}{
+		{"unknown guard", config.Lifecycle{Guards: map[string][]string{"*": {"paid_fees"}}}},
+		{"unknown hook", config.Lifecycle{Hooks: map[string][]string{"*": {"email_parents"}}}},
+		{"impossible transition", config.Lifecycle{Guards: map[string][]string{"graduated->active": {GuardAdmin}}}},
+		{"malformed key", config.Lifecycle{Hooks: map[string][]string{"applicant to active": {HookNotify}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.cfg, storage.NewMemoryStorage(), &outbox{}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package notify
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogNotifier writes messages to the server log, for local development.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, msg Message) error {
+	slog.Info("Notification",
+		slog.String("to", msg.To),
+		slog.String("subject", msg.Subject),
+		slog.String("body", msg.Body),
+	)
+	return nil
+}
@@ -0,0 +1,31 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+)
+
+// Message is something to deliver to a student, e.g. a password reset link.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers messages to students. Implementations for real channels
+// (email, SMS) can be added next to the local ones here.
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// New returns the notifier for the configured driver: "log" or "file".
+func New(driver, filePath string) (Notifier, error) {
+	switch driver {
+	case "", "log":
+		return LogNotifier{}, nil
+	case "file":
+		return NewFileNotifier(filePath)
+	default:
+		return nil, fmt.Errorf("unknown notifier driver: %s", driver)
+	}
+}
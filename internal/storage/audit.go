@@ -0,0 +1,303 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+	AuditPurge   AuditAction = "purge"
+	// AuditTransition is a status change, see TransitionStudent
+	AuditTransition AuditAction = "transition"
+)
+
+func (a AuditAction) Valid() bool {
+	switch a {
+	case AuditCreate, AuditUpdate, AuditDelete, AuditRestore, AuditPurge, AuditTransition:
+		return true
+	}
+	return false
+}
+
+// FieldChange is the value of a column before and after a change, nil when
+// the student didn't exist before or doesn't exist after.
+type FieldChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditEntry is one append-only record of a change to a student.
+type AuditEntry struct {
+	ID int64 `db:"id"`
+	// ActorID is nil for changes not made by a logged in student
+	ActorID   *int64                 `db:"actor_id"`
+	Actor     string                 `db:"actor"`
+	Action    AuditAction            `db:"action"`
+	StudentID int64                  `db:"student_id"`
+	Changes   map[string]FieldChange `db:"changes"`
+	RequestID string                 `db:"request_id"`
+	CreatedAt time.Time              `db:"created_at"`
+}
+
+// AuditFilter narrows down the audit log. Zero values don't filter.
+type AuditFilter struct {
+	StudentID *int64
+	ActorID   *int64
+	Action    AuditAction
+	RequestID string
+	From      *time.Time
+	To        *time.Time
+}
+
+// Actor is who changes are attributed to in the audit log.
+type Actor struct {
+	// ID of the acting student, 0 for anonymous callers and the system itself
+	ID   int64
+	Name string
+}
+
+var SystemActor = Actor{Name: "system"}
+
+type actorKey struct{}
+
+type requestIDKey struct{}
+
+// WithActor attributes the changes made with ctx to actor.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor set with WithActor, SystemActor if there is none.
+func ActorFrom(ctx context.Context) Actor {
+	if actor, ok := ctx.Value(actorKey{}).(Actor); ok {
+		return actor
+	}
+	return SystemActor
+}
+
+// WithRequestID tags the changes made with ctx with the id of the request that made them.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestIDFrom(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// redacted stands in for the password, the audit log only shows that it changed.
+const redacted = "[redacted]"
+
+// auditedFields returns the columns recorded in the audit log.
+func auditedFields(student *Student) map[string]any {
+	if student == nil {
+		return nil
+	}
+
+	fields := map[string]any{
+		"first_name":      student.FirstName,
+		"last_name":       student.LastName,
+		"registration_no": student.RegistrationNo,
+		"phone_number":    student.PhoneNumber,
+		"email":           student.Email,
+		"role":            string(student.Role),
+		"deleted_at":      nil,
+		"date_of_birth":   auditDate(student.DateOfBirth),
+		"gender":          student.Gender,
+		"address":         auditAddress(student.Address),
+		"status":          string(student.Status),
+		"admission_date":  auditDate(student.AdmissionDate),
+	}
+	if student.DeletedAt != nil {
+		fields["deleted_at"] = student.DeletedAt.UTC().Format(time.RFC3339Nano)
+	}
+	// only known when the guardians were written, see Student.Guardians
+	if student.Guardians != nil {
+		fields["guardians"] = guardianSummary(student.Guardians)
+	}
+	if student.Password != "" {
+		fields["password"] = redacted
+	}
+	return fields
+}
+
+// auditDate formats a date column, nil when it is empty.
+func auditDate(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.DateOnly)
+}
+
+// auditAddress writes the address on one line, the parts separated by commas.
+func auditAddress(a Address) string {
+	var parts []string
+	for _, part := range []string{a.Line1, a.Line2, a.City, a.Region, a.PostalCode, a.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// diffStudents returns the audited columns that differ between before and after.
+func diffStudents(before, after *Student) map[string]FieldChange {
+	b, a := auditedFields(before), auditedFields(after)
+
+	changes := make(map[string]FieldChange)
+	for field, value := range b {
+		if a[field] != value {
+			changes[field] = FieldChange{Before: value, After: a[field]}
+		}
+	}
+	for field, value := range a {
+		if _, ok := b[field]; !ok && value != nil {
+			changes[field] = FieldChange{Before: nil, After: value}
+		}
+	}
+
+	// both sides are redacted, so compare the hashes
+	if before != nil && after != nil && before.Password != after.Password {
+		changes["password"] = FieldChange{Before: redacted, After: redacted}
+	}
+
+	return changes
+}
+
+// newAuditEntry describes a change from before to after, made by the actor in ctx.
+func newAuditEntry(ctx context.Context, action AuditAction, before, after *Student, now time.Time) *AuditEntry {
+	actor := ActorFrom(ctx)
+	entry := &AuditEntry{
+		Actor:     actor.Name,
+		Action:    action,
+		Changes:   diffStudents(before, after),
+		RequestID: RequestIDFrom(ctx),
+		CreatedAt: now,
+	}
+	if actor.ID != 0 {
+		entry.ActorID = &actor.ID
+	}
+	if after != nil {
+		entry.StudentID = after.ID
+	} else if before != nil {
+		entry.StudentID = before.ID
+	}
+	return entry
+}
+
+// insertAudit writes entry in the transaction that made the change.
+func insertAudit(ctx context.Context, tx *sql.Tx, bind func(n int) string, entry *AuditEntry) error {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit changes: %w", err)
+	}
+
+	query := fmt.Sprintf(
+		`INSERT INTO audit_log (actor_id, actor, action, student_id, changes, request_id, created_at) VALUES (%s, %s, %s, %s, %s, %s, %s)`,
+		bind(1), bind(2), bind(3), bind(4), bind(5), bind(6), bind(7),
+	)
+	_, err = tx.ExecContext(ctx, query, entry.ActorID, entry.Actor, entry.Action, entry.StudentID, string(changes), entry.RequestID, entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+const auditColumns = "id, actor_id, actor, action, student_id, changes, request_id, created_at"
+
+func scanAuditEntry(row interface{ Scan(dest ...any) error }) (*AuditEntry, error) {
+	var entry AuditEntry
+	var changes []byte
+	err := row.Scan(
+		&entry.ID,
+		&entry.ActorID,
+		&entry.Actor,
+		&entry.Action,
+		&entry.StudentID,
+		&changes,
+		&entry.RequestID,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// keep numbers as they were written instead of turning them into floats
+	decoder := json.NewDecoder(bytes.NewReader(changes))
+	decoder.UseNumber()
+	if err := decoder.Decode(&entry.Changes); err != nil {
+		return nil, fmt.Errorf("failed to decode audit changes: %w", err)
+	}
+
+	return &entry, nil
+}
+
+func (q *queryBuilder) addAuditFilter(f AuditFilter) {
+	if f.StudentID != nil {
+		q.where = append(q.where, "student_id = "+q.arg(*f.StudentID))
+	}
+	if f.ActorID != nil {
+		q.where = append(q.where, "actor_id = "+q.arg(*f.ActorID))
+	}
+	if f.Action != "" {
+		q.where = append(q.where, "action = "+q.arg(f.Action))
+	}
+	if f.RequestID != "" {
+		q.where = append(q.where, "request_id = "+q.arg(f.RequestID))
+	}
+	if f.From != nil {
+		q.where = append(q.where, "created_at >= "+q.arg(*f.From))
+	}
+	if f.To != nil {
+		q.where = append(q.where, "created_at <= "+q.arg(*f.To))
+	}
+}
+
+// buildAuditQuery lists the audit log newest first.
+func buildAuditQuery(filter AuditFilter, limit, offset int, bind func(n int) string) (query string, args []any) {
+	q := &queryBuilder{bind: bind}
+	q.addAuditFilter(filter)
+
+	var b strings.Builder
+	b.WriteString("SELECT " + auditColumns + " FROM audit_log")
+	b.WriteString(q.whereClause())
+	fmt.Fprintf(&b, " ORDER BY id DESC LIMIT %s OFFSET %s", q.arg(limit), q.arg(offset))
+
+	return b.String(), q.args
+}
+
+func buildAuditCountQuery(filter AuditFilter, bind func(n int) string) (query string, args []any) {
+	q := &queryBuilder{bind: bind}
+	q.addAuditFilter(filter)
+
+	return "SELECT COUNT(*) FROM audit_log" + q.whereClause(), q.args
+}
+
+// withTx runs fn in a transaction and commits it if fn succeeds.
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
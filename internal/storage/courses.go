@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Course is an entry of the course catalogue.
+type Course struct {
+	ID          int64  `db:"id"`
+	Code        string `db:"code"`
+	Title       string `db:"title"`
+	Description string `db:"description"`
+	Credits     int    `db:"credits"`
+	Department  string `db:"department"`
+	// Capacity is the most students a section of the course can take
+	Capacity int `db:"capacity"`
+	// Active courses are the ones currently offered
+	Active    bool      `db:"active"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Fields the course list can be sorted by besides SortByID and
+// SortByCreatedAt, ties are broken by id.
+const (
+	SortByCode       SortField = "code"
+	SortByTitle      SortField = "title"
+	SortByDepartment SortField = "department"
+	SortByCredits    SortField = "credits"
+)
+
+// ValidForCourses reports whether the course list can be sorted by f.
+func (f SortField) ValidForCourses() bool {
+	switch f {
+	case SortByID, SortByCode, SortByTitle, SortByDepartment, SortByCredits, SortByCreatedAt:
+		return true
+	}
+	return false
+}
+
+// SortValue returns the value of the field the list is sorted by, for building cursors.
+func (c *Course) SortValue(field SortField) any {
+	switch field {
+	case SortByCode:
+		return c.Code
+	case SortByTitle:
+		return c.Title
+	case SortByDepartment:
+		return c.Department
+	case SortByCredits:
+		return int64(c.Credits)
+	case SortByCreatedAt:
+		return c.CreatedAt
+	default:
+		return c.ID
+	}
+}
+
+// CourseFilter narrows down the course list. Zero values don't filter.
+type CourseFilter struct {
+	// Department matches the whole department name, case-insensitive
+	Department string
+	Active     *bool
+	// Query matches anywhere in the code or title, case-insensitive
+	Query string
+}
+
+type CourseListOptions struct {
+	Filter CourseFilter
+	// SortBy defaults to code
+	SortBy   SortField
+	SortDesc bool
+	// Limit, Offset, After and Before work like they do in ListOptions
+	Limit  int
+	Offset int
+	After  *Cursor
+	Before *Cursor
+}
+
+const courseColumns = "id, code, title, description, credits, department, capacity, active, created_at, updated_at"
+
+// scanCourse reads a row selected with courseColumns.
+func scanCourse(row interface{ Scan(dest ...any) error }) (*Course, error) {
+	var c Course
+	err := row.Scan(&c.ID, &c.Code, &c.Title, &c.Description, &c.Credits, &c.Department, &c.Capacity, &c.Active, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (q *queryBuilder) addCourseFilter(f CourseFilter) {
+	if f.Department != "" {
+		q.where = append(q.where, "LOWER(department) = "+q.arg(strings.ToLower(f.Department)))
+	}
+	if f.Active != nil {
+		q.where = append(q.where, "active = "+q.arg(*f.Active))
+	}
+	if f.Query != "" {
+		p := q.arg("%" + strings.ToLower(escapeLike(f.Query)) + "%")
+		q.where = append(q.where, fmt.Sprintf(`(LOWER(code) LIKE %s ESCAPE '\' OR LOWER(title) LIKE %s ESCAPE '\')`, p, p))
+	}
+}
+
+func insertCourse(ctx context.Context, db *sql.DB, d sqlDialect, course *Course) error {
+	now := time.Now().UTC()
+	query := fmt.Sprintf(
+		`INSERT INTO courses (code, title, description, credits, department, capacity, active, created_at, updated_at) VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s) RETURNING id`,
+		d.bind(1), d.bind(2), d.bind(3), d.bind(4), d.bind(5), d.bind(6), d.bind(7), d.bind(8), d.bind(9),
+	)
+	err := db.QueryRowContext(ctx, query,
+		course.Code, course.Title, course.Description, course.Credits, course.Department, course.Capacity, course.Active, now, now,
+	).Scan(&course.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create course: %w", d.fromDriver(err))
+	}
+
+	course.CreatedAt = now
+	course.UpdatedAt = now
+	return nil
+}
+
+func getCourse(ctx context.Context, db *sql.DB, d sqlDialect, id int64) (*Course, error) {
+	course, err := scanCourse(db.QueryRowContext(ctx, `SELECT `+courseColumns+` FROM courses WHERE id = `+d.bind(1), id))
+	if err == sql.ErrNoRows {
+		return nil, errCourseNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get course: %w", err)
+	}
+	return course, nil
+}
+
+func updateCourse(ctx context.Context, db *sql.DB, d sqlDialect, course *Course) error {
+	query := fmt.Sprintf(
+		`UPDATE courses SET code = %s, title = %s, description = %s, credits = %s, department = %s, capacity = %s, active = %s, updated_at = %s WHERE id = %s RETURNING `+courseColumns,
+		d.bind(1), d.bind(2), d.bind(3), d.bind(4), d.bind(5), d.bind(6), d.bind(7), d.bind(8), d.bind(9),
+	)
+	updated, err := scanCourse(db.QueryRowContext(ctx, query,
+		course.Code, course.Title, course.Description, course.Credits, course.Department, course.Capacity, course.Active, time.Now().UTC(), course.ID,
+	))
+	if err == sql.ErrNoRows {
+		return errCourseNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update course: %w", d.fromDriver(err))
+	}
+
+	*course = *updated
+	return nil
+}
+
+func deleteCourse(ctx context.Context, db *sql.DB, d sqlDialect, id int64) error {
+	return withTx(ctx, db, func(tx *sql.Tx) error {
+		// the foreign key would stop it too, but not with an error that says why
+		taught, err := exists(ctx, tx, `SELECT 1 FROM course_sections WHERE course_id = `+d.bind(1), id)
+		if err != nil {
+			return fmt.Errorf("failed to get sections: %w", err)
+		}
+		if taught {
+			return ErrCourseHasSections
+		}
+		required, err := exists(ctx, tx, `SELECT 1 FROM course_prerequisites WHERE prerequisite_id = `+d.bind(1), id)
+		if err != nil {
+			return fmt.Errorf("failed to get prerequisites: %w", err)
+		}
+		if required {
+			return ErrCourseIsPrerequisite
+		}
+
+		result, err := tx.ExecContext(ctx, `DELETE FROM courses WHERE id = `+d.bind(1), id)
+		if err != nil {
+			return fmt.Errorf("failed to delete course: %w", d.fromDriver(err))
+		}
+
+		n, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to delete course: %w", err)
+		}
+		if n == 0 {
+			return errCourseNotFound
+		}
+		return nil
+	})
+}
+
+func listCourses(ctx context.Context, db queryer, d sqlDialect, opts CourseListOptions) ([]*Course, error) {
+	q := &queryBuilder{bind: d.bind}
+	q.addCourseFilter(opts.Filter)
+
+	column := string(opts.SortBy)
+	if !opts.SortBy.ValidForCourses() {
+		column = string(SortByCode)
+	}
+
+	query, reversed := q.orderAndPage("SELECT "+courseColumns+" FROM courses", column, opts.SortDesc, opts.After, opts.Before, opts.Limit, opts.Offset)
+	rows, err := db.QueryContext(ctx, query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list courses: %w", err)
+	}
+	defer rows.Close()
+
+	var courses []*Course
+	for rows.Next() {
+		course, err := scanCourse(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan course: %w", err)
+		}
+		courses = append(courses, course)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	if reversed {
+		slices.Reverse(courses)
+	}
+
+	return courses, nil
+}
+
+func countCourses(ctx context.Context, db *sql.DB, d sqlDialect, filter CourseFilter) (int, error) {
+	q := &queryBuilder{bind: d.bind}
+	q.addCourseFilter(filter)
+
+	var total int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM courses"+q.whereClause(), q.args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to count courses: %w", err)
+	}
+	return total, nil
+}
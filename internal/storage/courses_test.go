@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCourseCRUD(t *testing.T) {
+	ctx := context.Background()
+
+	for name, store := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			course := &Course{Code: "CS101", Title: "Programming", Credits: 4, Department: "Computing", Capacity: 30, Active: true}
+			if err := store.CreateCourse(ctx, course); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := store.GetCourse(ctx, course.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Code != "CS101" || got.Capacity != 30 || !got.Active {
+				t.Errorf("got %+v, want %+v", got, course)
+			}
+
+			other := &Course{Code: "CS101", Title: "Again", Capacity: 10}
+			if err := store.CreateCourse(ctx, other); !errors.Is(err, ErrDuplicateCourseCode) {
+				t.Errorf("got %v creating a second CS101, want ErrDuplicateCourseCode", err)
+			}
+
+			got.Title = "Programming I"
+			got.Active = false
+			if err := store.UpdateCourse(ctx, got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Title != "Programming I" || got.Active || !got.CreatedAt.Equal(course.CreatedAt) {
+				t.Errorf("updated course is %+v", got)
+			}
+
+			if err := store.DeleteCourse(ctx, course.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := store.GetCourse(ctx, course.ID); !errors.Is(err, ErrNotFound) {
+				t.Errorf("got %v after the delete, want ErrNotFound", err)
+			}
+			if err := store.DeleteCourse(ctx, course.ID); !errors.Is(err, ErrNotFound) {
+				t.Errorf("got %v deleting twice, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestListCourses(t *testing.T) {
+	ctx := context.Background()
+	active := true
+
+	for name, store := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, c := range []*Course{
+				{Code: "MA201", Title: "Linear Algebra", Credits: 3, Department: "Mathematics", Capacity: 40, Active: true},
+				{Code: "CS101", Title: "Programming", Credits: 4, Department: "Computing", Capacity: 30, Active: true},
+				{Code: "CS102", Title: "Data Structures", Credits: 4, Department: "Computing", Capacity: 30},
+				{Code: "MA101", Title: "Calculus", Credits: 5, Department: "Mathematics", Capacity: 60, Active: true},
+			} {
+				if err := store.CreateCourse(ctx, c); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			codes := func(opts CourseListOptions) []string {
+				t.Helper()
+				courses, err := store.ListCourses(ctx, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var codes []string
+				for _, c := range courses {
+					codes = append(codes, c.Code)
+				}
+				return codes
+			}
+
+			if got, want := codes(CourseListOptions{}), []string{"CS101", "CS102", "MA101", "MA201"}; !slices.Equal(got, want) {
+				t.Errorf("default order is %v, want %v", got, want)
+			}
+			if got, want := codes(CourseListOptions{Filter: CourseFilter{Department: "computing", Active: &active}}), []string{"CS101"}; !slices.Equal(got, want) {
+				t.Errorf("active computing courses are %v, want %v", got, want)
+			}
+			if got, want := codes(CourseListOptions{Filter: CourseFilter{Query: "calc"}}), []string{"MA101"}; !slices.Equal(got, want) {
+				t.Errorf("query matched %v, want %v", got, want)
+			}
+			if got, want := codes(CourseListOptions{SortBy: SortByCredits, SortDesc: true, Limit: 2, Offset: 1}), []string{"CS102", "CS101"}; !slices.Equal(got, want) {
+				t.Errorf("second page by credits is %v, want %v", got, want)
+			}
+
+			// the tie between the two 4 credit courses is broken by id
+			after := &Cursor{Value: int64(4), ID: 2}
+			if got, want := codes(CourseListOptions{SortBy: SortByCredits, Limit: 10, After: after}), []string{"CS102", "MA101"}; !slices.Equal(got, want) {
+				t.Errorf("after CS101 by credits is %v, want %v", got, want)
+			}
+
+			total, err := store.CountCourses(ctx, CourseFilter{Department: "Mathematics"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != 2 {
+				t.Errorf("%d mathematics courses, want 2", total)
+			}
+		})
+	}
+}
@@ -23,186 +23,319 @@ func NewPostgresStorage(connStr string) (*PostgresStorage, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	storage := &PostgresStorage{db: db}
-
-	// Auto-create tables if they don't exist
-	if err := storage.createTables(); err != nil {
-		return nil, fmt.Errorf("failed to create tables: %w", err)
-	}
+	return &PostgresStorage{db: db}, nil
+}
 
-	return storage, nil
+func (s *PostgresStorage) Migrator() (*Migrator, error) {
+	return NewMigrator(s.db, "postgres")
 }
 
-func (s *PostgresStorage) createTables() error {
-	query := `
-	CREATE TABLE IF NOT EXISTS students (
-		id SERIAL PRIMARY KEY,
-		first_name VARCHAR(100) NOT NULL,
-		last_name VARCHAR(100) NOT NULL,
-		registration_no INTEGER UNIQUE NOT NULL,
-		phone_number BIGINT NOT NULL,
-		email VARCHAR(255) UNIQUE NOT NULL,
-		password VARCHAR(255) NOT NULL,
-		created_at TIMESTAMP NOT NULL DEFAULT NOW(),
-		updated_at TIMESTAMP NOT NULL DEFAULT NOW()
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_students_email ON students(email);
-	CREATE INDEX IF NOT EXISTS idx_students_registration_no ON students(registration_no);
-	`
+func (s *PostgresStorage) CreateStudent(ctx context.Context, student *Student) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		return s.insertStudent(ctx, tx, student, time.Now().UTC())
+	})
+}
 
-	_, err := s.db.Exec(query)
-	return err
+func (s *PostgresStorage) ImportStudents(ctx context.Context, students []*Student, atomic bool) ([]error, error) {
+	return importStudents(ctx, s.db, students, atomic, func(tx *sql.Tx, student *Student) error {
+		return s.insertStudent(ctx, tx, student, time.Now().UTC())
+	})
 }
 
-func (s *PostgresStorage) CreateStudent(ctx context.Context, student *Student) error {
+// insertStudent creates student in tx and records it in the audit log.
+func (s *PostgresStorage) insertStudent(ctx context.Context, tx *sql.Tx, student *Student, now time.Time) error {
+	if student.Role == "" {
+		student.Role = RoleStudent
+	}
+	if student.Status == "" {
+		student.Status = StatusApplicant
+	}
+
 	query := `
-		INSERT INTO students (first_name, last_name, registration_no, phone_number, email, password, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO students (first_name, last_name, registration_no, phone_number, email, password, role, created_at, updated_at, status, ` + profileColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 		RETURNING id
 	`
-	now := time.Now()
-	err := s.db.QueryRowContext(
-		ctx,
-		query,
+	args := []any{
 		student.FirstName,
 		student.LastName,
 		student.RegistrationNo,
 		student.PhoneNumber,
 		student.Email,
 		student.Password,
+		student.Role,
 		now,
 		now,
-	).Scan(&student.ID)
+		student.Status,
+	}
+	err := tx.QueryRowContext(ctx, query, append(args, profileArgs(student.Profile)...)...).Scan(&student.ID)
 
 	if err != nil {
-		return fmt.Errorf("failed to create student: %w", err)
+		return fmt.Errorf("failed to create student: %w", fromPostgres(err))
 	}
 
 	student.CreatedAt = now
 	student.UpdatedAt = now
-	return nil
+	student.Version = 1
+	if student.Guardians != nil {
+		if err := replaceGuardians(ctx, tx, postgresBind, student.ID, student.Guardians); err != nil {
+			return err
+		}
+	}
+	return insertAudit(ctx, tx, postgresBind, newAuditEntry(ctx, AuditCreate, nil, student, now))
 }
 
 func (s *PostgresStorage) GetStudentByID(ctx context.Context, id int64) (*Student, error) {
 	query := `
-		SELECT id, first_name, last_name, registration_no, phone_number, email, password, created_at, updated_at
+		SELECT ` + studentColumns + `
 		FROM students
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
-	var student Student
-	err := s.db.QueryRowContext(ctx, query, id).Scan(
-		&student.ID,
-		&student.FirstName,
-		&student.LastName,
-		&student.RegistrationNo,
-		&student.PhoneNumber,
-		&student.Email,
-		&student.Password,
-		&student.CreatedAt,
-		&student.UpdatedAt,
-	)
+	student, err := scanStudent(s.db.QueryRowContext(ctx, query, id))
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("student not found")
+		return nil, errStudentNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get student: %w", err)
 	}
 
-	return &student, nil
+	return student, nil
 }
 
 func (s *PostgresStorage) GetStudentByEmail(ctx context.Context, email string) (*Student, error) {
 	query := `
-		SELECT id, first_name, last_name, registration_no, phone_number, email, password, created_at, updated_at
+		SELECT ` + studentColumns + `
 		FROM students
-		WHERE email = $1
+		WHERE email = $1 AND deleted_at IS NULL
 	`
-	var student Student
-	err := s.db.QueryRowContext(ctx, query, email).Scan(
-		&student.ID,
-		&student.FirstName,
-		&student.LastName,
-		&student.RegistrationNo,
-		&student.PhoneNumber,
-		&student.Email,
-		&student.Password,
-		&student.CreatedAt,
-		&student.UpdatedAt,
-	)
+	student, err := scanStudent(s.db.QueryRowContext(ctx, query, email))
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("student not found")
+		return nil, errStudentNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get student: %w", err)
 	}
 
-	return &student, nil
+	return student, nil
 }
 
-func (s *PostgresStorage) UpdateStudent(ctx context.Context, student *Student) error {
-	query := `
-		UPDATE students
-		SET first_name = $1, last_name = $2, registration_no = $3, phone_number = $4, email = $5, updated_at = $6
-		WHERE id = $7
-	`
-	result, err := s.db.ExecContext(
-		ctx,
-		query,
-		student.FirstName,
-		student.LastName,
-		student.RegistrationNo,
-		student.PhoneNumber,
-		student.Email,
-		time.Now(),
-		student.ID,
-	)
+func (s *PostgresStorage) ListGuardians(ctx context.Context, studentID int64) ([]Guardian, error) {
+	return queryGuardians(ctx, s.db, postgresBind, studentID)
+}
 
-	if err != nil {
-		return fmt.Errorf("failed to update student: %w", err)
+// lockStudent reads a live student, or one in the trash if deleted is true,
+// and locks the row until tx ends.
+func (s *PostgresStorage) lockStudent(ctx context.Context, tx *sql.Tx, id int64, deleted bool) (*Student, error) {
+	query := `SELECT ` + studentColumns + ` FROM students WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	notFound := errStudentNotFound
+	if deleted {
+		query = `SELECT ` + studentColumns + ` FROM students WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE`
+		notFound = errDeletedStudentNotFound
 	}
 
-	rows, err := result.RowsAffected()
+	student, err := scanStudent(tx.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, notFound
+	}
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return nil, fmt.Errorf("failed to get student: %w", err)
 	}
 
-	if rows == 0 {
-		return fmt.Errorf("student not found")
+	return student, nil
+}
+
+// updateLocked runs an UPDATE ... RETURNING on a student locked with
+// lockStudent and records the change in the audit log.
+func (s *PostgresStorage) updateLocked(ctx context.Context, tx *sql.Tx, action AuditAction, before *Student, now time.Time, query string, args ...any) (*Student, error) {
+	student, err := scanStudent(tx.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		// the row is locked, so only the version check can exclude it
+		return nil, ErrVersionMismatch
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s student: %w", action, fromPostgres(err))
 	}
 
-	return nil
+	if err := insertAudit(ctx, tx, postgresBind, newAuditEntry(ctx, action, before, student, now)); err != nil {
+		return nil, err
+	}
+	return student, nil
 }
 
-func (s *PostgresStorage) DeleteStudent(ctx context.Context, id int64) error {
-	query := `DELETE FROM students WHERE id = $1`
-	result, err := s.db.ExecContext(ctx, query, id)
+func (s *PostgresStorage) UpdateStudent(ctx context.Context, id int64, update StudentUpdate) (*Student, error) {
+	var student *Student
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		before, err := s.lockStudent(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+		if update.Version != 0 && before.Version != update.Version {
+			return ErrVersionMismatch
+		}
+		if update.Empty() {
+			student = before
+			return nil
+		}
+
+		// the old guardians are only read when they are replaced, so the audit
+		// log only mentions them then
+		if update.Guardians != nil {
+			if before.Guardians, err = queryGuardians(ctx, tx, postgresBind, id); err != nil {
+				return err
+			}
+			if err := replaceGuardians(ctx, tx, postgresBind, id, *update.Guardians); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now().UTC()
+		query, args := buildUpdateQuery(id, update, now, postgresBind)
+		student, err = scanStudent(tx.QueryRowContext(ctx, query, args...))
+		if err == sql.ErrNoRows {
+			return ErrVersionMismatch
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update student: %w", fromPostgres(err))
+		}
+		if update.Guardians != nil {
+			student.Guardians = *update.Guardians
+		}
+		return insertAudit(ctx, tx, postgresBind, newAuditEntry(ctx, AuditUpdate, before, student, now))
+	})
 	if err != nil {
-		return fmt.Errorf("failed to delete student: %w", err)
+		return nil, err
 	}
 
-	rows, err := result.RowsAffected()
+	return student, nil
+}
+
+func (s *PostgresStorage) SetStudentRole(ctx context.Context, id int64, role Role) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		before, err := s.lockStudent(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		query := `UPDATE students SET role = $1, updated_at = $2, version = version + 1 WHERE id = $3 RETURNING ` + studentColumns
+		_, err = s.updateLocked(ctx, tx, AuditUpdate, before, now, query, role, now, id)
+		return err
+	})
+}
+
+func (s *PostgresStorage) UpdateStudentPassword(ctx context.Context, id int64, passwordHash string) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		before, err := s.lockStudent(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		query := `UPDATE students SET password = $1, updated_at = $2, version = version + 1 WHERE id = $3 RETURNING ` + studentColumns
+		_, err = s.updateLocked(ctx, tx, AuditUpdate, before, now, query, passwordHash, now, id)
+		return err
+	})
+}
+
+func (s *PostgresStorage) DeleteStudent(ctx context.Context, id int64, version int64) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		before, err := s.lockStudent(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		query := `UPDATE students SET deleted_at = $1, updated_at = $1, version = version + 1 WHERE id = $2`
+		args := []any{now, id}
+		if version != 0 {
+			query += ` AND version = $3`
+			args = append(args, version)
+		}
+		query += ` RETURNING ` + studentColumns
+
+		_, err = s.updateLocked(ctx, tx, AuditDelete, before, now, query, args...)
+		return err
+	})
+}
+
+func (s *PostgresStorage) RestoreStudent(ctx context.Context, id int64) (*Student, error) {
+	var student *Student
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		before, err := s.lockStudent(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		query := `UPDATE students SET deleted_at = NULL, updated_at = $1, version = version + 1 WHERE id = $2 RETURNING ` + studentColumns
+		student, err = s.updateLocked(ctx, tx, AuditRestore, before, now, query, now, id)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return nil, err
 	}
 
-	if rows == 0 {
-		return fmt.Errorf("student not found")
+	return student, nil
+}
+
+// purge permanently deletes the students in the trash matched by where and
+// records each of them in the audit log.
+func (s *PostgresStorage) purge(ctx context.Context, where string, args ...any) (int, error) {
+	purged := 0
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `DELETE FROM students WHERE deleted_at IS NOT NULL AND `+where+` RETURNING `+studentColumns, args...)
+		if err != nil {
+			return fmt.Errorf("failed to purge students: %w", fromPostgres(err))
+		}
+		defer rows.Close()
+
+		var deleted []*Student
+		for rows.Next() {
+			student, err := scanStudent(rows)
+			if err != nil {
+				return fmt.Errorf("failed to scan student: %w", err)
+			}
+			deleted = append(deleted, student)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating rows: %w", err)
+		}
+
+		now := time.Now().UTC()
+		for _, student := range deleted {
+			if err := insertAudit(ctx, tx, postgresBind, newAuditEntry(ctx, AuditPurge, student, nil, now)); err != nil {
+				return err
+			}
+		}
+
+		purged = len(deleted)
+		return nil
+	})
+
+	return purged, err
+}
+
+func (s *PostgresStorage) PurgeStudent(ctx context.Context, id int64) error {
+	purged, err := s.purge(ctx, `id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if purged == 0 {
+		return errDeletedStudentNotFound
 	}
 
 	return nil
 }
 
-func (s *PostgresStorage) ListStudents(ctx context.Context, limit, offset int) ([]*Student, error) {
-	query := `
-		SELECT id, first_name, last_name, registration_no, phone_number, email, password, created_at, updated_at
-		FROM students
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
-	`
-	rows, err := s.db.QueryContext(ctx, query, limit, offset)
+func (s *PostgresStorage) PurgeDeletedStudents(ctx context.Context, deletedBefore time.Time) (int, error) {
+	return s.purge(ctx, `deleted_at < $1`, deletedBefore.UTC())
+}
+
+func (s *PostgresStorage) ListStudents(ctx context.Context, opts ListOptions) ([]*Student, error) {
+	query, args, reversed := buildListQuery(opts, postgresBind)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list students: %w", err)
 	}
@@ -210,31 +343,84 @@ func (s *PostgresStorage) ListStudents(ctx context.Context, limit, offset int) (
 
 	var students []*Student
 	for rows.Next() {
-		var student Student
-		err := rows.Scan(
-			&student.ID,
-			&student.FirstName,
-			&student.LastName,
-			&student.RegistrationNo,
-			&student.PhoneNumber,
-			&student.Email,
-			&student.Password,
-			&student.CreatedAt,
-			&student.UpdatedAt,
-		)
+		student, err := scanStudent(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan student: %w", err)
 		}
-		students = append(students, &student)
+		students = append(students, student)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating rows: %w", err)
 	}
 
+	if reversed {
+		reverseStudents(students)
+	}
+
 	return students, nil
 }
 
+func (s *PostgresStorage) CountStudents(ctx context.Context, filter StudentFilter) (int, error) {
+	query, args := buildCountQuery(filter, postgresBind)
+
+	var total int
+	err := s.db.QueryRowContext(ctx, query, args...).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count students: %w", err)
+	}
+
+	return total, nil
+}
+
+// ExportStudents reads the students through a server-side cursor in a
+// read-only transaction, exportBatchSize rows at a time.
+func (s *PostgresStorage) ExportStudents(ctx context.Context, opts ListOptions, fn func(*Student) error) error {
+	opts = exportOptions(opts)
+	opts.Limit = 0
+	query, args, _ := buildListQuery(opts, postgresBind)
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DECLARE export_students NO SCROLL CURSOR FOR `+query, args...); err != nil {
+		return fmt.Errorf("failed to declare export cursor: %w", err)
+	}
+
+	fetch := fmt.Sprintf(`FETCH %d FROM export_students`, exportBatchSize)
+	for {
+		rows, err := tx.QueryContext(ctx, fetch)
+		if err != nil {
+			return fmt.Errorf("failed to fetch students: %w", err)
+		}
+
+		n := 0
+		for rows.Next() {
+			student, err := scanStudent(rows)
+			if err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan student: %w", err)
+			}
+			if err := fn(student); err != nil {
+				rows.Close()
+				return err
+			}
+			n++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating rows: %w", err)
+		}
+
+		if n < exportBatchSize {
+			return tx.Commit()
+		}
+	}
+}
+
 func (s *PostgresStorage) Close() error {
 	return s.db.Close()
 }
@@ -0,0 +1,27 @@
+package storage
+
+import "context"
+
+func (s *PostgresStorage) CreateCourse(ctx context.Context, course *Course) error {
+	return insertCourse(ctx, s.db, postgresDialect, course)
+}
+
+func (s *PostgresStorage) GetCourse(ctx context.Context, id int64) (*Course, error) {
+	return getCourse(ctx, s.db, postgresDialect, id)
+}
+
+func (s *PostgresStorage) UpdateCourse(ctx context.Context, course *Course) error {
+	return updateCourse(ctx, s.db, postgresDialect, course)
+}
+
+func (s *PostgresStorage) DeleteCourse(ctx context.Context, id int64) error {
+	return deleteCourse(ctx, s.db, postgresDialect, id)
+}
+
+func (s *PostgresStorage) ListCourses(ctx context.Context, opts CourseListOptions) ([]*Course, error) {
+	return listCourses(ctx, s.db, postgresDialect, opts)
+}
+
+func (s *PostgresStorage) CountCourses(ctx context.Context, filter CourseFilter) (int, error) {
+	return countCourses(ctx, s.db, postgresDialect, filter)
+}
@@ -0,0 +1,39 @@
+package storage
+
+import "context"
+
+func (s *PostgresStorage) CreateTerm(ctx context.Context, term *Term) error {
+	return insertTerm(ctx, s.db, postgresDialect, term)
+}
+
+func (s *PostgresStorage) ListTerms(ctx context.Context) ([]*Term, error) {
+	return queryTerms(ctx, s.db)
+}
+
+func (s *PostgresStorage) CreateSection(ctx context.Context, section *Section) error {
+	return insertSection(ctx, s.db, postgresDialect, section)
+}
+
+func (s *PostgresStorage) GetSection(ctx context.Context, id int64) (*Section, error) {
+	return getSection(ctx, s.db, postgresDialect, id)
+}
+
+func (s *PostgresStorage) ListSections(ctx context.Context, courseID int64) ([]*Section, error) {
+	return querySections(ctx, s.db, postgresDialect, courseID)
+}
+
+func (s *PostgresStorage) Enroll(ctx context.Context, studentID, sectionID int64, waitlist bool) (*Enrollment, []*Enrollment, error) {
+	return enroll(ctx, s.db, postgresDialect, studentID, sectionID, waitlist)
+}
+
+func (s *PostgresStorage) DropEnrollment(ctx context.Context, studentID, enrollmentID int64) (*Enrollment, []*Enrollment, error) {
+	return dropEnrollment(ctx, s.db, postgresDialect, studentID, enrollmentID)
+}
+
+func (s *PostgresStorage) ListStudentEnrollments(ctx context.Context, studentID int64) ([]*Enrollment, error) {
+	return queryStudentEnrollments(ctx, s.db, postgresDialect, studentID)
+}
+
+func (s *PostgresStorage) ListSectionRoster(ctx context.Context, sectionID int64) ([]*RosterEntry, error) {
+	return queryRoster(ctx, s.db, postgresDialect, sectionID)
+}
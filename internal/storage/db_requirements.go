@@ -0,0 +1,19 @@
+package storage
+
+import "context"
+
+func (s *PostgresStorage) SetPrerequisites(ctx context.Context, courseID int64, groups [][]Prerequisite) error {
+	return setPrerequisites(ctx, s.db, postgresDialect, courseID, groups)
+}
+
+func (s *PostgresStorage) ListPrerequisites(ctx context.Context, courseID int64) ([][]Prerequisite, error) {
+	return queryPrerequisites(ctx, s.db, postgresDialect, courseID)
+}
+
+func (s *PostgresStorage) SetSectionMeetings(ctx context.Context, sectionID int64, meetings []Meeting) error {
+	return setSectionMeetings(ctx, s.db, postgresDialect, sectionID, meetings)
+}
+
+func (s *PostgresStorage) GradeEnrollment(ctx context.Context, studentID, enrollmentID int64, grade Grade) (*Enrollment, error) {
+	return gradeEnrollment(ctx, s.db, postgresDialect, studentID, enrollmentID, grade)
+}
@@ -0,0 +1,494 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Term is a teaching period, sections of courses are offered in a term.
+// Dates are midnight UTC.
+type Term struct {
+	ID        int64     `db:"id"`
+	Code      string    `db:"code"`
+	Name      string    `db:"name"`
+	StartsOn  time.Time `db:"starts_on"`
+	EndsOn    time.Time `db:"ends_on"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Section is a course offered in a term, students enroll in sections.
+type Section struct {
+	ID       int64  `db:"id"`
+	CourseID int64  `db:"course_id"`
+	TermID   int64  `db:"term_id"`
+	Code     string `db:"code"`
+	// Capacity is the number of seats, it defaults to the capacity of the course
+	Capacity  int       `db:"capacity"`
+	CreatedAt time.Time `db:"created_at"`
+	// Meetings are the weekly classes of the section
+	Meetings []Meeting
+
+	// Read from the enrollments, course and term of the section, they are
+	// ignored by CreateSection
+	Enrolled    int
+	Waitlisted  int
+	CourseCode  string
+	CourseTitle string
+	TermCode    string
+}
+
+// Available is the number of free seats.
+func (s *Section) Available() int {
+	return max(s.Capacity-s.Enrolled, 0)
+}
+
+type EnrollmentStatus string
+
+const (
+	EnrollmentEnrolled   EnrollmentStatus = "enrolled"
+	EnrollmentWaitlisted EnrollmentStatus = "waitlisted"
+	EnrollmentDropped    EnrollmentStatus = "dropped"
+)
+
+// Enrollment is a seat, or a place on the waitlist, of a student in a section.
+type Enrollment struct {
+	ID        int64            `db:"id"`
+	StudentID int64            `db:"student_id"`
+	SectionID int64            `db:"section_id"`
+	Status    EnrollmentStatus `db:"status"`
+	// Position is the place on the waitlist counting from 1, 0 unless the
+	// enrollment is waitlisted
+	Position int
+	// Grade is empty until the student is graded
+	Grade     Grade     `db:"grade"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+	// Section is set by ListStudentEnrollments
+	Section *Section
+}
+
+// RosterEntry is an enrollment in a section along with its student.
+type RosterEntry struct {
+	Enrollment
+	FirstName      string
+	LastName       string
+	Email          string
+	RegistrationNo int
+}
+
+const termColumns = "id, code, name, starts_on, ends_on, created_at"
+
+// sectionFields selects a section as s, with its course c and term t joined
+// by sectionJoins, in the order of sectionDest.
+const sectionFields = `s.id, s.course_id, s.term_id, s.code, s.capacity, s.created_at,
+	(SELECT COUNT(*) FROM enrollments x WHERE x.section_id = s.id AND x.status = 'enrolled'),
+	(SELECT COUNT(*) FROM enrollments x WHERE x.section_id = s.id AND x.status = 'waitlisted'),
+	c.code, c.title, t.code`
+
+const sectionJoins = ` JOIN courses c ON c.id = s.course_id JOIN terms t ON t.id = s.term_id`
+
+// enrollmentFields selects an enrollment as e, in the order of
+// enrollmentDest. The waitlist position is counted from the waitlisted rows
+// before it.
+const enrollmentFields = `e.id, e.student_id, e.section_id, e.status,
+	CASE WHEN e.status = 'waitlisted' THEN (SELECT COUNT(*) FROM enrollments w WHERE w.section_id = e.section_id AND w.status = 'waitlisted' AND w.id <= e.id) ELSE 0 END,
+	e.grade, e.created_at, e.updated_at`
+
+func sectionDest(s *Section) []any {
+	return []any{&s.ID, &s.CourseID, &s.TermID, &s.Code, &s.Capacity, &s.CreatedAt, &s.Enrolled, &s.Waitlisted, &s.CourseCode, &s.CourseTitle, &s.TermCode}
+}
+
+func enrollmentDest(e *Enrollment) []any {
+	return []any{&e.ID, &e.StudentID, &e.SectionID, &e.Status, &e.Position, &e.Grade, &e.CreatedAt, &e.UpdatedAt}
+}
+
+func insertTerm(ctx context.Context, db *sql.DB, d sqlDialect, term *Term) error {
+	now := time.Now().UTC()
+	query := fmt.Sprintf(
+		`INSERT INTO terms (code, name, starts_on, ends_on, created_at) VALUES (%s, %s, %s, %s, %s) RETURNING id`,
+		d.bind(1), d.bind(2), d.bind(3), d.bind(4), d.bind(5),
+	)
+	err := db.QueryRowContext(ctx, query, term.Code, term.Name, term.StartsOn, term.EndsOn, now).Scan(&term.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create term: %w", d.fromDriver(err))
+	}
+
+	term.CreatedAt = now
+	return nil
+}
+
+// queryTerms lists the terms, latest start first.
+func queryTerms(ctx context.Context, db queryer) ([]*Term, error) {
+	rows, err := db.QueryContext(ctx, `SELECT `+termColumns+` FROM terms ORDER BY starts_on DESC, id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list terms: %w", err)
+	}
+	defer rows.Close()
+
+	terms := []*Term{}
+	for rows.Next() {
+		var t Term
+		if err := rows.Scan(&t.ID, &t.Code, &t.Name, &t.StartsOn, &t.EndsOn, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan term: %w", err)
+		}
+		terms = append(terms, &t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return terms, nil
+}
+
+// exists runs a SELECT 1 query and reports whether it found a row.
+func exists(ctx context.Context, tx *sql.Tx, query string, args ...any) (bool, error) {
+	var one int
+	err := tx.QueryRowContext(ctx, query, args...).Scan(&one)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func insertSection(ctx context.Context, db *sql.DB, d sqlDialect, section *Section) error {
+	return withTx(ctx, db, func(tx *sql.Tx) error {
+		// Report a missing course or term as such rather than as a foreign key violation
+		found, err := exists(ctx, tx, `SELECT 1 FROM courses WHERE id = `+d.bind(1), section.CourseID)
+		if err != nil {
+			return fmt.Errorf("failed to get course: %w", err)
+		}
+		if !found {
+			return errCourseNotFound
+		}
+		found, err = exists(ctx, tx, `SELECT 1 FROM terms WHERE id = `+d.bind(1), section.TermID)
+		if err != nil {
+			return fmt.Errorf("failed to get term: %w", err)
+		}
+		if !found {
+			return errTermNotFound
+		}
+
+		now := time.Now().UTC()
+		query := fmt.Sprintf(
+			`INSERT INTO course_sections (course_id, term_id, code, capacity, created_at) VALUES (%s, %s, %s, %s, %s) RETURNING id`,
+			d.bind(1), d.bind(2), d.bind(3), d.bind(4), d.bind(5),
+		)
+		err = tx.QueryRowContext(ctx, query, section.CourseID, section.TermID, section.Code, section.Capacity, now).Scan(&section.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create section: %w", d.fromDriver(err))
+		}
+
+		section.CreatedAt = now
+		return insertMeetings(ctx, tx, d, section.ID, section.Meetings)
+	})
+}
+
+func getSection(ctx context.Context, db *sql.DB, d sqlDialect, id int64) (*Section, error) {
+	var section Section
+	err := db.QueryRowContext(ctx, `SELECT `+sectionFields+` FROM course_sections s`+sectionJoins+` WHERE s.id = `+d.bind(1), id).Scan(sectionDest(&section)...)
+	if err == sql.ErrNoRows {
+		return nil, errSectionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get section: %w", err)
+	}
+	if err := loadMeetings(ctx, db, d, &section); err != nil {
+		return nil, err
+	}
+	return &section, nil
+}
+
+// querySections lists the sections of a course, latest term first.
+func querySections(ctx context.Context, db queryer, d sqlDialect, courseID int64) ([]*Section, error) {
+	rows, err := db.QueryContext(ctx, `SELECT `+sectionFields+` FROM course_sections s`+sectionJoins+` WHERE s.course_id = `+d.bind(1)+` ORDER BY t.starts_on DESC, s.code, s.id`, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sections: %w", err)
+	}
+	defer rows.Close()
+
+	sections := []*Section{}
+	for rows.Next() {
+		var section Section
+		if err := rows.Scan(sectionDest(&section)...); err != nil {
+			return nil, fmt.Errorf("failed to scan section: %w", err)
+		}
+		sections = append(sections, &section)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	if err := loadMeetings(ctx, db, d, sections...); err != nil {
+		return nil, err
+	}
+	return sections, nil
+}
+
+func getEnrollment(ctx context.Context, tx *sql.Tx, d sqlDialect, id int64) (*Enrollment, error) {
+	var e Enrollment
+	err := tx.QueryRowContext(ctx, `SELECT `+enrollmentFields+` FROM enrollments e WHERE e.id = `+d.bind(1), id).Scan(enrollmentDest(&e)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enrollment: %w", err)
+	}
+	return &e, nil
+}
+
+// lockSection reads the capacity of a section and, in postgres, locks it
+// until tx ends. Every change to the enrollments of a section takes this
+// lock first, so seats are counted and taken one request at a time.
+func lockSection(ctx context.Context, tx *sql.Tx, d sqlDialect, id int64) (int, error) {
+	var capacity int
+	err := tx.QueryRowContext(ctx, `SELECT capacity FROM course_sections WHERE id = `+d.bind(1)+d.forUpdate, id).Scan(&capacity)
+	if err == sql.ErrNoRows {
+		return 0, errSectionNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get section: %w", err)
+	}
+	return capacity, nil
+}
+
+func countEnrolled(ctx context.Context, tx *sql.Tx, d sqlDialect, sectionID int64) (int, error) {
+	var n int
+	err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM enrollments WHERE section_id = `+d.bind(1)+` AND status = 'enrolled'`, sectionID).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count enrollments: %w", err)
+	}
+	return n, nil
+}
+
+// fillSeats moves students from the front of the waitlist of a section
+// locked with lockSection into its free seats, and returns their enrollments.
+func fillSeats(ctx context.Context, tx *sql.Tx, d sqlDialect, sectionID int64, capacity int, now time.Time) ([]*Enrollment, error) {
+	enrolled, err := countEnrolled(ctx, tx, d, sectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var promoted []*Enrollment
+	for ; enrolled < capacity; enrolled++ {
+		var id int64
+		err := tx.QueryRowContext(ctx, `SELECT id FROM enrollments WHERE section_id = `+d.bind(1)+` AND status = 'waitlisted' ORDER BY id LIMIT 1`, sectionID).Scan(&id)
+		if err == sql.ErrNoRows {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read waitlist: %w", err)
+		}
+
+		query := fmt.Sprintf(`UPDATE enrollments SET status = 'enrolled', updated_at = %s WHERE id = %s`, d.bind(1), d.bind(2))
+		if _, err := tx.ExecContext(ctx, query, now, id); err != nil {
+			return nil, fmt.Errorf("failed to promote enrollment: %w", err)
+		}
+
+		enrollment, err := getEnrollment(ctx, tx, d, id)
+		if err != nil {
+			return nil, err
+		}
+		promoted = append(promoted, enrollment)
+	}
+
+	return promoted, nil
+}
+
+func enroll(ctx context.Context, db *sql.DB, d sqlDialect, studentID, sectionID int64, waitlist bool) (*Enrollment, []*Enrollment, error) {
+	var enrollment *Enrollment
+	var promoted []*Enrollment
+	err := withTx(ctx, db, func(tx *sql.Tx) error {
+		// Lock the student before the section. Their enrollments in other
+		// sections, which the requirement checks read, then can't change
+		// under this one, nor can their status.
+		var status StudentStatus
+		err := tx.QueryRowContext(ctx, `SELECT status FROM students WHERE id = `+d.bind(1)+` AND deleted_at IS NULL`+d.forUpdate, studentID).Scan(&status)
+		if err == sql.ErrNoRows {
+			return errStudentNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get student: %w", err)
+		}
+		if status != StatusActive {
+			return ErrStudentNotActive
+		}
+
+		capacity, err := lockSection(ctx, tx, d, sectionID)
+		if err != nil {
+			return err
+		}
+
+		var active bool
+		err = tx.QueryRowContext(ctx, `SELECT c.active FROM courses c JOIN course_sections s ON s.course_id = c.id WHERE s.id = `+d.bind(1), sectionID).Scan(&active)
+		if err != nil {
+			return fmt.Errorf("failed to get course: %w", err)
+		}
+		if !active {
+			return ErrCourseInactive
+		}
+
+		found, err := exists(ctx, tx, fmt.Sprintf(`SELECT 1 FROM enrollments WHERE student_id = %s AND section_id = %s AND status <> 'dropped'`, d.bind(1), d.bind(2)), studentID, sectionID)
+		if err != nil {
+			return fmt.Errorf("failed to get enrollment: %w", err)
+		}
+		if found {
+			return ErrAlreadyEnrolled
+		}
+
+		reasons, err := checkRequirements(ctx, tx, d, studentID, sectionID)
+		if err != nil {
+			return err
+		}
+		if len(reasons) > 0 {
+			return &EnrollmentRejectedError{Reasons: reasons}
+		}
+
+		// Seats that were freed without promoting anyone, e.g. by purging a
+		// student, go to the waitlist before the newcomer
+		now := time.Now().UTC()
+		promoted, err = fillSeats(ctx, tx, d, sectionID, capacity, now)
+		if err != nil {
+			return err
+		}
+
+		enrolled, err := countEnrolled(ctx, tx, d, sectionID)
+		if err != nil {
+			return err
+		}
+		seat := EnrollmentEnrolled
+		if enrolled >= capacity {
+			if !waitlist {
+				return ErrSectionFull
+			}
+			seat = EnrollmentWaitlisted
+		}
+
+		var id int64
+		query := fmt.Sprintf(
+			`INSERT INTO enrollments (student_id, section_id, status, created_at, updated_at) VALUES (%s, %s, %s, %s, %s) RETURNING id`,
+			d.bind(1), d.bind(2), d.bind(3), d.bind(4), d.bind(5),
+		)
+		if err := tx.QueryRowContext(ctx, query, studentID, sectionID, seat, now, now).Scan(&id); err != nil {
+			return fmt.Errorf("failed to create enrollment: %w", d.fromDriver(err))
+		}
+
+		enrollment, err = getEnrollment(ctx, tx, d, id)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return enrollment, promoted, nil
+}
+
+func dropEnrollment(ctx context.Context, db *sql.DB, d sqlDialect, studentID, enrollmentID int64) (*Enrollment, []*Enrollment, error) {
+	var dropped *Enrollment
+	var promoted []*Enrollment
+	err := withTx(ctx, db, func(tx *sql.Tx) error {
+		var sectionID int64
+		err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT section_id FROM enrollments WHERE id = %s AND student_id = %s`, d.bind(1), d.bind(2)), enrollmentID, studentID).Scan(&sectionID)
+		if err == sql.ErrNoRows {
+			return errEnrollmentNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get enrollment: %w", err)
+		}
+
+		capacity, err := lockSection(ctx, tx, d, sectionID)
+		if err != nil {
+			return err
+		}
+
+		// read the status under the lock, another request may have dropped it
+		before, err := getEnrollment(ctx, tx, d, enrollmentID)
+		if err != nil {
+			return err
+		}
+		if before.Status == EnrollmentDropped {
+			return errEnrollmentNotFound
+		}
+		if before.Grade != "" {
+			return ErrEnrollmentGraded
+		}
+
+		now := time.Now().UTC()
+		query := fmt.Sprintf(`UPDATE enrollments SET status = 'dropped', updated_at = %s WHERE id = %s`, d.bind(1), d.bind(2))
+		if _, err := tx.ExecContext(ctx, query, now, enrollmentID); err != nil {
+			return fmt.Errorf("failed to drop enrollment: %w", err)
+		}
+
+		if dropped, err = getEnrollment(ctx, tx, d, enrollmentID); err != nil {
+			return err
+		}
+		if before.Status == EnrollmentEnrolled {
+			promoted, err = fillSeats(ctx, tx, d, sectionID, capacity, now)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return dropped, promoted, nil
+}
+
+// queryStudentEnrollments lists the enrollments of a student with their
+// sections, oldest first.
+func queryStudentEnrollments(ctx context.Context, db queryer, d sqlDialect, studentID int64) ([]*Enrollment, error) {
+	query := `SELECT ` + enrollmentFields + `, ` + sectionFields + ` FROM enrollments e JOIN course_sections s ON s.id = e.section_id` + sectionJoins +
+		` WHERE e.student_id = ` + d.bind(1) + ` ORDER BY e.id`
+	rows, err := db.QueryContext(ctx, query, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list enrollments: %w", err)
+	}
+	defer rows.Close()
+
+	enrollments := []*Enrollment{}
+	for rows.Next() {
+		e := Enrollment{Section: &Section{}}
+		if err := rows.Scan(append(enrollmentDest(&e), sectionDest(e.Section)...)...); err != nil {
+			return nil, fmt.Errorf("failed to scan enrollment: %w", err)
+		}
+		enrollments = append(enrollments, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	sections := make([]*Section, len(enrollments))
+	for i, e := range enrollments {
+		sections[i] = e.Section
+	}
+	if err := loadMeetings(ctx, db, d, sections...); err != nil {
+		return nil, err
+	}
+	return enrollments, nil
+}
+
+// queryRoster lists the students enrolled in a section, then its waitlist
+// in order.
+func queryRoster(ctx context.Context, db queryer, d sqlDialect, sectionID int64) ([]*RosterEntry, error) {
+	query := `SELECT ` + enrollmentFields + `, st.first_name, st.last_name, st.email, st.registration_no
+		FROM enrollments e JOIN students st ON st.id = e.student_id
+		WHERE e.section_id = ` + d.bind(1) + ` AND e.status <> 'dropped'
+		ORDER BY CASE e.status WHEN 'enrolled' THEN 0 ELSE 1 END, e.id`
+	rows, err := db.QueryContext(ctx, query, sectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roster: %w", err)
+	}
+	defer rows.Close()
+
+	roster := []*RosterEntry{}
+	for rows.Next() {
+		var r RosterEntry
+		if err := rows.Scan(append(enrollmentDest(&r.Enrollment), &r.FirstName, &r.LastName, &r.Email, &r.RegistrationNo)...); err != nil {
+			return nil, fmt.Errorf("failed to scan roster: %w", err)
+		}
+		roster = append(roster, &r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return roster, nil
+}
@@ -0,0 +1,258 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// seedSection creates a course with a section of the given capacity and
+// that many active students plus extra ones.
+func seedSection(t *testing.T, store Storage, capacity, extra int) (*Section, []*Student) {
+	t.Helper()
+	ctx := context.Background()
+
+	course := &Course{Code: "CS101", Title: "Programming", Capacity: capacity, Active: true}
+	if err := store.CreateCourse(ctx, course); err != nil {
+		t.Fatal(err)
+	}
+	term := &Term{
+		Code:     "2026-FA",
+		Name:     "Fall 2026",
+		StartsOn: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		EndsOn:   time.Date(2026, 12, 18, 0, 0, 0, 0, time.UTC),
+	}
+	if err := store.CreateTerm(ctx, term); err != nil {
+		t.Fatal(err)
+	}
+	section := &Section{CourseID: course.ID, TermID: term.ID, Code: "A", Capacity: capacity}
+	if err := store.CreateSection(ctx, section); err != nil {
+		t.Fatal(err)
+	}
+
+	var students []*Student
+	for i := range capacity + extra {
+		student := &Student{
+			FirstName:      fmt.Sprintf("First%02d", i),
+			LastName:       "Student",
+			RegistrationNo: 1000 + i,
+			PhoneNumber:    fmt.Sprintf("+1555000%04d", i),
+			Email:          fmt.Sprintf("student%02d@example.com", i),
+			Password:       "hash",
+		}
+		if err := store.CreateStudent(ctx, student); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := store.TransitionStudent(ctx, student.ID, StatusApplicant, StatusActive, "admitted"); err != nil {
+			t.Fatal(err)
+		}
+		students = append(students, student)
+	}
+	return section, students
+}
+
+func TestEnrollWaitlistAndPromotion(t *testing.T) {
+	ctx := context.Background()
+
+	for name, store := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			section, students := seedSection(t, store, 2, 2)
+
+			var enrollments []*Enrollment
+			for _, student := range students[:3] {
+				e, _, err := store.Enroll(ctx, student.ID, section.ID, true)
+				if err != nil {
+					t.Fatal(err)
+				}
+				enrollments = append(enrollments, e)
+			}
+			if enrollments[1].Status != EnrollmentEnrolled || enrollments[2].Status != EnrollmentWaitlisted || enrollments[2].Position != 1 {
+				t.Fatalf("got %+v and %+v, want the third student first on the waitlist", enrollments[1], enrollments[2])
+			}
+
+			if _, _, err := store.Enroll(ctx, students[3].ID, section.ID, false); !errors.Is(err, ErrSectionFull) {
+				t.Errorf("got %v without the waitlist, want ErrSectionFull", err)
+			}
+			last, _, err := store.Enroll(ctx, students[3].ID, section.ID, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if last.Position != 2 {
+				t.Errorf("last student is at position %d, want 2", last.Position)
+			}
+			if _, _, err := store.Enroll(ctx, students[0].ID, section.ID, true); !errors.Is(err, ErrAlreadyEnrolled) {
+				t.Errorf("got %v enrolling twice, want ErrAlreadyEnrolled", err)
+			}
+
+			// Dropping a seat hands it to the front of the waitlist
+			dropped, promoted, err := store.DropEnrollment(ctx, students[0].ID, enrollments[0].ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if dropped.Status != EnrollmentDropped {
+				t.Errorf("dropped enrollment is %q", dropped.Status)
+			}
+			if len(promoted) != 1 || promoted[0].ID != enrollments[2].ID || promoted[0].Status != EnrollmentEnrolled {
+				t.Fatalf("promoted %+v, want the third student", promoted)
+			}
+			if _, _, err := store.DropEnrollment(ctx, students[0].ID, enrollments[0].ID); !errors.Is(err, ErrNotFound) {
+				t.Errorf("got %v dropping twice, want ErrNotFound", err)
+			}
+
+			roster, err := store.ListSectionRoster(ctx, section.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var order []string
+			for _, r := range roster {
+				order = append(order, fmt.Sprintf("%s:%s:%d", r.FirstName, r.Status, r.Position))
+			}
+			if want := "[First01:enrolled:0 First02:enrolled:0 First03:waitlisted:1]"; fmt.Sprint(order) != want {
+				t.Errorf("roster is %v, want %s", order, want)
+			}
+
+			got, err := store.GetSection(ctx, section.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Enrolled != 2 || got.Waitlisted != 1 || got.CourseCode != "CS101" || got.TermCode != "2026-FA" {
+				t.Errorf("section is %+v", got)
+			}
+
+			// Leaving the waitlist frees no seat
+			_, promoted, err = store.DropEnrollment(ctx, students[3].ID, last.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(promoted) != 0 {
+				t.Errorf("promoted %+v after leaving the waitlist", promoted)
+			}
+
+			history, err := store.ListStudentEnrollments(ctx, students[2].ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(history) != 1 || history[0].Status != EnrollmentEnrolled || history[0].Section == nil || history[0].Section.CourseTitle != "Programming" {
+				t.Errorf("enrollments of the promoted student are %+v", history)
+			}
+
+			if err := store.DeleteCourse(ctx, section.CourseID); !errors.Is(err, ErrCourseHasSections) {
+				t.Errorf("got %v deleting a course with sections, want ErrCourseHasSections", err)
+			}
+		})
+	}
+}
+
+// Purging a student frees their seat without promoting anyone, the next
+// enrollment hands it to the waitlist and returns who got it.
+func TestEnrollPromotesIntoFreedSeats(t *testing.T) {
+	ctx := context.Background()
+
+	for name, store := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			section, students := seedSection(t, store, 1, 2)
+
+			if _, _, err := store.Enroll(ctx, students[0].ID, section.ID, true); err != nil {
+				t.Fatal(err)
+			}
+			waiting, _, err := store.Enroll(ctx, students[1].ID, section.ID, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := store.DeleteStudent(ctx, students[0].ID, 0); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.PurgeStudent(ctx, students[0].ID); err != nil {
+				t.Fatal(err)
+			}
+
+			// the freed seat goes to the waitlist, which leaves none for the
+			// newcomer, and the promotion is undone along with the enrollment
+			if _, promoted, err := store.Enroll(ctx, students[2].ID, section.ID, false); !errors.Is(err, ErrSectionFull) || len(promoted) != 0 {
+				t.Fatalf("got %v and %+v without the waitlist, want ErrSectionFull", err, promoted)
+			}
+			got, err := store.GetSection(ctx, section.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Enrolled != 0 || got.Waitlisted != 1 {
+				t.Errorf("%d enrolled and %d waitlisted after a failed enrollment, want 0 and 1", got.Enrolled, got.Waitlisted)
+			}
+
+			e, promoted, err := store.Enroll(ctx, students[2].ID, section.ID, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(promoted) != 1 || promoted[0].ID != waiting.ID || promoted[0].Status != EnrollmentEnrolled {
+				t.Fatalf("promoted %+v, want the waiting student", promoted)
+			}
+			if e.Status != EnrollmentWaitlisted || e.Position != 1 {
+				t.Errorf("newcomer got %+v, want the front of the waitlist", e)
+			}
+		})
+	}
+}
+
+func TestEnrollChecksStudentAndCourse(t *testing.T) {
+	ctx := context.Background()
+
+	for name, store := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			section, students := seedSection(t, store, 1, 0)
+
+			applicant := &Student{FirstName: "Ada", LastName: "Lovelace", RegistrationNo: 42, PhoneNumber: "+15550100", Email: "ada@example.com", Password: "hash"}
+			if err := store.CreateStudent(ctx, applicant); err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := store.Enroll(ctx, applicant.ID, section.ID, true); !errors.Is(err, ErrStudentNotActive) {
+				t.Errorf("got %v for an applicant, want ErrStudentNotActive", err)
+			}
+
+			course, err := store.GetCourse(ctx, section.CourseID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			course.Active = false
+			if err := store.UpdateCourse(ctx, course); err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := store.Enroll(ctx, students[0].ID, section.ID, true); !errors.Is(err, ErrCourseInactive) {
+				t.Errorf("got %v for an inactive course, want ErrCourseInactive", err)
+			}
+		})
+	}
+}
+
+func TestConcurrentEnrollmentsStayWithinCapacity(t *testing.T) {
+	ctx := context.Background()
+
+	for name, store := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			section, students := seedSection(t, store, 3, 7)
+
+			var wg sync.WaitGroup
+			for _, student := range students {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, _, err := store.Enroll(ctx, student.ID, section.ID, true); err != nil {
+						t.Error(err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			got, err := store.GetSection(ctx, section.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Enrolled != 3 || got.Waitlisted != 7 {
+				t.Errorf("%d enrolled and %d waitlisted, want 3 and 7", got.Enrolled, got.Waitlisted)
+			}
+		})
+	}
+}
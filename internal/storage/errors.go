@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+var (
+	ErrNotFound                = errors.New("not found")
+	ErrDuplicateEmail          = errors.New("email already exists")
+	ErrDuplicateRegistrationNo = errors.New("registration number already exists")
+	ErrConflict                = errors.New("conflicting change, please retry")
+	ErrVersionMismatch         = errors.New("record has been modified, fetch it again and retry")
+	ErrRolledBack              = errors.New("not created because another row failed")
+	ErrInvalidTransition       = errors.New("this status change is not allowed")
+	ErrStatusMismatch          = errors.New("the student's status has changed, fetch it again and retry")
+	ErrDuplicateCourseCode     = errors.New("course code already exists")
+	ErrDuplicateTermCode       = errors.New("term code already exists")
+	ErrDuplicateSectionCode    = errors.New("section code already exists for this course and term")
+	ErrCourseHasSections       = errors.New("the course has sections, set active to false instead")
+	ErrCourseInactive          = errors.New("the course is not offered")
+	ErrStudentNotActive        = errors.New("only active students can enroll")
+	ErrAlreadyEnrolled         = errors.New("the student is already enrolled or waitlisted in this section")
+	ErrSectionFull             = errors.New("the section is full")
+	ErrEnrollmentRejected      = errors.New("the enrollment requirements are not met")
+	ErrPrerequisiteCycle       = errors.New("a course can't require itself, directly or through its prerequisites")
+	ErrCourseIsPrerequisite    = errors.New("the course is a prerequisite of another course")
+	ErrNotGradable             = errors.New("only enrolled students can be graded")
+	ErrEnrollmentGraded        = errors.New("a graded enrollment can't be dropped")
+)
+
+var (
+	errStudentNotFound        = fmt.Errorf("student %w", ErrNotFound)
+	errDeletedStudentNotFound = fmt.Errorf("deleted student %w", ErrNotFound)
+	errRefreshTokenNotFound   = fmt.Errorf("refresh token %w", ErrNotFound)
+	errResetTokenNotFound     = fmt.Errorf("password reset token %w", ErrNotFound)
+	errCourseNotFound         = fmt.Errorf("course %w", ErrNotFound)
+	errTermNotFound           = fmt.Errorf("term %w", ErrNotFound)
+	errSectionNotFound        = fmt.Errorf("section %w", ErrNotFound)
+	errEnrollmentNotFound     = fmt.Errorf("enrollment %w", ErrNotFound)
+)
+
+// fromPostgres turns constraint and concurrency failures reported by postgres
+// into one of the storage errors so handlers don't need to know about pq.
+func fromPostgres(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch {
+	case pqErr.Code == "23505" && strings.Contains(pqErr.Constraint, "email"):
+		return ErrDuplicateEmail
+	case pqErr.Code == "23505" && strings.Contains(pqErr.Constraint, "registration_no"):
+		return ErrDuplicateRegistrationNo
+	case pqErr.Code == "23505" && pqErr.Constraint == "courses_code_key":
+		return ErrDuplicateCourseCode
+	case pqErr.Code == "23505" && pqErr.Constraint == "terms_code_key":
+		return ErrDuplicateTermCode
+	case pqErr.Code == "23505" && pqErr.Constraint == "course_sections_course_id_term_id_code_key":
+		return ErrDuplicateSectionCode
+	case pqErr.Code == "23505" && pqErr.Constraint == "idx_enrollments_student_section":
+		return ErrAlreadyEnrolled
+	case pqErr.Code.Class() == "23":
+		// any other integrity constraint violation
+		return fmt.Errorf("%w: %s", ErrConflict, pqErr.Message)
+	case pqErr.Code == "40001", pqErr.Code == "40P01":
+		// serialization failure or deadlock
+		return ErrConflict
+	}
+
+	return err
+}
+
+// fromSQLite does the same as fromPostgres for the sqlite driver, which only
+// reports the offending table.column in the error message.
+func fromSQLite(err error) error {
+	var liteErr *sqlite.Error
+	if !errors.As(err, &liteErr) {
+		return err
+	}
+
+	msg := liteErr.Error()
+	switch {
+	case liteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE && strings.Contains(msg, ".email"):
+		return ErrDuplicateEmail
+	case liteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE && strings.Contains(msg, ".registration_no"):
+		return ErrDuplicateRegistrationNo
+	case liteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE && strings.Contains(msg, "courses.code"):
+		return ErrDuplicateCourseCode
+	case liteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE && strings.Contains(msg, "terms.code"):
+		return ErrDuplicateTermCode
+	case liteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE && strings.Contains(msg, "course_sections.code"):
+		return ErrDuplicateSectionCode
+	case liteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE && strings.Contains(msg, "enrollments.section_id"):
+		return ErrAlreadyEnrolled
+	case liteErr.Code()&0xff == sqlite3.SQLITE_CONSTRAINT:
+		return fmt.Errorf("%w: %s", ErrConflict, msg)
+	case liteErr.Code()&0xff == sqlite3.SQLITE_BUSY:
+		return ErrConflict
+	}
+
+	return err
+}
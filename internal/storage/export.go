@@ -0,0 +1,43 @@
+package storage
+
+import "context"
+
+// exportBatchSize is how many students an export reads at a time.
+const exportBatchSize = 500
+
+// exportOptions is opts for a full export from the start of the list.
+func exportOptions(opts ListOptions) ListOptions {
+	if !opts.SortBy.Valid() {
+		opts.SortBy = SortByCreatedAt
+	}
+	opts.Offset = 0
+	opts.After = nil
+	opts.Before = nil
+	return opts
+}
+
+// exportPages walks the list in pages with keyset pagination, so nothing is
+// held between pages, e.g. the only connection of the sqlite pool.
+func exportPages(ctx context.Context, list func(context.Context, ListOptions) ([]*Student, error), opts ListOptions, fn func(*Student) error) error {
+	opts = exportOptions(opts)
+	opts.Limit = exportBatchSize
+
+	for {
+		students, err := list(ctx, opts)
+		if err != nil {
+			return err
+		}
+
+		for _, student := range students {
+			if err := fn(student); err != nil {
+				return err
+			}
+		}
+
+		if len(students) < exportBatchSize {
+			return nil
+		}
+		last := students[len(students)-1]
+		opts.After = &Cursor{Value: last.SortValue(opts.SortBy), ID: last.ID}
+	}
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+const guardianColumns = "id, student_id, name, relationship, phone_number, email, emergency_contact"
+
+// replaceGuardians deletes the guardians of a student and inserts guardians
+// in their place, in the transaction that changes the student. The ids of the
+// new rows are set in guardians.
+func replaceGuardians(ctx context.Context, tx *sql.Tx, bind func(n int) string, studentID int64, guardians []Guardian) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM student_guardians WHERE student_id = `+bind(1), studentID); err != nil {
+		return fmt.Errorf("failed to delete guardians: %w", err)
+	}
+
+	query := fmt.Sprintf(
+		`INSERT INTO student_guardians (student_id, name, relationship, phone_number, email, emergency_contact, position) VALUES (%s, %s, %s, %s, %s, %s, %s) RETURNING id`,
+		bind(1), bind(2), bind(3), bind(4), bind(5), bind(6), bind(7),
+	)
+	for i := range guardians {
+		g := &guardians[i]
+		g.StudentID = studentID
+		err := tx.QueryRowContext(ctx, query, studentID, g.Name, g.Relationship, g.PhoneNumber, g.Email, g.EmergencyContact, i).Scan(&g.ID)
+		if err != nil {
+			return fmt.Errorf("failed to insert guardian: %w", err)
+		}
+	}
+	return nil
+}
+
+// queryer is a *sql.DB or a *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// queryGuardians lists the guardians of a student in the order they were given.
+func queryGuardians(ctx context.Context, db queryer, bind func(n int) string, studentID int64) ([]Guardian, error) {
+	rows, err := db.QueryContext(ctx, `SELECT `+guardianColumns+` FROM student_guardians WHERE student_id = `+bind(1)+` ORDER BY position`, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list guardians: %w", err)
+	}
+	defer rows.Close()
+
+	guardians := []Guardian{}
+	for rows.Next() {
+		var g Guardian
+		if err := rows.Scan(&g.ID, &g.StudentID, &g.Name, &g.Relationship, &g.PhoneNumber, &g.Email, &g.EmergencyContact); err != nil {
+			return nil, fmt.Errorf("failed to scan guardian: %w", err)
+		}
+		guardians = append(guardians, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return guardians, nil
+}
+
+// guardianSummary describes guardians in one line for the audit log.
+func guardianSummary(guardians []Guardian) string {
+	parts := make([]string, len(guardians))
+	for i, g := range guardians {
+		parts[i] = fmt.Sprintf("%s (%s, %s)", g.Name, g.Relationship, g.PhoneNumber)
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// rowFailed reports whether err only concerns the row being imported, as
+// opposed to the database or the transaction.
+func rowFailed(err error) bool {
+	return errors.Is(err, ErrDuplicateEmail) ||
+		errors.Is(err, ErrDuplicateRegistrationNo) ||
+		errors.Is(err, ErrConflict)
+}
+
+// importStudents runs insert for each student under its own savepoint, so a
+// failing row is undone without aborting the rest of the transaction.
+func importStudents(ctx context.Context, db *sql.DB, students []*Student, atomic bool, insert func(tx *sql.Tx, student *Student) error) ([]error, error) {
+	errs := make([]error, len(students))
+	failed := false
+
+	err := withTx(ctx, db, func(tx *sql.Tx) error {
+		for i, student := range students {
+			if _, err := tx.ExecContext(ctx, `SAVEPOINT import_row`); err != nil {
+				return fmt.Errorf("failed to create savepoint: %w", err)
+			}
+
+			err := insert(tx, student)
+			if err != nil && !rowFailed(err) {
+				return err
+			}
+			if err != nil {
+				if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT import_row`); err != nil {
+					return fmt.Errorf("failed to roll back savepoint: %w", err)
+				}
+				student.ID = 0
+				errs[i] = err
+				failed = true
+				continue
+			}
+
+			if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT import_row`); err != nil {
+				return fmt.Errorf("failed to release savepoint: %w", err)
+			}
+		}
+
+		if atomic && failed {
+			return ErrRolledBack
+		}
+		return nil
+	})
+
+	if errors.Is(err, ErrRolledBack) {
+		rollBackImport(students, errs)
+		return errs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return errs, nil
+}
+
+// rollBackImport marks the rows that were created before an atomic import failed.
+func rollBackImport(students []*Student, errs []error) {
+	for i, student := range students {
+		if errs[i] == nil {
+			student.ID = 0
+			errs[i] = ErrRolledBack
+		}
+	}
+}
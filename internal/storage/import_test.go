@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func importRow(i int) *Student {
+	return &Student{
+		FirstName:      fmt.Sprintf("Import%02d", i),
+		LastName:       "Student",
+		RegistrationNo: 5000 + i,
+		PhoneNumber:    fmt.Sprintf("+1555100%04d", i),
+		Email:          fmt.Sprintf("import%02d@example.com", i),
+		Password:       "hash",
+	}
+}
+
+// seedImport creates a student the import rows can clash with.
+func seedImport(t *testing.T, store Storage) *Student {
+	t.Helper()
+
+	existing := importRow(0)
+	if err := store.CreateStudent(context.Background(), existing); err != nil {
+		t.Fatal(err)
+	}
+	return existing
+}
+
+// importBatch has a clash with the existing student and one within the
+// batch between valid rows.
+func importBatch() []*Student {
+	sameEmail := importRow(2)
+	sameEmail.Email = importRow(0).Email
+	sameRegNo := importRow(4)
+	sameRegNo.RegistrationNo = importRow(1).RegistrationNo
+	return []*Student{importRow(1), sameEmail, importRow(3), sameRegNo}
+}
+
+func TestImportStudentsBestEffort(t *testing.T) {
+	ctx := context.Background()
+
+	for name, store := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			seedImport(t, store)
+
+			students := importBatch()
+			errs, err := store.ImportStudents(ctx, students, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// one result per row, in order
+			want := []error{nil, ErrDuplicateEmail, nil, ErrDuplicateRegistrationNo}
+			if len(errs) != len(want) {
+				t.Fatalf("got %d results for %d rows", len(errs), len(want))
+			}
+			for i := range want {
+				if !errors.Is(errs[i], want[i]) {
+					t.Errorf("row %d: got %v, want %v", i, errs[i], want[i])
+				}
+			}
+
+			for i, student := range students {
+				if created := errs[i] == nil; created != (student.ID != 0) {
+					t.Errorf("row %d has id %d with error %v", i, student.ID, errs[i])
+				}
+			}
+			for _, i := range []int{0, 2} {
+				got, err := store.GetStudentByID(ctx, students[i].ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Email != students[i].Email || got.Status != StatusApplicant || got.Role != RoleStudent {
+					t.Errorf("row %d is stored as %+v", i, got)
+				}
+			}
+
+			if n, err := store.CountStudents(ctx, StudentFilter{}); err != nil || n != 3 {
+				t.Errorf("got %d students and %v, want 3", n, err)
+			}
+		})
+	}
+}
+
+func TestImportStudentsAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+
+	for name, store := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			seedImport(t, store)
+
+			students := importBatch()
+			errs, err := store.ImportStudents(ctx, students, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the failed rows keep their own error, the others are rolled back
+			want := []error{ErrRolledBack, ErrDuplicateEmail, ErrRolledBack, ErrDuplicateRegistrationNo}
+			for i := range want {
+				if !errors.Is(errs[i], want[i]) {
+					t.Errorf("row %d: got %v, want %v", i, errs[i], want[i])
+				}
+				if students[i].ID != 0 {
+					t.Errorf("row %d kept id %d", i, students[i].ID)
+				}
+			}
+
+			if n, err := store.CountStudents(ctx, StudentFilter{}); err != nil || n != 1 {
+				t.Errorf("got %d students and %v, want only the existing one", n, err)
+			}
+			if n, err := store.CountAuditEntries(ctx, AuditFilter{Action: AuditCreate}); err != nil || n != 1 {
+				t.Errorf("got %d create entries and %v, want only the existing student's", n, err)
+			}
+
+			// nothing was left behind to clash with a clean retry
+			errs, err = store.ImportStudents(ctx, []*Student{importRow(1), importRow(3)}, true)
+			if err != nil || errs[0] != nil || errs[1] != nil {
+				t.Errorf("retry got %v and %v", errs, err)
+			}
+		})
+	}
+}
+
+// A row that fails after it wrote something is undone by rolling back to its
+// savepoint, the rows around it are kept.
+func TestImportStudentsSavepointRollback(t *testing.T) {
+	ctx := context.Background()
+	store := testBackends(t)["sqlite"].(*SQLiteStorage)
+
+	students := []*Student{importRow(1), importRow(2), importRow(3)}
+	errs, err := importStudents(ctx, store.db, students, false, func(tx *sql.Tx, student *Student) error {
+		if err := store.insertStudent(ctx, tx, student, time.Now().UTC()); err != nil {
+			return err
+		}
+		if student == students[1] {
+			return fmt.Errorf("after the insert: %w", ErrConflict)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs[0] != nil || !errors.Is(errs[1], ErrConflict) || errs[2] != nil {
+		t.Fatalf("got %v", errs)
+	}
+	if students[1].ID != 0 {
+		t.Errorf("failed row kept id %d", students[1].ID)
+	}
+
+	if _, err := store.GetStudentByEmail(ctx, students[1].Email); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got %v for the failed row, want ErrNotFound", err)
+	}
+	for _, i := range []int{0, 2} {
+		if _, err := store.GetStudentByID(ctx, students[i].ID); err != nil {
+			t.Errorf("row %d: %v", i, err)
+		}
+	}
+	if n, err := store.CountAuditEntries(ctx, AuditFilter{Action: AuditCreate}); err != nil || n != 2 {
+		t.Errorf("got %d create entries and %v, want 2", n, err)
+	}
+
+	// an error that isn't about the row aborts the whole import
+	boom := errors.New("connection lost")
+	students = []*Student{importRow(4), importRow(5)}
+	_, err = importStudents(ctx, store.db, students, false, func(tx *sql.Tx, student *Student) error {
+		if student == students[1] {
+			return boom
+		}
+		return store.insertStudent(ctx, tx, student, time.Now().UTC())
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("got %v, want the database error", err)
+	}
+	if _, err := store.GetStudentByEmail(ctx, students[0].Email); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got %v for a row of the aborted import, want ErrNotFound", err)
+	}
+}
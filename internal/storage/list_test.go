@@ -0,0 +1,226 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// testBackends returns a fresh memory and sqlite store, the sqlite one in a
+// temporary file with every migration applied.
+func testBackends(t *testing.T) map[string]Storage {
+	t.Helper()
+
+	lite, err := NewSQLiteStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lite.Close() })
+
+	migrator, err := lite.Migrator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return map[string]Storage{
+		"memory": NewMemoryStorage(),
+		"sqlite": lite,
+	}
+}
+
+// seedStudents creates the same students in every backend, in the same
+// order, so they get the same ids. Last names repeat to exercise the id
+// tie-break.
+func seedStudents(t *testing.T, store Storage) {
+	t.Helper()
+
+	lastNames := []string{"Lee", "Adams", "Lee", "Brown", "Adams", "Lee", "Young", "Brown", "Lee", "Adams", "Zhou"}
+	for i, lastName := range lastNames {
+		student := &Student{
+			FirstName:      fmt.Sprintf("First%02d", i),
+			LastName:       lastName,
+			RegistrationNo: 1000 + (i*7)%len(lastNames),
+			PhoneNumber:    fmt.Sprintf("+1555000%04d", i),
+			Email:          fmt.Sprintf("student%02d@example.com", i),
+			Password:       "hash",
+		}
+		if err := store.CreateStudent(context.Background(), student); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func studentIDs(students []*Student) []int64 {
+	ids := make([]int64, len(students))
+	for i, student := range students {
+		ids[i] = student.ID
+	}
+	return ids
+}
+
+func position(student *Student, field SortField) *Cursor {
+	return &Cursor{Value: student.SortValue(field), ID: student.ID}
+}
+
+func TestListStudentsKeysetPaging(t *testing.T) {
+	ctx := context.Background()
+	sorts := []struct {
+		field SortField
+		desc  bool
+	}{
+		{SortByID, false},
+		{SortByID, true},
+		{SortByLastName, false},
+		{SortByLastName, true},
+		{SortByRegistrationNo, false},
+		{SortByEmail, true},
+		{SortByCreatedAt, false},
+		{SortByCreatedAt, true},
+	}
+
+	for name, store := range testBackends(t) {
+		seedStudents(t, store)
+
+		for _, sort := range sorts {
+			for _, limit := range []int{1, 3, 4, 20} {
+				t.Run(fmt.Sprintf("%s/%s/desc=%v/limit=%d", name, sort.field, sort.desc, limit), func(t *testing.T) {
+					opts := ListOptions{SortBy: sort.field, SortDesc: sort.desc}
+					all, err := store.ListStudents(ctx, opts)
+					if err != nil {
+						t.Fatal(err)
+					}
+					want := studentIDs(all)
+
+					// Forward with After, every row exactly once and in order
+					var forward []int64
+					opts.Limit = limit
+					for page := 0; ; page++ {
+						students, err := store.ListStudents(ctx, opts)
+						if err != nil {
+							t.Fatal(err)
+						}
+						if len(students) > limit {
+							t.Fatalf("page %d has %d rows, limit is %d", page, len(students), limit)
+						}
+						if len(students) == 0 {
+							break
+						}
+						forward = append(forward, studentIDs(students)...)
+						opts.After = position(students[len(students)-1], sort.field)
+					}
+					if !slices.Equal(forward, want) {
+						t.Fatalf("paging forward got %v, want %v", forward, want)
+					}
+
+					// Backward with Before from past the last row
+					var backward []int64
+					opts.After = nil
+					opts.Before = position(all[len(all)-1], sort.field)
+					backward = append(backward, all[len(all)-1].ID)
+					for {
+						students, err := store.ListStudents(ctx, opts)
+						if err != nil {
+							t.Fatal(err)
+						}
+						if len(students) == 0 {
+							break
+						}
+						backward = append(studentIDs(students), backward...)
+						opts.Before = position(students[0], sort.field)
+					}
+					if !slices.Equal(backward, want) {
+						t.Fatalf("paging backward got %v, want %v", backward, want)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestListStudentsTieBreaksOnID(t *testing.T) {
+	for name, store := range testBackends(t) {
+		seedStudents(t, store)
+
+		for _, desc := range []bool{false, true} {
+			students, err := store.ListStudents(context.Background(), ListOptions{SortBy: SortByLastName, SortDesc: desc})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var lee []int64
+			for _, student := range students {
+				if student.LastName == "Lee" {
+					lee = append(lee, student.ID)
+				}
+			}
+			want := []int64{1, 3, 6, 9}
+			if desc {
+				want = []int64{9, 6, 3, 1}
+			}
+			if !slices.Equal(lee, want) {
+				t.Errorf("%s desc=%v: Lee rows in order %v, want %v", name, desc, lee, want)
+			}
+		}
+	}
+}
+
+// The backends have to return the same rows for the same options.
+func TestListStudentsBackendsAgree(t *testing.T) {
+	regMin, regMax := 1002, 1008
+	tests := []struct {
+		name string
+		opts ListOptions
+	}{
+		{"default sort", ListOptions{}},
+		{"zero limit ignores offset", ListOptions{SortBy: SortByID, Offset: 4}},
+		{"offset page", ListOptions{SortBy: SortByLastName, Limit: 3, Offset: 2}},
+		{"offset past the end", ListOptions{SortBy: SortByID, Limit: 3, Offset: 50}},
+		{"name prefix", ListOptions{SortBy: SortByID, Filter: StudentFilter{NamePrefix: "le"}}},
+		{"registration range", ListOptions{SortBy: SortByRegistrationNo, SortDesc: true, Filter: StudentFilter{RegistrationNoMin: &regMin, RegistrationNoMax: &regMax}}},
+		{"search", ListOptions{SortBy: SortByEmail, Filter: StudentFilter{Query: "T0"}}},
+		{"search escapes wildcards", ListOptions{Filter: StudentFilter{Query: "%"}}},
+		{"after cursor", ListOptions{SortBy: SortByRegistrationNo, Limit: 2, After: &Cursor{Value: int64(1003), ID: 0}}},
+		{"before cursor", ListOptions{SortBy: SortByID, SortDesc: true, Limit: 3, Before: &Cursor{Value: int64(5), ID: 5}}},
+		{"zero limit before cursor", ListOptions{SortBy: SortByID, Before: &Cursor{Value: int64(4), ID: 4}}},
+	}
+
+	backends := testBackends(t)
+	for _, store := range backends {
+		seedStudents(t, store)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := make(map[string][]int64)
+			for name, store := range backends {
+				students, err := store.ListStudents(context.Background(), tt.opts)
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				results[name] = studentIDs(students)
+			}
+			if !slices.Equal(results["memory"], results["sqlite"]) {
+				t.Errorf("memory returned %v, sqlite %v", results["memory"], results["sqlite"])
+			}
+		})
+	}
+}
+
+func TestListStudentsZeroLimit(t *testing.T) {
+	for name, store := range testBackends(t) {
+		seedStudents(t, store)
+
+		students, err := store.ListStudents(context.Background(), ListOptions{Offset: 5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(students) != 11 {
+			t.Errorf("%s: got %d students, want all 11", name, len(students))
+		}
+	}
+}
@@ -0,0 +1,538 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type MemoryStorage struct {
+	mu       sync.RWMutex
+	students map[int64]*Student
+	nextID   int64
+
+	guardians      map[int64][]Guardian
+	nextGuardianID int64
+
+	refreshTokens map[string]*RefreshToken
+	resetTokens   map[string]*PasswordResetToken
+	nextTokenID   int64
+
+	audit []*AuditEntry
+
+	transitions []*StatusTransition
+
+	courses      map[int64]*Course
+	nextCourseID int64
+
+	terms            map[int64]*Term
+	sections         map[int64]*Section
+	enrollments      []*Enrollment
+	nextTermID       int64
+	nextSectionID    int64
+	nextEnrollmentID int64
+
+	// prerequisites are by course and meetings by section
+	prerequisites map[int64][][]Prerequisite
+	meetings      map[int64][]Meeting
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		students:         make(map[int64]*Student),
+		nextID:           1,
+		guardians:        make(map[int64][]Guardian),
+		nextGuardianID:   1,
+		refreshTokens:    make(map[string]*RefreshToken),
+		resetTokens:      make(map[string]*PasswordResetToken),
+		courses:          make(map[int64]*Course),
+		nextCourseID:     1,
+		terms:            make(map[int64]*Term),
+		sections:         make(map[int64]*Section),
+		nextTermID:       1,
+		nextSectionID:    1,
+		nextEnrollmentID: 1,
+		prerequisites:    make(map[int64][][]Prerequisite),
+		meetings:         make(map[int64][]Meeting),
+	}
+}
+
+// live returns the student with the given id unless it is missing or in the trash.
+func (s *MemoryStorage) live(id int64) (*Student, bool) {
+	student, ok := s.students[id]
+	if !ok || student.DeletedAt != nil {
+		return nil, false
+	}
+	return student, true
+}
+
+// checkUnique mirrors the partial UNIQUE indexes on the students table, which
+// only cover live rows. The student with the given id is ignored so updates
+// can keep their own values.
+func (s *MemoryStorage) checkUnique(id int64, email string, registrationNo int) error {
+	for _, existing := range s.students {
+		if existing.ID == id || existing.DeletedAt != nil {
+			continue
+		}
+		if existing.Email == email {
+			return ErrDuplicateEmail
+		}
+		if existing.RegistrationNo == registrationNo {
+			return ErrDuplicateRegistrationNo
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStorage) CreateStudent(ctx context.Context, student *Student) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.insertStudent(ctx, student, time.Now())
+}
+
+func (s *MemoryStorage) ImportStudents(ctx context.Context, students []*Student, atomic bool) ([]error, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// remember where we started, so an atomic import can be undone
+	nextID, auditLen := s.nextID, len(s.audit)
+
+	errs := make([]error, len(students))
+	failed := false
+	now := time.Now()
+	for i, student := range students {
+		if err := s.insertStudent(ctx, student, now); err != nil {
+			errs[i] = err
+			failed = true
+		}
+	}
+
+	if atomic && failed {
+		for id := nextID; id < s.nextID; id++ {
+			delete(s.students, id)
+			delete(s.guardians, id)
+		}
+		s.nextID = nextID
+		s.audit = s.audit[:auditLen]
+		rollBackImport(students, errs)
+	}
+
+	return errs, nil
+}
+
+// insertStudent stores student, the caller holds the write lock.
+func (s *MemoryStorage) insertStudent(ctx context.Context, student *Student, now time.Time) error {
+	if err := s.checkUnique(0, student.Email, student.RegistrationNo); err != nil {
+		return fmt.Errorf("failed to create student: %w", err)
+	}
+
+	if student.Role == "" {
+		student.Role = RoleStudent
+	}
+	if student.Status == "" {
+		student.Status = StatusApplicant
+	}
+
+	student.ID = s.nextID
+	student.CreatedAt = now
+	student.UpdatedAt = now
+	student.Version = 1
+	s.nextID++
+
+	if student.Guardians != nil {
+		s.replaceGuardians(student.ID, student.Guardians)
+	}
+
+	stored := *student
+	s.recordAudit(ctx, AuditCreate, nil, &stored, now)
+	stored.Guardians = nil
+	s.students[stored.ID] = &stored
+	return nil
+}
+
+// replaceGuardians stores guardians as the only guardians of the student and
+// sets their ids, the caller holds the write lock.
+func (s *MemoryStorage) replaceGuardians(studentID int64, guardians []Guardian) {
+	for i := range guardians {
+		guardians[i].ID = s.nextGuardianID
+		guardians[i].StudentID = studentID
+		s.nextGuardianID++
+	}
+	s.guardians[studentID] = append([]Guardian(nil), guardians...)
+}
+
+func (s *MemoryStorage) ListGuardians(ctx context.Context, studentID int64) ([]Guardian, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]Guardian{}, s.guardians[studentID]...), nil
+}
+
+func (s *MemoryStorage) GetStudentByID(ctx context.Context, id int64) (*Student, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	student, ok := s.live(id)
+	if !ok {
+		return nil, errStudentNotFound
+	}
+
+	found := *student
+	return &found, nil
+}
+
+func (s *MemoryStorage) GetStudentByEmail(ctx context.Context, email string) (*Student, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, student := range s.students {
+		if student.Email == email && student.DeletedAt == nil {
+			found := *student
+			return &found, nil
+		}
+	}
+
+	return nil, errStudentNotFound
+}
+
+func (s *MemoryStorage) UpdateStudent(ctx context.Context, id int64, update StudentUpdate) (*Student, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.live(id)
+	if !ok {
+		return nil, errStudentNotFound
+	}
+
+	if update.Version != 0 && existing.Version != update.Version {
+		return nil, ErrVersionMismatch
+	}
+
+	if update.Empty() {
+		found := *existing
+		return &found, nil
+	}
+
+	updated := *existing
+	if update.FirstName != nil {
+		updated.FirstName = *update.FirstName
+	}
+	if update.LastName != nil {
+		updated.LastName = *update.LastName
+	}
+	if update.RegistrationNo != nil {
+		updated.RegistrationNo = *update.RegistrationNo
+	}
+	if update.PhoneNumber != nil {
+		updated.PhoneNumber = *update.PhoneNumber
+	}
+	if update.Email != nil {
+		updated.Email = *update.Email
+	}
+	if update.Profile != nil {
+		updated.Profile = *update.Profile
+	}
+
+	if err := s.checkUnique(id, updated.Email, updated.RegistrationNo); err != nil {
+		return nil, fmt.Errorf("failed to update student: %w", err)
+	}
+
+	// like the SQL backends, the audit log only mentions guardians that were replaced
+	before := *existing
+	if update.Guardians != nil {
+		before.Guardians = append([]Guardian{}, s.guardians[id]...)
+		s.replaceGuardians(id, *update.Guardians)
+		updated.Guardians = *update.Guardians
+	}
+
+	updated.UpdatedAt = time.Now()
+	updated.Version++
+	s.recordAudit(ctx, AuditUpdate, &before, &updated, updated.UpdatedAt)
+	*existing = updated
+	existing.Guardians = nil
+
+	return &updated, nil
+}
+
+func (s *MemoryStorage) SetStudentRole(ctx context.Context, id int64, role Role) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.live(id)
+	if !ok {
+		return errStudentNotFound
+	}
+
+	before := *existing
+	existing.Role = role
+	existing.UpdatedAt = time.Now()
+	existing.Version++
+	s.recordAudit(ctx, AuditUpdate, &before, existing, existing.UpdatedAt)
+	return nil
+}
+
+func (s *MemoryStorage) UpdateStudentPassword(ctx context.Context, id int64, passwordHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.live(id)
+	if !ok {
+		return errStudentNotFound
+	}
+
+	before := *existing
+	existing.Password = passwordHash
+	existing.UpdatedAt = time.Now()
+	existing.Version++
+	s.recordAudit(ctx, AuditUpdate, &before, existing, existing.UpdatedAt)
+	return nil
+}
+
+func (s *MemoryStorage) DeleteStudent(ctx context.Context, id int64, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.live(id)
+	if !ok {
+		return errStudentNotFound
+	}
+	if version != 0 && existing.Version != version {
+		return ErrVersionMismatch
+	}
+
+	before := *existing
+	now := time.Now()
+	existing.DeletedAt = &now
+	existing.UpdatedAt = now
+	existing.Version++
+	s.recordAudit(ctx, AuditDelete, &before, existing, now)
+	return nil
+}
+
+func (s *MemoryStorage) RestoreStudent(ctx context.Context, id int64) (*Student, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.students[id]
+	if !ok || existing.DeletedAt == nil {
+		return nil, errDeletedStudentNotFound
+	}
+
+	if err := s.checkUnique(id, existing.Email, existing.RegistrationNo); err != nil {
+		return nil, fmt.Errorf("failed to restore student: %w", err)
+	}
+
+	before := *existing
+	existing.DeletedAt = nil
+	existing.UpdatedAt = time.Now()
+	existing.Version++
+	s.recordAudit(ctx, AuditRestore, &before, existing, existing.UpdatedAt)
+
+	restored := *existing
+	return &restored, nil
+}
+
+// purge removes a student and, like ON DELETE CASCADE, their tokens and
+// enrollments.
+func (s *MemoryStorage) purge(ctx context.Context, id int64) {
+	s.recordAudit(ctx, AuditPurge, s.students[id], nil, time.Now())
+	delete(s.students, id)
+	delete(s.guardians, id)
+	s.transitions = slices.DeleteFunc(s.transitions, func(t *StatusTransition) bool { return t.StudentID == id })
+	s.enrollments = slices.DeleteFunc(s.enrollments, func(e *Enrollment) bool { return e.StudentID == id })
+
+	for hash, token := range s.refreshTokens {
+		if token.StudentID == id {
+			delete(s.refreshTokens, hash)
+		}
+	}
+	for hash, token := range s.resetTokens {
+		if token.StudentID == id {
+			delete(s.resetTokens, hash)
+		}
+	}
+}
+
+func (s *MemoryStorage) PurgeStudent(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.students[id]
+	if !ok || existing.DeletedAt == nil {
+		return errDeletedStudentNotFound
+	}
+
+	s.purge(ctx, id)
+	return nil
+}
+
+func (s *MemoryStorage) PurgeDeletedStudents(ctx context.Context, deletedBefore time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := 0
+	for id, student := range s.students {
+		if student.DeletedAt != nil && student.DeletedAt.Before(deletedBefore) {
+			s.purge(ctx, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// compareValues compares two sort values of the same kind, see Student.SortValue.
+func compareValues(a, b any) int {
+	switch av := a.(type) {
+	case string:
+		return strings.Compare(av, b.(string))
+	case int64:
+		return cmp.Compare(av, b.(int64))
+	case time.Time:
+		return av.Compare(b.(time.Time))
+	}
+	return 0
+}
+
+// listOrder compares two list positions in the requested sort direction, ties broken by id.
+func listOrder(a, b Cursor, desc bool) int {
+	c := compareValues(a.Value, b.Value)
+	if c == 0 {
+		c = cmp.Compare(a.ID, b.ID)
+	}
+	if desc {
+		return -c
+	}
+	return c
+}
+
+func matchesFilter(student *Student, f StudentFilter) bool {
+	if f.Deleted != (student.DeletedAt != nil) {
+		return false
+	}
+	if f.NamePrefix != "" {
+		prefix := strings.ToLower(f.NamePrefix)
+		if !strings.HasPrefix(strings.ToLower(student.FirstName), prefix) &&
+			!strings.HasPrefix(strings.ToLower(student.LastName), prefix) {
+			return false
+		}
+	}
+	if f.Status != "" && student.Status != f.Status {
+		return false
+	}
+	if f.RegistrationNoMin != nil && student.RegistrationNo < *f.RegistrationNoMin {
+		return false
+	}
+	if f.RegistrationNoMax != nil && student.RegistrationNo > *f.RegistrationNoMax {
+		return false
+	}
+	if f.CreatedFrom != nil && student.CreatedAt.Before(*f.CreatedFrom) {
+		return false
+	}
+	if f.CreatedTo != nil && student.CreatedAt.After(*f.CreatedTo) {
+		return false
+	}
+	if f.UpdatedFrom != nil && student.UpdatedAt.Before(*f.UpdatedFrom) {
+		return false
+	}
+	if f.UpdatedTo != nil && student.UpdatedAt.After(*f.UpdatedTo) {
+		return false
+	}
+	if f.Query != "" {
+		q := strings.ToLower(f.Query)
+		if !strings.Contains(strings.ToLower(student.FirstName), q) &&
+			!strings.Contains(strings.ToLower(student.LastName), q) &&
+			!strings.Contains(strings.ToLower(student.Email), q) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *MemoryStorage) ListStudents(ctx context.Context, opts ListOptions) ([]*Student, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	field := opts.SortBy
+	if !field.Valid() {
+		field = SortByCreatedAt
+	}
+	position := func(student *Student) Cursor {
+		return Cursor{Value: student.SortValue(field), ID: student.ID}
+	}
+
+	var all []*Student
+	for _, student := range s.students {
+		if matchesFilter(student, opts.Filter) {
+			copied := *student
+			all = append(all, &copied)
+		}
+	}
+
+	return sortAndPage(all, position, opts.SortDesc, opts.Limit, opts.Offset, opts.After, opts.Before), nil
+}
+
+// sortAndPage sorts rows like the SQL backends and returns the page of them
+// the limit, offset and cursors select. A zero limit selects every row and,
+// like orderAndPage, ignores offset.
+func sortAndPage[T any](rows []T, position func(T) Cursor, desc bool, limit, offset int, after, before *Cursor) []T {
+	sort.Slice(rows, func(i, j int) bool {
+		return listOrder(position(rows[i]), position(rows[j]), desc) < 0
+	})
+
+	start, end := 0, len(rows)
+	if limit > 0 {
+		start = offset
+	}
+	switch {
+	case after != nil:
+		start = sort.Search(len(rows), func(i int) bool {
+			return listOrder(position(rows[i]), *after, desc) > 0
+		})
+	case before != nil:
+		end = sort.Search(len(rows), func(i int) bool {
+			return listOrder(position(rows[i]), *before, desc) >= 0
+		})
+		start = 0
+		if limit > 0 {
+			start = max(end-limit, 0)
+		}
+	}
+
+	if start >= end {
+		return nil
+	}
+	if limit > 0 && end-start > limit {
+		end = start + limit
+	}
+
+	return rows[start:end]
+}
+
+func (s *MemoryStorage) CountStudents(ctx context.Context, filter StudentFilter) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	total := 0
+	for _, student := range s.students {
+		if matchesFilter(student, filter) {
+			total++
+		}
+	}
+
+	return total, nil
+}
+
+func (s *MemoryStorage) Close() error {
+	return nil
+}
+
+func (s *MemoryStorage) ExportStudents(ctx context.Context, opts ListOptions, fn func(*Student) error) error {
+	return exportPages(ctx, s.ListStudents, opts, fn)
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// recordAudit appends an audit entry, the caller holds the write lock.
+func (s *MemoryStorage) recordAudit(ctx context.Context, action AuditAction, before, after *Student, now time.Time) {
+	entry := newAuditEntry(ctx, action, before, after, now)
+	entry.ID = int64(len(s.audit)) + 1
+	s.audit = append(s.audit, entry)
+}
+
+func matchesAuditFilter(entry *AuditEntry, f AuditFilter) bool {
+	if f.StudentID != nil && entry.StudentID != *f.StudentID {
+		return false
+	}
+	if f.ActorID != nil && (entry.ActorID == nil || *entry.ActorID != *f.ActorID) {
+		return false
+	}
+	if f.Action != "" && entry.Action != f.Action {
+		return false
+	}
+	if f.RequestID != "" && entry.RequestID != f.RequestID {
+		return false
+	}
+	if f.From != nil && entry.CreatedAt.Before(*f.From) {
+		return false
+	}
+	if f.To != nil && entry.CreatedAt.After(*f.To) {
+		return false
+	}
+	return true
+}
+
+func (s *MemoryStorage) ListAuditEntries(ctx context.Context, filter AuditFilter, limit, offset int) ([]*AuditEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// newest first, like the SQL backends
+	var entries []*AuditEntry
+	skipped := 0
+	for i := len(s.audit) - 1; i >= 0 && len(entries) < limit; i-- {
+		if !matchesAuditFilter(s.audit[i], filter) {
+			continue
+		}
+		if skipped < offset {
+			skipped++
+			continue
+		}
+		copied := *s.audit[i]
+		entries = append(entries, &copied)
+	}
+
+	return entries, nil
+}
+
+func (s *MemoryStorage) CountAuditEntries(ctx context.Context, filter AuditFilter) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	total := 0
+	for _, entry := range s.audit {
+		if matchesAuditFilter(entry, filter) {
+			total++
+		}
+	}
+
+	return total, nil
+}
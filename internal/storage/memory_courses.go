@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// checkCourseCode mirrors the UNIQUE index on courses.code, ignoring the
+// course with the given id.
+func (s *MemoryStorage) checkCourseCode(id int64, code string) error {
+	for _, existing := range s.courses {
+		if existing.ID != id && existing.Code == code {
+			return ErrDuplicateCourseCode
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStorage) CreateCourse(ctx context.Context, course *Course) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkCourseCode(0, course.Code); err != nil {
+		return fmt.Errorf("failed to create course: %w", err)
+	}
+
+	now := time.Now()
+	course.ID = s.nextCourseID
+	course.CreatedAt = now
+	course.UpdatedAt = now
+	s.nextCourseID++
+
+	stored := *course
+	s.courses[stored.ID] = &stored
+	return nil
+}
+
+func (s *MemoryStorage) GetCourse(ctx context.Context, id int64) (*Course, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	course, ok := s.courses[id]
+	if !ok {
+		return nil, errCourseNotFound
+	}
+
+	found := *course
+	return &found, nil
+}
+
+func (s *MemoryStorage) UpdateCourse(ctx context.Context, course *Course) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.courses[course.ID]
+	if !ok {
+		return errCourseNotFound
+	}
+	if err := s.checkCourseCode(course.ID, course.Code); err != nil {
+		return fmt.Errorf("failed to update course: %w", err)
+	}
+
+	course.CreatedAt = existing.CreatedAt
+	course.UpdatedAt = time.Now()
+	*existing = *course
+	return nil
+}
+
+func (s *MemoryStorage) DeleteCourse(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.courses[id]; !ok {
+		return errCourseNotFound
+	}
+	for _, section := range s.sections {
+		if section.CourseID == id {
+			return ErrCourseHasSections
+		}
+	}
+	for _, groups := range s.prerequisites {
+		for _, group := range groups {
+			if slices.ContainsFunc(group, func(p Prerequisite) bool { return p.CourseID == id }) {
+				return ErrCourseIsPrerequisite
+			}
+		}
+	}
+
+	delete(s.courses, id)
+	delete(s.prerequisites, id)
+	return nil
+}
+
+func matchesCourseFilter(course *Course, f CourseFilter) bool {
+	if f.Department != "" && !strings.EqualFold(course.Department, f.Department) {
+		return false
+	}
+	if f.Active != nil && course.Active != *f.Active {
+		return false
+	}
+	if f.Query != "" {
+		q := strings.ToLower(f.Query)
+		if !strings.Contains(strings.ToLower(course.Code), q) && !strings.Contains(strings.ToLower(course.Title), q) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *MemoryStorage) ListCourses(ctx context.Context, opts CourseListOptions) ([]*Course, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	field := opts.SortBy
+	if !field.ValidForCourses() {
+		field = SortByCode
+	}
+	position := func(course *Course) Cursor {
+		return Cursor{Value: course.SortValue(field), ID: course.ID}
+	}
+
+	var all []*Course
+	for _, course := range s.courses {
+		if matchesCourseFilter(course, opts.Filter) {
+			copied := *course
+			all = append(all, &copied)
+		}
+	}
+
+	return sortAndPage(all, position, opts.SortDesc, opts.Limit, opts.Offset, opts.After, opts.Before), nil
+}
+
+func (s *MemoryStorage) CountCourses(ctx context.Context, filter CourseFilter) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	total := 0
+	for _, course := range s.courses {
+		if matchesCourseFilter(course, filter) {
+			total++
+		}
+	}
+
+	return total, nil
+}
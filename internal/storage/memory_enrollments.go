@@ -0,0 +1,295 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+func (s *MemoryStorage) CreateTerm(ctx context.Context, term *Term) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.terms {
+		if existing.Code == term.Code {
+			return fmt.Errorf("failed to create term: %w", ErrDuplicateTermCode)
+		}
+	}
+
+	term.ID = s.nextTermID
+	term.CreatedAt = time.Now()
+	s.nextTermID++
+
+	stored := *term
+	s.terms[stored.ID] = &stored
+	return nil
+}
+
+func (s *MemoryStorage) ListTerms(ctx context.Context) ([]*Term, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	terms := []*Term{}
+	for _, term := range s.terms {
+		copied := *term
+		terms = append(terms, &copied)
+	}
+
+	// Same order as the SQL backends, latest start first
+	slices.SortFunc(terms, func(a, b *Term) int {
+		if c := b.StartsOn.Compare(a.StartsOn); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+	return terms, nil
+}
+
+func (s *MemoryStorage) CreateSection(ctx context.Context, section *Section) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.courses[section.CourseID]; !ok {
+		return errCourseNotFound
+	}
+	if _, ok := s.terms[section.TermID]; !ok {
+		return errTermNotFound
+	}
+	for _, existing := range s.sections {
+		if existing.CourseID == section.CourseID && existing.TermID == section.TermID && existing.Code == section.Code {
+			return fmt.Errorf("failed to create section: %w", ErrDuplicateSectionCode)
+		}
+	}
+
+	section.ID = s.nextSectionID
+	section.CreatedAt = time.Now()
+	s.nextSectionID++
+
+	stored := Section{
+		ID:        section.ID,
+		CourseID:  section.CourseID,
+		TermID:    section.TermID,
+		Code:      section.Code,
+		Capacity:  section.Capacity,
+		CreatedAt: section.CreatedAt,
+	}
+	s.sections[stored.ID] = &stored
+	s.meetings[stored.ID] = sortMeetings(section.Meetings)
+	return nil
+}
+
+// sectionView returns a copy of section with the fields the SQL backends
+// join in, the caller holds the lock.
+func (s *MemoryStorage) sectionView(section *Section) *Section {
+	view := *section
+	for _, e := range s.enrollments {
+		if e.SectionID != section.ID {
+			continue
+		}
+		switch e.Status {
+		case EnrollmentEnrolled:
+			view.Enrolled++
+		case EnrollmentWaitlisted:
+			view.Waitlisted++
+		}
+	}
+	course := s.courses[section.CourseID]
+	view.CourseCode = course.Code
+	view.CourseTitle = course.Title
+	view.TermCode = s.terms[section.TermID].Code
+	view.Meetings = slices.Clone(s.meetings[section.ID])
+	if view.Meetings == nil {
+		view.Meetings = []Meeting{}
+	}
+	return &view
+}
+
+// enrollmentView returns a copy of e with its waitlist position, the caller
+// holds the lock.
+func (s *MemoryStorage) enrollmentView(e *Enrollment) *Enrollment {
+	view := *e
+	if e.Status == EnrollmentWaitlisted {
+		for _, other := range s.enrollments {
+			if other.SectionID == e.SectionID && other.Status == EnrollmentWaitlisted && other.ID <= e.ID {
+				view.Position++
+			}
+		}
+	}
+	return &view
+}
+
+func (s *MemoryStorage) GetSection(ctx context.Context, id int64) (*Section, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	section, ok := s.sections[id]
+	if !ok {
+		return nil, errSectionNotFound
+	}
+	return s.sectionView(section), nil
+}
+
+func (s *MemoryStorage) ListSections(ctx context.Context, courseID int64) ([]*Section, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sections := []*Section{}
+	for _, section := range s.sections {
+		if section.CourseID == courseID {
+			sections = append(sections, s.sectionView(section))
+		}
+	}
+
+	// Same order as the SQL backends, latest term first
+	slices.SortFunc(sections, func(a, b *Section) int {
+		if c := s.terms[b.TermID].StartsOn.Compare(s.terms[a.TermID].StartsOn); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.Code, b.Code); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return sections, nil
+}
+
+// fillSeats moves students from the front of the waitlist into the free
+// seats of a section, the caller holds the write lock.
+func (s *MemoryStorage) fillSeats(section *Section, now time.Time) []*Enrollment {
+	free := s.sectionView(section).Available()
+
+	var promoted []*Enrollment
+	for _, e := range s.enrollments {
+		if free == 0 {
+			break
+		}
+		if e.SectionID == section.ID && e.Status == EnrollmentWaitlisted {
+			e.Status = EnrollmentEnrolled
+			e.UpdatedAt = now
+			promoted = append(promoted, s.enrollmentView(e))
+			free--
+		}
+	}
+	return promoted
+}
+
+func (s *MemoryStorage) Enroll(ctx context.Context, studentID, sectionID int64, waitlist bool) (*Enrollment, []*Enrollment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	student, ok := s.live(studentID)
+	if !ok {
+		return nil, nil, errStudentNotFound
+	}
+	if student.Status != StatusActive {
+		return nil, nil, ErrStudentNotActive
+	}
+	section, ok := s.sections[sectionID]
+	if !ok {
+		return nil, nil, errSectionNotFound
+	}
+	if !s.courses[section.CourseID].Active {
+		return nil, nil, ErrCourseInactive
+	}
+	for _, e := range s.enrollments {
+		if e.StudentID == studentID && e.SectionID == sectionID && e.Status != EnrollmentDropped {
+			return nil, nil, ErrAlreadyEnrolled
+		}
+	}
+	if reasons := s.checkRequirements(studentID, section); len(reasons) > 0 {
+		return nil, nil, &EnrollmentRejectedError{Reasons: reasons}
+	}
+
+	// like the SQL backends, seats freed without promoting anyone go to the
+	// waitlist first. Whether one is left over is decided up front, there is
+	// no transaction to undo the promotions when the section turns out full.
+	view := s.sectionView(section)
+	seat := EnrollmentEnrolled
+	if view.Available() <= view.Waitlisted {
+		if !waitlist {
+			return nil, nil, ErrSectionFull
+		}
+		seat = EnrollmentWaitlisted
+	}
+
+	now := time.Now()
+	promoted := s.fillSeats(section, now)
+
+	enrollment := &Enrollment{
+		ID:        s.nextEnrollmentID,
+		StudentID: studentID,
+		SectionID: sectionID,
+		Status:    seat,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.nextEnrollmentID++
+	s.enrollments = append(s.enrollments, enrollment)
+
+	return s.enrollmentView(enrollment), promoted, nil
+}
+
+func (s *MemoryStorage) DropEnrollment(ctx context.Context, studentID, enrollmentID int64) (*Enrollment, []*Enrollment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.enrollments, func(e *Enrollment) bool { return e.ID == enrollmentID && e.StudentID == studentID })
+	if i < 0 || s.enrollments[i].Status == EnrollmentDropped {
+		return nil, nil, errEnrollmentNotFound
+	}
+	if s.enrollments[i].Grade != "" {
+		return nil, nil, ErrEnrollmentGraded
+	}
+
+	e := s.enrollments[i]
+	wasEnrolled := e.Status == EnrollmentEnrolled
+	now := time.Now()
+	e.Status = EnrollmentDropped
+	e.UpdatedAt = now
+
+	var promoted []*Enrollment
+	if wasEnrolled {
+		promoted = s.fillSeats(s.sections[e.SectionID], now)
+	}
+	return s.enrollmentView(e), promoted, nil
+}
+
+func (s *MemoryStorage) ListStudentEnrollments(ctx context.Context, studentID int64) ([]*Enrollment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	enrollments := []*Enrollment{}
+	for _, e := range s.enrollments {
+		if e.StudentID == studentID {
+			view := s.enrollmentView(e)
+			view.Section = s.sectionView(s.sections[e.SectionID])
+			enrollments = append(enrollments, view)
+		}
+	}
+	return enrollments, nil
+}
+
+func (s *MemoryStorage) ListSectionRoster(ctx context.Context, sectionID int64) ([]*RosterEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	roster := []*RosterEntry{}
+	for _, status := range []EnrollmentStatus{EnrollmentEnrolled, EnrollmentWaitlisted} {
+		for _, e := range s.enrollments {
+			if e.SectionID != sectionID || e.Status != status {
+				continue
+			}
+			student := s.students[e.StudentID]
+			roster = append(roster, &RosterEntry{
+				Enrollment:     *s.enrollmentView(e),
+				FirstName:      student.FirstName,
+				LastName:       student.LastName,
+				Email:          student.Email,
+				RegistrationNo: student.RegistrationNo,
+			})
+		}
+	}
+	return roster, nil
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+func (s *MemoryStorage) SetPrerequisites(ctx context.Context, courseID int64, groups [][]Prerequisite) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.courses[courseID]; !ok {
+		return errCourseNotFound
+	}
+
+	edges := map[int64][]int64{}
+	for course, existing := range s.prerequisites {
+		if course == courseID {
+			continue
+		}
+		for _, group := range existing {
+			for _, p := range group {
+				edges[course] = append(edges[course], p.CourseID)
+			}
+		}
+	}
+
+	stored := make([][]Prerequisite, len(groups))
+	for i, group := range groups {
+		for _, p := range group {
+			if _, ok := s.courses[p.CourseID]; !ok {
+				return fmt.Errorf("prerequisite %w", errCourseNotFound)
+			}
+			if requiresCourse(edges, p.CourseID, courseID) {
+				return ErrPrerequisiteCycle
+			}
+			stored[i] = append(stored[i], Prerequisite{CourseID: p.CourseID, MinGrade: p.MinGrade})
+		}
+	}
+
+	s.prerequisites[courseID] = stored
+	return nil
+}
+
+// prerequisiteView returns the prerequisites of a course with their course
+// codes, the caller holds the lock.
+func (s *MemoryStorage) prerequisiteView(courseID int64) [][]Prerequisite {
+	groups := [][]Prerequisite{}
+	for _, group := range s.prerequisites[courseID] {
+		view := make([]Prerequisite, len(group))
+		for i, p := range group {
+			view[i] = p
+			view[i].CourseCode = s.courses[p.CourseID].Code
+		}
+		// Same order as the SQL backends
+		slices.SortFunc(view, func(a, b Prerequisite) int { return cmp.Compare(a.CourseCode, b.CourseCode) })
+		groups = append(groups, view)
+	}
+	return groups
+}
+
+func (s *MemoryStorage) ListPrerequisites(ctx context.Context, courseID int64) ([][]Prerequisite, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.prerequisiteView(courseID), nil
+}
+
+func (s *MemoryStorage) SetSectionMeetings(ctx context.Context, sectionID int64, meetings []Meeting) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sections[sectionID]; !ok {
+		return errSectionNotFound
+	}
+
+	s.meetings[sectionID] = sortMeetings(meetings)
+	return nil
+}
+
+// sortMeetings returns a copy of meetings in the order the SQL backends
+// read them, by day of the week and then time.
+func sortMeetings(meetings []Meeting) []Meeting {
+	sorted := slices.Clone(meetings)
+	slices.SortStableFunc(sorted, func(a, b Meeting) int {
+		if c := cmp.Compare(a.Weekday, b.Weekday); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.StartsAt, b.StartsAt)
+	})
+	return sorted
+}
+
+// checkRequirements runs the prerequisite and schedule checks of enrolling a
+// student in section, the caller holds the lock.
+func (s *MemoryStorage) checkRequirements(studentID int64, section *Section) []RejectionReason {
+	var reasons []RejectionReason
+
+	if groups := s.prerequisiteView(section.CourseID); len(groups) > 0 {
+		courseGrades := map[int64]Grade{}
+		for _, e := range s.enrollments {
+			if e.StudentID == studentID && e.Grade != "" {
+				bestGrades(courseGrades, s.sections[e.SectionID].CourseID, e.Grade)
+			}
+		}
+		reasons = append(reasons, unmetPrerequisites(groups, courseGrades)...)
+	}
+
+	meetings := s.meetings[section.ID]
+	if len(meetings) == 0 {
+		return reasons
+	}
+
+	term := s.terms[section.TermID]
+	var taken []*Section
+	for _, e := range s.enrollments {
+		if e.StudentID != studentID || e.Status == EnrollmentDropped || e.Grade != "" {
+			continue
+		}
+		other := s.sections[e.SectionID]
+		if t := s.terms[other.TermID]; !t.StartsOn.After(term.EndsOn) && !t.EndsOn.Before(term.StartsOn) {
+			taken = append(taken, s.sectionView(other))
+		}
+	}
+	return append(reasons, scheduleConflicts(meetings, taken)...)
+}
+
+func (s *MemoryStorage) GradeEnrollment(ctx context.Context, studentID, enrollmentID int64, grade Grade) (*Enrollment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.enrollments, func(e *Enrollment) bool { return e.ID == enrollmentID && e.StudentID == studentID })
+	if i < 0 {
+		return nil, errEnrollmentNotFound
+	}
+
+	e := s.enrollments[i]
+	if e.Status != EnrollmentEnrolled {
+		return nil, ErrNotGradable
+	}
+	e.Grade = grade
+	e.UpdatedAt = time.Now()
+
+	return s.enrollmentView(e), nil
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+func (s *MemoryStorage) CreateRefreshToken(ctx context.Context, token *RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.students[token.StudentID]; !ok {
+		return errStudentNotFound
+	}
+	if _, ok := s.refreshTokens[token.TokenHash]; ok {
+		return ErrConflict
+	}
+
+	s.nextTokenID++
+	token.ID = s.nextTokenID
+	token.CreatedAt = time.Now()
+
+	stored := *token
+	s.refreshTokens[token.TokenHash] = &stored
+	return nil
+}
+
+func (s *MemoryStorage) GetRefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	token, ok := s.refreshTokens[tokenHash]
+	if !ok {
+		return nil, errRefreshTokenNotFound
+	}
+
+	found := *token
+	return &found, nil
+}
+
+func (s *MemoryStorage) RevokeRefreshToken(ctx context.Context, tokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.refreshTokens[tokenHash]
+	if !ok || token.RevokedAt != nil {
+		return errRefreshTokenNotFound
+	}
+
+	now := time.Now()
+	token.RevokedAt = &now
+	return nil
+}
+
+func (s *MemoryStorage) RevokeStudentRefreshTokens(ctx context.Context, studentID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range s.refreshTokens {
+		if token.StudentID == studentID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	if student, ok := s.students[studentID]; ok {
+		student.TokenGeneration++
+	}
+	return nil
+}
+
+func (s *MemoryStorage) CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.students[token.StudentID]; !ok {
+		return errStudentNotFound
+	}
+	if _, ok := s.resetTokens[token.TokenHash]; ok {
+		return ErrConflict
+	}
+
+	s.nextTokenID++
+	token.ID = s.nextTokenID
+	token.CreatedAt = time.Now()
+
+	stored := *token
+	s.resetTokens[token.TokenHash] = &stored
+	return nil
+}
+
+func (s *MemoryStorage) ResetPassword(ctx context.Context, tokenHash string, passwordHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	token, ok := s.resetTokens[tokenHash]
+	if !ok || token.UsedAt != nil || !token.ExpiresAt.After(now) {
+		return errResetTokenNotFound
+	}
+
+	student, ok := s.live(token.StudentID)
+	if !ok {
+		return errResetTokenNotFound
+	}
+
+	before := *student
+	student.Password = passwordHash
+	student.UpdatedAt = now
+	student.Version++
+	student.TokenGeneration++
+	s.recordAudit(ctx, AuditUpdate, &before, student, now)
+
+	for _, t := range s.resetTokens {
+		if t.StudentID == student.ID && t.UsedAt == nil {
+			t.UsedAt = &now
+		}
+	}
+	for _, t := range s.refreshTokens {
+		if t.StudentID == student.ID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+		}
+	}
+
+	return nil
+}
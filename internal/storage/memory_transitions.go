@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+func (s *MemoryStorage) TransitionStudent(ctx context.Context, id int64, from, to StudentStatus, reason string) (*Student, *StatusTransition, error) {
+	if !from.CanTransitionTo(to) {
+		return nil, nil, ErrInvalidTransition
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.live(id)
+	if !ok {
+		return nil, nil, errStudentNotFound
+	}
+	if existing.Status != from {
+		return nil, nil, ErrStatusMismatch
+	}
+
+	before := *existing
+	existing.Status = to
+	existing.UpdatedAt = time.Now()
+	existing.Version++
+	s.recordAudit(ctx, AuditTransition, &before, existing, existing.UpdatedAt)
+
+	transition := newStatusTransition(ctx, id, from, to, reason, existing.UpdatedAt)
+	transition.ID = int64(len(s.transitions)) + 1
+	s.transitions = append(s.transitions, transition)
+
+	updated := *existing
+	recorded := *transition
+	return &updated, &recorded, nil
+}
+
+func (s *MemoryStorage) ListStatusTransitions(ctx context.Context, studentID int64) ([]*StatusTransition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	transitions := []*StatusTransition{}
+	for _, transition := range s.transitions {
+		if transition.StudentID == studentID {
+			copied := *transition
+			transitions = append(transitions, &copied)
+		}
+	}
+	return transitions, nil
+}
@@ -0,0 +1,10 @@
+-- the full UNIQUE constraints can't hold trashed duplicates, so the trash is emptied
+DELETE FROM students WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_students_deleted_at;
+DROP INDEX IF EXISTS students_registration_no_live_key;
+DROP INDEX IF EXISTS students_email_live_key;
+ALTER TABLE students ADD CONSTRAINT students_email_key UNIQUE (email);
+ALTER TABLE students ADD CONSTRAINT students_registration_no_key UNIQUE (registration_no);
+
+ALTER TABLE students DROP COLUMN deleted_at;
@@ -0,0 +1,9 @@
+ALTER TABLE students ADD COLUMN deleted_at TIMESTAMP;
+
+-- email and registration_no only have to be unique among live students
+ALTER TABLE students DROP CONSTRAINT IF EXISTS students_email_key;
+ALTER TABLE students DROP CONSTRAINT IF EXISTS students_registration_no_key;
+CREATE UNIQUE INDEX IF NOT EXISTS students_email_live_key ON students(email) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS students_registration_no_live_key ON students(registration_no) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_students_deleted_at ON students(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,2 @@
+-- bumped to invalidate every access token issued to the student
+ALTER TABLE students ADD COLUMN token_generation BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE students ALTER COLUMN phone_number TYPE BIGINT
+	USING COALESCE(NULLIF(regexp_replace(phone_number, '[^0-9]', '', 'g'), ''), '0')::BIGINT;
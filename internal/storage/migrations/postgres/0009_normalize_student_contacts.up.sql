@@ -0,0 +1,5 @@
+-- phone numbers are E.164 strings now, the digits of existing rows are kept as they are
+ALTER TABLE students ALTER COLUMN phone_number TYPE TEXT USING phone_number::TEXT;
+
+-- emails are compared lower-cased, addresses that only differ in case fail here
+UPDATE students SET email = LOWER(TRIM(email));
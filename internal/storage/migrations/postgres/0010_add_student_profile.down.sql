@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS student_guardians;
+DROP INDEX IF EXISTS idx_students_status;
+
+ALTER TABLE students
+	DROP COLUMN date_of_birth,
+	DROP COLUMN gender,
+	DROP COLUMN address_line1,
+	DROP COLUMN address_line2,
+	DROP COLUMN address_city,
+	DROP COLUMN address_region,
+	DROP COLUMN address_postal_code,
+	DROP COLUMN address_country,
+	DROP COLUMN admission_date,
+	DROP COLUMN status;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS enrollments;
+DROP TABLE IF EXISTS course_sections;
+DROP TABLE IF EXISTS terms;
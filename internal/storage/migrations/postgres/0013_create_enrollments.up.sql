@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS terms (
+	id SERIAL PRIMARY KEY,
+	code VARCHAR(20) UNIQUE NOT NULL,
+	name VARCHAR(100) NOT NULL,
+	starts_on DATE NOT NULL,
+	ends_on DATE NOT NULL CHECK (ends_on > starts_on),
+	created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- A course can only be deleted once it has no sections
+CREATE TABLE IF NOT EXISTS course_sections (
+	id SERIAL PRIMARY KEY,
+	course_id INTEGER NOT NULL REFERENCES courses(id),
+	term_id INTEGER NOT NULL REFERENCES terms(id),
+	code VARCHAR(20) NOT NULL,
+	capacity INTEGER NOT NULL CHECK (capacity > 0),
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	UNIQUE (course_id, term_id, code)
+);
+
+CREATE INDEX IF NOT EXISTS idx_course_sections_term_id ON course_sections(term_id);
+
+-- Dropped enrollments are kept as history. The waitlist is in id order.
+CREATE TABLE IF NOT EXISTS enrollments (
+	id BIGSERIAL PRIMARY KEY,
+	student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+	section_id INTEGER NOT NULL REFERENCES course_sections(id),
+	status VARCHAR(20) NOT NULL CHECK (status IN ('enrolled', 'waitlisted', 'dropped')),
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- one seat or waitlist place per student and section
+CREATE UNIQUE INDEX IF NOT EXISTS idx_enrollments_student_section ON enrollments(student_id, section_id) WHERE status <> 'dropped';
+CREATE INDEX IF NOT EXISTS idx_enrollments_section_status ON enrollments(section_id, status);
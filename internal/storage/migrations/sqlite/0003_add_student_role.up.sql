@@ -0,0 +1,2 @@
+ALTER TABLE students ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'student'
+	CHECK (role IN ('admin', 'staff', 'student'));
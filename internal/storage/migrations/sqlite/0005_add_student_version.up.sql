@@ -0,0 +1,2 @@
+-- bumped on every write, used for ETag / If-Match
+ALTER TABLE students ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
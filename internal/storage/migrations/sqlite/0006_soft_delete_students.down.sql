@@ -0,0 +1,30 @@
+-- the full UNIQUE constraints can't hold trashed duplicates, so the trash is emptied
+DELETE FROM refresh_tokens WHERE student_id IN (SELECT id FROM students WHERE deleted_at IS NOT NULL);
+DELETE FROM password_reset_tokens WHERE student_id IN (SELECT id FROM students WHERE deleted_at IS NOT NULL);
+DELETE FROM students WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE students_old (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	first_name VARCHAR(100) NOT NULL,
+	last_name VARCHAR(100) NOT NULL,
+	registration_no INTEGER UNIQUE NOT NULL,
+	phone_number BIGINT NOT NULL,
+	email VARCHAR(255) UNIQUE NOT NULL,
+	password VARCHAR(255) NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	role VARCHAR(20) NOT NULL DEFAULT 'student'
+		CHECK (role IN ('admin', 'staff', 'student')),
+	version INTEGER NOT NULL DEFAULT 1
+);
+
+INSERT INTO students_old (id, first_name, last_name, registration_no, phone_number, email, password, created_at, updated_at, role, version)
+SELECT id, first_name, last_name, registration_no, phone_number, email, password, created_at, updated_at, role, version FROM students;
+
+UPDATE sqlite_sequence SET seq = (SELECT seq FROM sqlite_sequence WHERE name = 'students') WHERE name = 'students_old';
+
+DROP TABLE students;
+ALTER TABLE students_old RENAME TO students;
+
+CREATE INDEX IF NOT EXISTS idx_students_email ON students(email);
+CREATE INDEX IF NOT EXISTS idx_students_registration_no ON students(registration_no);
@@ -0,0 +1,35 @@
+-- SQLite can't drop the inline UNIQUE constraints, so the table is rebuilt
+-- without them. Foreign keys are off while migrations run.
+CREATE TABLE students_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	first_name VARCHAR(100) NOT NULL,
+	last_name VARCHAR(100) NOT NULL,
+	registration_no INTEGER NOT NULL,
+	phone_number BIGINT NOT NULL,
+	email VARCHAR(255) NOT NULL,
+	password VARCHAR(255) NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	role VARCHAR(20) NOT NULL DEFAULT 'student'
+		CHECK (role IN ('admin', 'staff', 'student')),
+	version INTEGER NOT NULL DEFAULT 1,
+	deleted_at DATETIME
+);
+
+INSERT INTO students_new (id, first_name, last_name, registration_no, phone_number, email, password, created_at, updated_at, role, version)
+SELECT id, first_name, last_name, registration_no, phone_number, email, password, created_at, updated_at, role, version FROM students;
+
+-- keep AUTOINCREMENT from handing out ids of already deleted students again
+UPDATE sqlite_sequence SET seq = (SELECT seq FROM sqlite_sequence WHERE name = 'students') WHERE name = 'students_new';
+
+DROP TABLE students;
+ALTER TABLE students_new RENAME TO students;
+
+CREATE INDEX IF NOT EXISTS idx_students_email ON students(email);
+CREATE INDEX IF NOT EXISTS idx_students_registration_no ON students(registration_no);
+
+-- email and registration_no only have to be unique among live students
+CREATE UNIQUE INDEX IF NOT EXISTS students_email_live_key ON students(email) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS students_registration_no_live_key ON students(registration_no) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_students_deleted_at ON students(deleted_at) WHERE deleted_at IS NOT NULL;
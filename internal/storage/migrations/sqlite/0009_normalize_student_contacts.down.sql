@@ -0,0 +1,31 @@
+CREATE TABLE students_old (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	first_name VARCHAR(100) NOT NULL,
+	last_name VARCHAR(100) NOT NULL,
+	registration_no INTEGER NOT NULL,
+	phone_number BIGINT NOT NULL,
+	email VARCHAR(255) NOT NULL,
+	password VARCHAR(255) NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	role VARCHAR(20) NOT NULL DEFAULT 'student'
+		CHECK (role IN ('admin', 'staff', 'student')),
+	version INTEGER NOT NULL DEFAULT 1,
+	deleted_at DATETIME,
+	token_generation INTEGER NOT NULL DEFAULT 0
+);
+
+-- the + of E.164 numbers is dropped, the digits are kept
+INSERT INTO students_old (id, first_name, last_name, registration_no, phone_number, email, password, created_at, updated_at, role, version, deleted_at, token_generation)
+SELECT id, first_name, last_name, registration_no, CAST(REPLACE(phone_number, '+', '') AS INTEGER), email, password, created_at, updated_at, role, version, deleted_at, token_generation FROM students;
+
+UPDATE sqlite_sequence SET seq = (SELECT seq FROM sqlite_sequence WHERE name = 'students') WHERE name = 'students_old';
+
+DROP TABLE students;
+ALTER TABLE students_old RENAME TO students;
+
+CREATE INDEX IF NOT EXISTS idx_students_email ON students(email);
+CREATE INDEX IF NOT EXISTS idx_students_registration_no ON students(registration_no);
+CREATE UNIQUE INDEX IF NOT EXISTS students_email_live_key ON students(email) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS students_registration_no_live_key ON students(registration_no) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_students_deleted_at ON students(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,34 @@
+-- phone numbers are E.164 strings now. A BIGINT column would turn "+1555..."
+-- back into a number, so the table is rebuilt with a TEXT column. Foreign
+-- keys are off while migrations run.
+CREATE TABLE students_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	first_name VARCHAR(100) NOT NULL,
+	last_name VARCHAR(100) NOT NULL,
+	registration_no INTEGER NOT NULL,
+	phone_number TEXT NOT NULL,
+	email VARCHAR(255) NOT NULL,
+	password VARCHAR(255) NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	role VARCHAR(20) NOT NULL DEFAULT 'student'
+		CHECK (role IN ('admin', 'staff', 'student')),
+	version INTEGER NOT NULL DEFAULT 1,
+	deleted_at DATETIME,
+	token_generation INTEGER NOT NULL DEFAULT 0
+);
+
+-- emails are compared lower-cased, addresses that only differ in case fail here
+INSERT INTO students_new (id, first_name, last_name, registration_no, phone_number, email, password, created_at, updated_at, role, version, deleted_at, token_generation)
+SELECT id, first_name, last_name, registration_no, CAST(phone_number AS TEXT), LOWER(TRIM(email)), password, created_at, updated_at, role, version, deleted_at, token_generation FROM students;
+
+UPDATE sqlite_sequence SET seq = (SELECT seq FROM sqlite_sequence WHERE name = 'students') WHERE name = 'students_new';
+
+DROP TABLE students;
+ALTER TABLE students_new RENAME TO students;
+
+CREATE INDEX IF NOT EXISTS idx_students_email ON students(email);
+CREATE INDEX IF NOT EXISTS idx_students_registration_no ON students(registration_no);
+CREATE UNIQUE INDEX IF NOT EXISTS students_email_live_key ON students(email) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS students_registration_no_live_key ON students(registration_no) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_students_deleted_at ON students(deleted_at) WHERE deleted_at IS NOT NULL;
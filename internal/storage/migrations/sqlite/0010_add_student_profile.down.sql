@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS student_guardians;
+DROP INDEX IF EXISTS idx_students_status;
+
+ALTER TABLE students DROP COLUMN date_of_birth;
+ALTER TABLE students DROP COLUMN gender;
+ALTER TABLE students DROP COLUMN address_line1;
+ALTER TABLE students DROP COLUMN address_line2;
+ALTER TABLE students DROP COLUMN address_city;
+ALTER TABLE students DROP COLUMN address_region;
+ALTER TABLE students DROP COLUMN address_postal_code;
+ALTER TABLE students DROP COLUMN address_country;
+ALTER TABLE students DROP COLUMN admission_date;
+ALTER TABLE students DROP COLUMN status;
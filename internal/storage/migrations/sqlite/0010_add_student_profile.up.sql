@@ -0,0 +1,29 @@
+ALTER TABLE students ADD COLUMN date_of_birth DATE;
+ALTER TABLE students ADD COLUMN gender VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE students ADD COLUMN address_line1 VARCHAR(200) NOT NULL DEFAULT '';
+ALTER TABLE students ADD COLUMN address_line2 VARCHAR(200) NOT NULL DEFAULT '';
+ALTER TABLE students ADD COLUMN address_city VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE students ADD COLUMN address_region VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE students ADD COLUMN address_postal_code VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE students ADD COLUMN address_country VARCHAR(2) NOT NULL DEFAULT '';
+ALTER TABLE students ADD COLUMN admission_date DATE;
+-- students from before statuses existed are taken to be enrolled, new ones
+-- start out as applicants
+ALTER TABLE students ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active'
+	CHECK (status IN ('applicant', 'active', 'suspended', 'graduated', 'withdrawn'));
+
+CREATE INDEX IF NOT EXISTS idx_students_status ON students(status);
+
+CREATE TABLE IF NOT EXISTS student_guardians (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+	name VARCHAR(200) NOT NULL,
+	relationship VARCHAR(50) NOT NULL,
+	phone_number TEXT NOT NULL,
+	email VARCHAR(255) NOT NULL DEFAULT '',
+	emergency_contact BOOLEAN NOT NULL DEFAULT FALSE,
+	-- keeps the guardians in the order they were given
+	position INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_student_guardians_student_id ON student_guardians(student_id);
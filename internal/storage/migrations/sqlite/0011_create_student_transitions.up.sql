@@ -0,0 +1,16 @@
+-- History of the status changes of each student, made through the
+-- transitions endpoint. It goes away with the student, the audit log keeps
+-- a record of the changes after that.
+CREATE TABLE IF NOT EXISTS student_transitions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+	from_status VARCHAR(20) NOT NULL,
+	to_status VARCHAR(20) NOT NULL,
+	reason TEXT NOT NULL,
+	actor_id INTEGER,
+	actor VARCHAR(255) NOT NULL,
+	request_id VARCHAR(128) NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_student_transitions_student_id ON student_transitions(student_id);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS courses (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	code VARCHAR(20) UNIQUE NOT NULL,
+	title VARCHAR(200) NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	credits INTEGER NOT NULL DEFAULT 0,
+	department VARCHAR(100) NOT NULL DEFAULT '',
+	-- the most students a section of the course can take
+	capacity INTEGER NOT NULL CHECK (capacity > 0),
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_courses_department ON courses(department);
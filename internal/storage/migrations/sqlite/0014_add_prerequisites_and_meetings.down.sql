@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS section_meetings;
+DROP TABLE IF EXISTS course_prerequisites;
+
+ALTER TABLE enrollments DROP COLUMN grade;
@@ -0,0 +1,26 @@
+-- '' until the student is graded
+ALTER TABLE enrollments ADD COLUMN grade VARCHAR(2) NOT NULL DEFAULT '';
+
+-- Every group of a course must be met, a group by passing any one of its
+-- courses with at least min_grade
+CREATE TABLE IF NOT EXISTS course_prerequisites (
+	course_id INTEGER NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+	group_no INTEGER NOT NULL,
+	prerequisite_id INTEGER NOT NULL REFERENCES courses(id),
+	min_grade VARCHAR(2) NOT NULL,
+	PRIMARY KEY (course_id, group_no, prerequisite_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_course_prerequisites_prerequisite_id ON course_prerequisites(prerequisite_id);
+
+-- weekday counts from 0 for Sunday, times are minutes after midnight
+CREATE TABLE IF NOT EXISTS section_meetings (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	section_id INTEGER NOT NULL REFERENCES course_sections(id) ON DELETE CASCADE,
+	weekday INTEGER NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+	starts_at INTEGER NOT NULL CHECK (starts_at >= 0),
+	ends_at INTEGER NOT NULL CHECK (ends_at > starts_at AND ends_at <= 1440),
+	room VARCHAR(50) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_section_meetings_section_id ON section_meetings(section_id);
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProfileAndGuardiansRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	born := time.Date(2008, 3, 14, 0, 0, 0, 0, time.UTC)
+
+	for name, store := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			student := &Student{
+				FirstName:      "Ada",
+				LastName:       "Lovelace",
+				RegistrationNo: 42,
+				PhoneNumber:    "+15550100",
+				Email:          "ada@example.com",
+				Password:       "hash",
+				Profile: Profile{
+					DateOfBirth: &born,
+					Gender:      "female",
+					Address:     Address{Line1: "1 Main St", City: "London", Country: "GB"},
+				},
+				Guardians: []Guardian{
+					{Name: "Anne", Relationship: "mother", PhoneNumber: "+15550101", EmergencyContact: true},
+					{Name: "George", Relationship: "father", PhoneNumber: "+15550102"},
+				},
+			}
+			if err := store.CreateStudent(ctx, student); err != nil {
+				t.Fatal(err)
+			}
+			if student.Status != StatusApplicant {
+				t.Errorf("new student has status %q, want %q", student.Status, StatusApplicant)
+			}
+
+			got, err := store.GetStudentByID(ctx, student.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.DateOfBirth == nil || !got.DateOfBirth.Equal(born) {
+				t.Errorf("date of birth is %v, want %v", got.DateOfBirth, born)
+			}
+			if got.Address != student.Address || got.Gender != "female" || got.AdmissionDate != nil {
+				t.Errorf("profile is %+v, want %+v", got.Profile, student.Profile)
+			}
+
+			guardians, err := store.ListGuardians(ctx, student.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(guardians, student.Guardians) {
+				t.Errorf("guardians are %+v, want %+v", guardians, student.Guardians)
+			}
+
+			// Replacing the guardians drops the ones left out
+			replaced := []Guardian{{Name: "Charles", Relationship: "guardian", PhoneNumber: "+15550103"}}
+			if _, err := store.UpdateStudent(ctx, student.ID, StudentUpdate{Guardians: &replaced}); err != nil {
+				t.Fatal(err)
+			}
+			guardians, err = store.ListGuardians(ctx, student.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(guardians) != 1 || guardians[0].Name != "Charles" {
+				t.Errorf("guardians are %+v after the update, want only Charles", guardians)
+			}
+
+			if _, _, err := store.TransitionStudent(ctx, student.ID, StatusApplicant, StatusActive, "admitted"); err != nil {
+				t.Fatal(err)
+			}
+			active, err := store.CountStudents(ctx, StudentFilter{Status: StatusActive})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if active != 1 {
+				t.Errorf("%d active students, want 1", active)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const studentColumns = "id, first_name, last_name, registration_no, phone_number, email, password, role, created_at, updated_at, version, deleted_at, token_generation, " + profileColumns + ", status"
+
+// profileColumns are the columns of Profile, in the order profileArgs returns them
+const profileColumns = "date_of_birth, gender, address_line1, address_line2, address_city, address_region, address_postal_code, address_country, admission_date"
+
+// profileArgs returns the values of profileColumns.
+func profileArgs(p Profile) []any {
+	return []any{
+		p.DateOfBirth,
+		p.Gender,
+		p.Address.Line1,
+		p.Address.Line2,
+		p.Address.City,
+		p.Address.Region,
+		p.Address.PostalCode,
+		p.Address.Country,
+		p.AdmissionDate,
+	}
+}
+
+// scanStudent reads a row selected with studentColumns.
+func scanStudent(row interface{ Scan(dest ...any) error }) (*Student, error) {
+	var student Student
+	err := row.Scan(
+		&student.ID,
+		&student.FirstName,
+		&student.LastName,
+		&student.RegistrationNo,
+		&student.PhoneNumber,
+		&student.Email,
+		&student.Password,
+		&student.Role,
+		&student.CreatedAt,
+		&student.UpdatedAt,
+		&student.Version,
+		&student.DeletedAt,
+		&student.TokenGeneration,
+		&student.DateOfBirth,
+		&student.Gender,
+		&student.Address.Line1,
+		&student.Address.Line2,
+		&student.Address.City,
+		&student.Address.Region,
+		&student.Address.PostalCode,
+		&student.Address.Country,
+		&student.AdmissionDate,
+		&student.Status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &student, nil
+}
+
+func postgresBind(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// sqliteBind uses numbered parameters so a placeholder can be repeated.
+func sqliteBind(n int) string {
+	return "?" + strconv.Itoa(n)
+}
+
+// sqlDialect is what the queries shared by the SQL backends need to know
+// about them.
+type sqlDialect struct {
+	bind       func(n int) string
+	fromDriver func(error) error
+	// forUpdate locks the selected rows until the transaction ends, sqlite
+	// doesn't need it with its single connection
+	forUpdate string
+}
+
+var (
+	postgresDialect = sqlDialect{bind: postgresBind, fromDriver: fromPostgres, forUpdate: " FOR UPDATE"}
+	sqliteDialect   = sqlDialect{bind: sqliteBind, fromDriver: fromSQLite}
+)
+
+// queryBuilder collects WHERE conditions and their arguments. bind returns
+// the placeholder for the n-th argument in the driver's syntax.
+type queryBuilder struct {
+	bind  func(n int) string
+	where []string
+	args  []any
+}
+
+// arg adds an argument and returns its placeholder.
+func (q *queryBuilder) arg(v any) string {
+	q.args = append(q.args, v)
+	return q.bind(len(q.args))
+}
+
+func (q *queryBuilder) whereClause() string {
+	if len(q.where) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(q.where, " AND ")
+}
+
+// escapeLike escapes the LIKE wildcards in s, for use with ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (q *queryBuilder) addFilter(f StudentFilter) {
+	if f.Deleted {
+		q.where = append(q.where, "deleted_at IS NOT NULL")
+	} else {
+		q.where = append(q.where, "deleted_at IS NULL")
+	}
+	if f.NamePrefix != "" {
+		p := q.arg(strings.ToLower(escapeLike(f.NamePrefix)) + "%")
+		q.where = append(q.where, fmt.Sprintf(`(LOWER(first_name) LIKE %s ESCAPE '\' OR LOWER(last_name) LIKE %s ESCAPE '\')`, p, p))
+	}
+	if f.Status != "" {
+		q.where = append(q.where, "status = "+q.arg(f.Status))
+	}
+	if f.RegistrationNoMin != nil {
+		q.where = append(q.where, "registration_no >= "+q.arg(*f.RegistrationNoMin))
+	}
+	if f.RegistrationNoMax != nil {
+		q.where = append(q.where, "registration_no <= "+q.arg(*f.RegistrationNoMax))
+	}
+	if f.CreatedFrom != nil {
+		q.where = append(q.where, "created_at >= "+q.arg(*f.CreatedFrom))
+	}
+	if f.CreatedTo != nil {
+		q.where = append(q.where, "created_at <= "+q.arg(*f.CreatedTo))
+	}
+	if f.UpdatedFrom != nil {
+		q.where = append(q.where, "updated_at >= "+q.arg(*f.UpdatedFrom))
+	}
+	if f.UpdatedTo != nil {
+		q.where = append(q.where, "updated_at <= "+q.arg(*f.UpdatedTo))
+	}
+	if f.Query != "" {
+		p := q.arg("%" + strings.ToLower(escapeLike(f.Query)) + "%")
+		q.where = append(q.where, fmt.Sprintf(
+			`(LOWER(first_name) LIKE %s ESCAPE '\' OR LOWER(last_name) LIKE %s ESCAPE '\' OR LOWER(email) LIKE %s ESCAPE '\')`,
+			p, p, p,
+		))
+	}
+}
+
+// buildListQuery builds the SELECT for ListStudents. When reversed is true
+// the rows come back in the opposite order and the caller has to flip them.
+// A zero Limit selects every row.
+func buildListQuery(opts ListOptions, bind func(n int) string) (query string, args []any, reversed bool) {
+	q := &queryBuilder{bind: bind}
+	q.addFilter(opts.Filter)
+
+	column := string(opts.SortBy)
+	if !opts.SortBy.Valid() {
+		column = string(SortByCreatedAt)
+	}
+
+	query, reversed = q.orderAndPage("SELECT "+studentColumns+" FROM students", column, opts.SortDesc, opts.After, opts.Before, opts.Limit, opts.Offset)
+	return query, q.args, reversed
+}
+
+// orderAndPage completes selectFrom into the query for one page of a list
+// sorted by column, ties broken by id. When reversed is true the rows come
+// back in the opposite order and the caller has to flip them. A zero limit
+// selects every row.
+func (q *queryBuilder) orderAndPage(selectFrom, column string, desc bool, after, before *Cursor, limit, offset int) (query string, reversed bool) {
+	// Paging backwards walks the list in the opposite direction
+	if before != nil {
+		desc = !desc
+		reversed = true
+	}
+
+	cursor := after
+	if cursor == nil {
+		cursor = before
+	}
+	if cursor != nil {
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		q.where = append(q.where, fmt.Sprintf("(%s, id) %s (%s, %s)", column, op, q.arg(cursor.Value), q.arg(cursor.ID)))
+	}
+
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+
+	var b strings.Builder
+	b.WriteString(selectFrom)
+	b.WriteString(q.whereClause())
+	fmt.Fprintf(&b, " ORDER BY %s %s, id %s", column, direction, direction)
+	if limit > 0 {
+		fmt.Fprintf(&b, " LIMIT %s", q.arg(limit))
+		if cursor == nil {
+			fmt.Fprintf(&b, " OFFSET %s", q.arg(offset))
+		}
+	}
+
+	return b.String(), reversed
+}
+
+// buildUpdateQuery builds an UPDATE ... RETURNING that only sets the columns
+// present in update, plus updated_at and version.
+func buildUpdateQuery(id int64, update StudentUpdate, now time.Time, bind func(n int) string) (query string, args []any) {
+	q := &queryBuilder{bind: bind}
+
+	var set []string
+	if update.FirstName != nil {
+		set = append(set, "first_name = "+q.arg(*update.FirstName))
+	}
+	if update.LastName != nil {
+		set = append(set, "last_name = "+q.arg(*update.LastName))
+	}
+	if update.RegistrationNo != nil {
+		set = append(set, "registration_no = "+q.arg(*update.RegistrationNo))
+	}
+	if update.PhoneNumber != nil {
+		set = append(set, "phone_number = "+q.arg(*update.PhoneNumber))
+	}
+	if update.Email != nil {
+		set = append(set, "email = "+q.arg(*update.Email))
+	}
+	if update.Profile != nil {
+		values := profileArgs(*update.Profile)
+		for i, column := range strings.Split(profileColumns, ", ") {
+			set = append(set, column+" = "+q.arg(values[i]))
+		}
+	}
+	set = append(set, "updated_at = "+q.arg(now), "version = version + 1")
+
+	where := "id = " + q.arg(id) + " AND deleted_at IS NULL"
+	if update.Version != 0 {
+		where += " AND version = " + q.arg(update.Version)
+	}
+
+	query = fmt.Sprintf("UPDATE students SET %s WHERE %s RETURNING %s", strings.Join(set, ", "), where, studentColumns)
+	return query, q.args
+}
+
+func buildCountQuery(filter StudentFilter, bind func(n int) string) (query string, args []any) {
+	q := &queryBuilder{bind: bind}
+	q.addFilter(filter)
+
+	return "SELECT COUNT(*) FROM students" + q.whereClause(), q.args
+}
+
+func reverseStudents(students []*Student) {
+	for i, j := 0, len(students)-1; i < j; i, j = i+1, j-1 {
+		students[i], students[j] = students[j], students[i]
+	}
+}
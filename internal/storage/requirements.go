@@ -0,0 +1,438 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Grade is a letter grade, from A down to F.
+type Grade string
+
+// grades is the grade scale from best to worst.
+var grades = []Grade{"A", "A-", "B+", "B", "B-", "C+", "C", "C-", "D+", "D", "D-", "F"}
+
+// PassingGrade is the lowest grade that passes a course, and the default
+// minimum grade of a prerequisite.
+const PassingGrade Grade = "D-"
+
+func (g Grade) Valid() bool {
+	return slices.Contains(grades, g)
+}
+
+// AtLeast reports whether g is min or better.
+func (g Grade) AtLeast(min Grade) bool {
+	return g.Valid() && slices.Index(grades, g) <= slices.Index(grades, min)
+}
+
+// Prerequisite is a course a student must have passed with at least
+// MinGrade before enrolling in a section of another course.
+type Prerequisite struct {
+	CourseID int64 `db:"prerequisite_id"`
+	MinGrade Grade `db:"min_grade"`
+	// CourseCode is read from the course, it is ignored by SetPrerequisites
+	CourseCode string
+}
+
+// Meeting is a weekly class of a section. The times are minutes after
+// midnight.
+type Meeting struct {
+	Weekday  time.Weekday `db:"weekday"`
+	StartsAt int          `db:"starts_at"`
+	EndsAt   int          `db:"ends_at"`
+	Room     string       `db:"room"`
+}
+
+// Overlaps reports whether m and o are on the same day at overlapping times,
+// a class may start the minute another ends.
+func (m Meeting) Overlaps(o Meeting) bool {
+	return m.Weekday == o.Weekday && m.StartsAt < o.EndsAt && o.StartsAt < m.EndsAt
+}
+
+// The requirements an enrollment can fail, see RejectionReason.
+const (
+	ReasonPrerequisite     = "prerequisite"
+	ReasonScheduleConflict = "schedule_conflict"
+)
+
+// RejectionReason is one requirement an enrollment failed.
+type RejectionReason struct {
+	// Code is ReasonPrerequisite or ReasonScheduleConflict
+	Code string
+	// AnyOf is the prerequisite group that isn't met, passing any one of
+	// its courses meets it
+	AnyOf []Prerequisite
+	// Section is the section the student is already in whose Meeting clashes
+	// with the requested section
+	Section *Section
+	Meeting Meeting
+}
+
+// EnrollmentRejectedError lists every requirement an enrollment failed, it
+// wraps ErrEnrollmentRejected.
+type EnrollmentRejectedError struct {
+	Reasons []RejectionReason
+}
+
+func (e *EnrollmentRejectedError) Error() string {
+	codes := make([]string, len(e.Reasons))
+	for i, reason := range e.Reasons {
+		codes[i] = reason.Code
+	}
+	return fmt.Sprintf("%s: %s", ErrEnrollmentRejected, strings.Join(codes, ", "))
+}
+
+func (e *EnrollmentRejectedError) Unwrap() error {
+	return ErrEnrollmentRejected
+}
+
+// bestGrades keeps the best of the grades a student got in each course.
+func bestGrades(courseGrades map[int64]Grade, courseID int64, grade Grade) {
+	if best, ok := courseGrades[courseID]; !ok || grade.AtLeast(best) {
+		courseGrades[courseID] = grade
+	}
+}
+
+// unmetPrerequisites returns the reasons for the groups that the best
+// grades of a student by course don't meet.
+func unmetPrerequisites(groups [][]Prerequisite, courseGrades map[int64]Grade) []RejectionReason {
+	var reasons []RejectionReason
+	for _, group := range groups {
+		met := slices.ContainsFunc(group, func(p Prerequisite) bool {
+			grade, ok := courseGrades[p.CourseID]
+			return ok && grade.AtLeast(p.MinGrade)
+		})
+		if !met {
+			reasons = append(reasons, RejectionReason{Code: ReasonPrerequisite, AnyOf: group})
+		}
+	}
+	return reasons
+}
+
+// scheduleConflicts returns a reason for each of the sections a student is
+// in that has a class at the same time as one of meetings.
+func scheduleConflicts(meetings []Meeting, sections []*Section) []RejectionReason {
+	var reasons []RejectionReason
+	for _, section := range sections {
+		for _, m := range section.Meetings {
+			if slices.ContainsFunc(meetings, m.Overlaps) {
+				reasons = append(reasons, RejectionReason{Code: ReasonScheduleConflict, Section: section, Meeting: m})
+				break
+			}
+		}
+	}
+	return reasons
+}
+
+// requiresCourse reports whether from, through the prerequisites in edges,
+// requires target.
+func requiresCourse(edges map[int64][]int64, from, target int64) bool {
+	seen := map[int64]bool{}
+	next := []int64{from}
+	for len(next) > 0 {
+		id := next[len(next)-1]
+		next = next[:len(next)-1]
+		if id == target {
+			return true
+		}
+		if !seen[id] {
+			seen[id] = true
+			next = append(next, edges[id]...)
+		}
+	}
+	return false
+}
+
+func setPrerequisites(ctx context.Context, db *sql.DB, d sqlDialect, courseID int64, groups [][]Prerequisite) error {
+	return withTx(ctx, db, func(tx *sql.Tx) error {
+		found, err := exists(ctx, tx, `SELECT 1 FROM courses WHERE id = `+d.bind(1)+d.forUpdate, courseID)
+		if err != nil {
+			return fmt.Errorf("failed to get course: %w", err)
+		}
+		if !found {
+			return errCourseNotFound
+		}
+
+		rows, err := tx.QueryContext(ctx, `SELECT course_id, prerequisite_id FROM course_prerequisites WHERE course_id <> `+d.bind(1), courseID)
+		if err != nil {
+			return fmt.Errorf("failed to list prerequisites: %w", err)
+		}
+		defer rows.Close()
+
+		edges := map[int64][]int64{}
+		for rows.Next() {
+			var course, prerequisite int64
+			if err := rows.Scan(&course, &prerequisite); err != nil {
+				return fmt.Errorf("failed to scan prerequisite: %w", err)
+			}
+			edges[course] = append(edges[course], prerequisite)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating rows: %w", err)
+		}
+
+		for _, group := range groups {
+			for _, p := range group {
+				found, err := exists(ctx, tx, `SELECT 1 FROM courses WHERE id = `+d.bind(1), p.CourseID)
+				if err != nil {
+					return fmt.Errorf("failed to get course: %w", err)
+				}
+				if !found {
+					return fmt.Errorf("prerequisite %w", errCourseNotFound)
+				}
+				if requiresCourse(edges, p.CourseID, courseID) {
+					return ErrPrerequisiteCycle
+				}
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM course_prerequisites WHERE course_id = `+d.bind(1), courseID); err != nil {
+			return fmt.Errorf("failed to delete prerequisites: %w", err)
+		}
+
+		query := fmt.Sprintf(
+			`INSERT INTO course_prerequisites (course_id, group_no, prerequisite_id, min_grade) VALUES (%s, %s, %s, %s)`,
+			d.bind(1), d.bind(2), d.bind(3), d.bind(4),
+		)
+		for i, group := range groups {
+			for _, p := range group {
+				if _, err := tx.ExecContext(ctx, query, courseID, i, p.CourseID, p.MinGrade); err != nil {
+					return fmt.Errorf("failed to insert prerequisite: %w", d.fromDriver(err))
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// queryPrerequisites returns the prerequisite groups of a course, in the
+// order they were given.
+func queryPrerequisites(ctx context.Context, db queryer, d sqlDialect, courseID int64) ([][]Prerequisite, error) {
+	query := `SELECT p.group_no, p.prerequisite_id, p.min_grade, c.code
+		FROM course_prerequisites p JOIN courses c ON c.id = p.prerequisite_id
+		WHERE p.course_id = ` + d.bind(1) + ` ORDER BY p.group_no, c.code`
+	rows, err := db.QueryContext(ctx, query, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prerequisites: %w", err)
+	}
+	defer rows.Close()
+
+	groups := [][]Prerequisite{}
+	last := -1
+	for rows.Next() {
+		var group int
+		var p Prerequisite
+		if err := rows.Scan(&group, &p.CourseID, &p.MinGrade, &p.CourseCode); err != nil {
+			return nil, fmt.Errorf("failed to scan prerequisite: %w", err)
+		}
+		if group != last {
+			groups = append(groups, nil)
+			last = group
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return groups, nil
+}
+
+// insertMeetings adds meetings to a section in tx.
+func insertMeetings(ctx context.Context, tx *sql.Tx, d sqlDialect, sectionID int64, meetings []Meeting) error {
+	query := fmt.Sprintf(
+		`INSERT INTO section_meetings (section_id, weekday, starts_at, ends_at, room) VALUES (%s, %s, %s, %s, %s)`,
+		d.bind(1), d.bind(2), d.bind(3), d.bind(4), d.bind(5),
+	)
+	for _, m := range meetings {
+		if _, err := tx.ExecContext(ctx, query, sectionID, m.Weekday, m.StartsAt, m.EndsAt, m.Room); err != nil {
+			return fmt.Errorf("failed to insert meeting: %w", err)
+		}
+	}
+	return nil
+}
+
+func setSectionMeetings(ctx context.Context, db *sql.DB, d sqlDialect, sectionID int64, meetings []Meeting) error {
+	return withTx(ctx, db, func(tx *sql.Tx) error {
+		// under the lock enroll takes, so a clash is checked against the
+		// meetings before or after the change and not halfway through
+		if _, err := lockSection(ctx, tx, d, sectionID); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM section_meetings WHERE section_id = `+d.bind(1), sectionID); err != nil {
+			return fmt.Errorf("failed to delete meetings: %w", err)
+		}
+		return insertMeetings(ctx, tx, d, sectionID, meetings)
+	})
+}
+
+// loadMeetings sets the Meetings of sections, in the order of the week.
+func loadMeetings(ctx context.Context, db queryer, d sqlDialect, sections ...*Section) error {
+	if len(sections) == 0 {
+		return nil
+	}
+
+	bySection := map[int64]*Section{}
+	binds := make([]string, len(sections))
+	args := make([]any, len(sections))
+	for i, section := range sections {
+		section.Meetings = []Meeting{}
+		bySection[section.ID] = section
+		binds[i] = d.bind(i + 1)
+		args[i] = section.ID
+	}
+
+	query := `SELECT section_id, weekday, starts_at, ends_at, room FROM section_meetings
+		WHERE section_id IN (` + strings.Join(binds, ", ") + `) ORDER BY weekday, starts_at, id`
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to list meetings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sectionID int64
+		var m Meeting
+		if err := rows.Scan(&sectionID, &m.Weekday, &m.StartsAt, &m.EndsAt, &m.Room); err != nil {
+			return fmt.Errorf("failed to scan meeting: %w", err)
+		}
+		// a section can be listed twice, e.g. in two enrollments
+		for _, section := range sections {
+			if section.ID == sectionID {
+				section.Meetings = append(section.Meetings, m)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return nil
+}
+
+// queryGrades returns the best grade a student got in each course.
+func queryGrades(ctx context.Context, tx *sql.Tx, d sqlDialect, studentID int64) (map[int64]Grade, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT s.course_id, e.grade FROM enrollments e JOIN course_sections s ON s.id = e.section_id WHERE e.student_id = `+d.bind(1)+` AND e.grade <> ''`, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list grades: %w", err)
+	}
+	defer rows.Close()
+
+	courseGrades := map[int64]Grade{}
+	for rows.Next() {
+		var course int64
+		var grade Grade
+		if err := rows.Scan(&course, &grade); err != nil {
+			return nil, fmt.Errorf("failed to scan grade: %w", err)
+		}
+		bestGrades(courseGrades, course, grade)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return courseGrades, nil
+}
+
+// queryTakenSections lists the sections a student holds a seat or a
+// waitlist place in, in terms that overlap the given dates.
+func queryTakenSections(ctx context.Context, tx *sql.Tx, d sqlDialect, studentID int64, startsOn, endsOn time.Time) ([]*Section, error) {
+	query := fmt.Sprintf(`SELECT `+sectionFields+` FROM enrollments e JOIN course_sections s ON s.id = e.section_id`+sectionJoins+`
+		WHERE e.student_id = %s AND e.status <> 'dropped' AND e.grade = '' AND t.starts_on <= %s AND t.ends_on >= %s
+		ORDER BY e.id`, d.bind(1), d.bind(2), d.bind(3))
+	rows, err := tx.QueryContext(ctx, query, studentID, endsOn, startsOn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sections: %w", err)
+	}
+	defer rows.Close()
+
+	var sections []*Section
+	for rows.Next() {
+		var section Section
+		if err := rows.Scan(sectionDest(&section)...); err != nil {
+			return nil, fmt.Errorf("failed to scan section: %w", err)
+		}
+		sections = append(sections, &section)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return sections, nil
+}
+
+// checkRequirements runs the prerequisite and schedule checks of enrolling
+// a student in a section, in the transaction of enroll.
+func checkRequirements(ctx context.Context, tx *sql.Tx, d sqlDialect, studentID, sectionID int64) ([]RejectionReason, error) {
+	var courseID int64
+	var startsOn, endsOn time.Time
+	err := tx.QueryRowContext(ctx, `SELECT s.course_id, t.starts_on, t.ends_on FROM course_sections s JOIN terms t ON t.id = s.term_id WHERE s.id = `+d.bind(1), sectionID).
+		Scan(&courseID, &startsOn, &endsOn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get section: %w", err)
+	}
+
+	var reasons []RejectionReason
+	groups, err := queryPrerequisites(ctx, tx, d, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) > 0 {
+		courseGrades, err := queryGrades(ctx, tx, d, studentID)
+		if err != nil {
+			return nil, err
+		}
+		reasons = append(reasons, unmetPrerequisites(groups, courseGrades)...)
+	}
+
+	requested := &Section{ID: sectionID}
+	if err := loadMeetings(ctx, tx, d, requested); err != nil {
+		return nil, err
+	}
+	if len(requested.Meetings) == 0 {
+		return reasons, nil
+	}
+
+	taken, err := queryTakenSections(ctx, tx, d, studentID, startsOn, endsOn)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadMeetings(ctx, tx, d, taken...); err != nil {
+		return nil, err
+	}
+	return append(reasons, scheduleConflicts(requested.Meetings, taken)...), nil
+}
+
+func gradeEnrollment(ctx context.Context, db *sql.DB, d sqlDialect, studentID, enrollmentID int64, grade Grade) (*Enrollment, error) {
+	var graded *Enrollment
+	err := withTx(ctx, db, func(tx *sql.Tx) error {
+		var status EnrollmentStatus
+		err := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT status FROM enrollments WHERE id = %s AND student_id = %s`, d.bind(1), d.bind(2)), enrollmentID, studentID).Scan(&status)
+		if err == sql.ErrNoRows {
+			return errEnrollmentNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get enrollment: %w", err)
+		}
+		if status != EnrollmentEnrolled {
+			return ErrNotGradable
+		}
+
+		query := fmt.Sprintf(`UPDATE enrollments SET grade = %s, updated_at = %s WHERE id = %s`, d.bind(1), d.bind(2), d.bind(3))
+		if _, err := tx.ExecContext(ctx, query, grade, time.Now().UTC(), enrollmentID); err != nil {
+			return fmt.Errorf("failed to grade enrollment: %w", err)
+		}
+
+		graded, err = getEnrollment(ctx, tx, d, enrollmentID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return graded, nil
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEnrollChecksPrerequisites(t *testing.T) {
+	ctx := context.Background()
+
+	for name, store := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			// the seeded CS101 section is where the students earn their grades
+			intro, students := seedSection(t, store, 5, 0)
+
+			var courses []*Course
+			for _, code := range []string{"CS102", "MATH101", "CS201"} {
+				course := &Course{Code: code, Title: code, Capacity: 5, Active: true}
+				if err := store.CreateCourse(ctx, course); err != nil {
+					t.Fatal(err)
+				}
+				courses = append(courses, course)
+			}
+			cs102, math, advanced := courses[0], courses[1], courses[2]
+
+			// CS201 needs CS101 with a B or CS102, and MATH101
+			groups := [][]Prerequisite{
+				{{CourseID: intro.CourseID, MinGrade: "B"}, {CourseID: cs102.ID, MinGrade: PassingGrade}},
+				{{CourseID: math.ID, MinGrade: PassingGrade}},
+			}
+			if err := store.SetPrerequisites(ctx, advanced.ID, groups); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.SetPrerequisites(ctx, intro.CourseID, [][]Prerequisite{{{CourseID: advanced.ID, MinGrade: "C"}}}); !errors.Is(err, ErrPrerequisiteCycle) {
+				t.Errorf("got %v for a cycle, want ErrPrerequisiteCycle", err)
+			}
+			if err := store.DeleteCourse(ctx, math.ID); !errors.Is(err, ErrCourseIsPrerequisite) {
+				t.Errorf("got %v deleting a prerequisite, want ErrCourseIsPrerequisite", err)
+			}
+
+			got, err := store.ListPrerequisites(ctx, advanced.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 2 || len(got[0]) != 2 || got[0][0].CourseCode != "CS101" || got[1][0].CourseCode != "MATH101" {
+				t.Errorf("prerequisites are %+v", got)
+			}
+
+			term := &Term{Code: "2027-SP", Name: "Spring 2027", StartsOn: time.Date(2027, 1, 10, 0, 0, 0, 0, time.UTC), EndsOn: time.Date(2027, 5, 1, 0, 0, 0, 0, time.UTC)}
+			if err := store.CreateTerm(ctx, term); err != nil {
+				t.Fatal(err)
+			}
+			section := &Section{CourseID: advanced.ID, TermID: term.ID, Code: "A", Capacity: 5}
+			if err := store.CreateSection(ctx, section); err != nil {
+				t.Fatal(err)
+			}
+
+			student := students[0]
+			enrollment, _, err := store.Enroll(ctx, student.ID, intro.ID, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := store.GradeEnrollment(ctx, student.ID, enrollment.ID, "C"); err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := store.DropEnrollment(ctx, student.ID, enrollment.ID); !errors.Is(err, ErrEnrollmentGraded) {
+				t.Errorf("got %v dropping a graded enrollment, want ErrEnrollmentGraded", err)
+			}
+
+			// a C in CS101 is below the B the first group asks for
+			_, _, err = store.Enroll(ctx, student.ID, section.ID, true)
+			var rejected *EnrollmentRejectedError
+			if !errors.As(err, &rejected) || !errors.Is(err, ErrEnrollmentRejected) {
+				t.Fatalf("got %v, want an EnrollmentRejectedError", err)
+			}
+			if len(rejected.Reasons) != 2 || rejected.Reasons[0].Code != ReasonPrerequisite || len(rejected.Reasons[0].AnyOf) != 2 || rejected.Reasons[1].AnyOf[0].CourseID != math.ID {
+				t.Fatalf("reasons are %+v, want both groups", rejected.Reasons)
+			}
+
+			// regrading to a B meets the first group
+			if _, err := store.GradeEnrollment(ctx, student.ID, enrollment.ID, "B"); err != nil {
+				t.Fatal(err)
+			}
+			_, _, err = store.Enroll(ctx, student.ID, section.ID, true)
+			if !errors.As(err, &rejected) || len(rejected.Reasons) != 1 || rejected.Reasons[0].AnyOf[0].CourseCode != "MATH101" {
+				t.Fatalf("got %v, want only MATH101 missing", err)
+			}
+		})
+	}
+}
+
+func TestEnrollChecksScheduleConflicts(t *testing.T) {
+	ctx := context.Background()
+
+	for name, store := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			first, students := seedSection(t, store, 2, 0)
+			monday := []Meeting{{Weekday: time.Monday, StartsAt: 9 * 60, EndsAt: 10*60 + 30, Room: "B12"}}
+			if err := store.SetSectionMeetings(ctx, first.ID, monday); err != nil {
+				t.Fatal(err)
+			}
+
+			course := &Course{Code: "PHYS101", Title: "Physics", Capacity: 5, Active: true}
+			if err := store.CreateCourse(ctx, course); err != nil {
+				t.Fatal(err)
+			}
+			// starts when the first one ends, and clashes on Monday morning
+			second := &Section{CourseID: course.ID, TermID: first.TermID, Code: "A", Capacity: 5, Meetings: []Meeting{
+				{Weekday: time.Wednesday, StartsAt: 9 * 60, EndsAt: 10 * 60},
+				{Weekday: time.Monday, StartsAt: 10 * 60, EndsAt: 11 * 60},
+			}}
+			if err := store.CreateSection(ctx, second); err != nil {
+				t.Fatal(err)
+			}
+			got, err := store.GetSection(ctx, second.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Meetings) != 2 || got.Meetings[0].Weekday != time.Monday {
+				t.Errorf("meetings are %+v, want Monday first", got.Meetings)
+			}
+
+			student := students[0]
+			if _, _, err := store.Enroll(ctx, student.ID, first.ID, true); err != nil {
+				t.Fatal(err)
+			}
+			_, _, err = store.Enroll(ctx, student.ID, second.ID, true)
+			var rejected *EnrollmentRejectedError
+			if !errors.As(err, &rejected) {
+				t.Fatalf("got %v, want an EnrollmentRejectedError", err)
+			}
+			reason := rejected.Reasons[0]
+			if len(rejected.Reasons) != 1 || reason.Code != ReasonScheduleConflict || reason.Section.ID != first.ID || reason.Meeting.Room != "B12" {
+				t.Fatalf("reasons are %+v, want the clash with the first section", rejected.Reasons)
+			}
+
+			// a class may start the minute another ends
+			if err := store.SetSectionMeetings(ctx, second.ID, []Meeting{{Weekday: time.Monday, StartsAt: 10*60 + 30, EndsAt: 12 * 60}}); err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := store.Enroll(ctx, student.ID, second.ID, true); err != nil {
+				t.Errorf("got %v for back to back classes", err)
+			}
+		})
+	}
+}
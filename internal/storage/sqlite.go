@@ -0,0 +1,398 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+type SQLiteStorage struct {
+	db *sql.DB
+}
+
+func NewSQLiteStorage(path string) (*SQLiteStorage, error) {
+	// Make sure the directory for the database file exists
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create storage directory: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_time_format=sqlite")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// SQLite only allows one writer at a time
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return &SQLiteStorage{db: db}, nil
+}
+
+func (s *SQLiteStorage) Migrator() (*Migrator, error) {
+	return NewMigrator(s.db, "sqlite")
+}
+
+func (s *SQLiteStorage) CreateStudent(ctx context.Context, student *Student) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		return s.insertStudent(ctx, tx, student, time.Now().UTC())
+	})
+}
+
+func (s *SQLiteStorage) ImportStudents(ctx context.Context, students []*Student, atomic bool) ([]error, error) {
+	return importStudents(ctx, s.db, students, atomic, func(tx *sql.Tx, student *Student) error {
+		return s.insertStudent(ctx, tx, student, time.Now().UTC())
+	})
+}
+
+// insertStudent creates student in tx and records it in the audit log.
+func (s *SQLiteStorage) insertStudent(ctx context.Context, tx *sql.Tx, student *Student, now time.Time) error {
+	if student.Role == "" {
+		student.Role = RoleStudent
+	}
+	if student.Status == "" {
+		student.Status = StatusApplicant
+	}
+
+	query := `
+		INSERT INTO students (first_name, last_name, registration_no, phone_number, email, password, role, created_at, updated_at, status, ` + profileColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	args := []any{
+		student.FirstName,
+		student.LastName,
+		student.RegistrationNo,
+		student.PhoneNumber,
+		student.Email,
+		student.Password,
+		student.Role,
+		now,
+		now,
+		student.Status,
+	}
+	result, err := tx.ExecContext(ctx, query, append(args, profileArgs(student.Profile)...)...)
+	if err != nil {
+		return fmt.Errorf("failed to create student: %w", fromSQLite(err))
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get student id: %w", err)
+	}
+
+	student.ID = id
+	student.CreatedAt = now
+	student.UpdatedAt = now
+	student.Version = 1
+	if student.Guardians != nil {
+		if err := replaceGuardians(ctx, tx, sqliteBind, student.ID, student.Guardians); err != nil {
+			return err
+		}
+	}
+	return insertAudit(ctx, tx, sqliteBind, newAuditEntry(ctx, AuditCreate, nil, student, now))
+}
+
+func (s *SQLiteStorage) GetStudentByID(ctx context.Context, id int64) (*Student, error) {
+	query := `
+		SELECT ` + studentColumns + `
+		FROM students
+		WHERE id = ? AND deleted_at IS NULL
+	`
+	student, err := scanStudent(s.db.QueryRowContext(ctx, query, id))
+
+	if err == sql.ErrNoRows {
+		return nil, errStudentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get student: %w", err)
+	}
+
+	return student, nil
+}
+
+func (s *SQLiteStorage) GetStudentByEmail(ctx context.Context, email string) (*Student, error) {
+	query := `
+		SELECT ` + studentColumns + `
+		FROM students
+		WHERE email = ? AND deleted_at IS NULL
+	`
+	student, err := scanStudent(s.db.QueryRowContext(ctx, query, email))
+
+	if err == sql.ErrNoRows {
+		return nil, errStudentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get student: %w", err)
+	}
+
+	return student, nil
+}
+
+func (s *SQLiteStorage) ListGuardians(ctx context.Context, studentID int64) ([]Guardian, error) {
+	return queryGuardians(ctx, s.db, sqliteBind, studentID)
+}
+
+// lockStudent reads a live student, or one in the trash if deleted is true.
+// There is only one connection, so nothing else can change it until tx ends.
+func (s *SQLiteStorage) lockStudent(ctx context.Context, tx *sql.Tx, id int64, deleted bool) (*Student, error) {
+	query := `SELECT ` + studentColumns + ` FROM students WHERE id = ?1 AND deleted_at IS NULL`
+	notFound := errStudentNotFound
+	if deleted {
+		query = `SELECT ` + studentColumns + ` FROM students WHERE id = ?1 AND deleted_at IS NOT NULL`
+		notFound = errDeletedStudentNotFound
+	}
+
+	student, err := scanStudent(tx.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, notFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get student: %w", err)
+	}
+
+	return student, nil
+}
+
+// updateLocked runs an UPDATE ... RETURNING on a student locked with
+// lockStudent and records the change in the audit log.
+func (s *SQLiteStorage) updateLocked(ctx context.Context, tx *sql.Tx, action AuditAction, before *Student, now time.Time, query string, args ...any) (*Student, error) {
+	student, err := scanStudent(tx.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		// nothing else can write meanwhile, so only the version check can exclude it
+		return nil, ErrVersionMismatch
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s student: %w", action, fromSQLite(err))
+	}
+
+	if err := insertAudit(ctx, tx, sqliteBind, newAuditEntry(ctx, action, before, student, now)); err != nil {
+		return nil, err
+	}
+	return student, nil
+}
+
+func (s *SQLiteStorage) UpdateStudent(ctx context.Context, id int64, update StudentUpdate) (*Student, error) {
+	var student *Student
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		before, err := s.lockStudent(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+		if update.Version != 0 && before.Version != update.Version {
+			return ErrVersionMismatch
+		}
+		if update.Empty() {
+			student = before
+			return nil
+		}
+
+		// the old guardians are only read when they are replaced, so the audit
+		// log only mentions them then
+		if update.Guardians != nil {
+			if before.Guardians, err = queryGuardians(ctx, tx, sqliteBind, id); err != nil {
+				return err
+			}
+			if err := replaceGuardians(ctx, tx, sqliteBind, id, *update.Guardians); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now().UTC()
+		query, args := buildUpdateQuery(id, update, now, sqliteBind)
+		student, err = scanStudent(tx.QueryRowContext(ctx, query, args...))
+		if err == sql.ErrNoRows {
+			return ErrVersionMismatch
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update student: %w", fromSQLite(err))
+		}
+		if update.Guardians != nil {
+			student.Guardians = *update.Guardians
+		}
+		return insertAudit(ctx, tx, sqliteBind, newAuditEntry(ctx, AuditUpdate, before, student, now))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return student, nil
+}
+
+func (s *SQLiteStorage) SetStudentRole(ctx context.Context, id int64, role Role) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		before, err := s.lockStudent(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		query := `UPDATE students SET role = ?1, updated_at = ?2, version = version + 1 WHERE id = ?3 RETURNING ` + studentColumns
+		_, err = s.updateLocked(ctx, tx, AuditUpdate, before, now, query, role, now, id)
+		return err
+	})
+}
+
+func (s *SQLiteStorage) UpdateStudentPassword(ctx context.Context, id int64, passwordHash string) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		before, err := s.lockStudent(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		query := `UPDATE students SET password = ?1, updated_at = ?2, version = version + 1 WHERE id = ?3 RETURNING ` + studentColumns
+		_, err = s.updateLocked(ctx, tx, AuditUpdate, before, now, query, passwordHash, now, id)
+		return err
+	})
+}
+
+func (s *SQLiteStorage) DeleteStudent(ctx context.Context, id int64, version int64) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		before, err := s.lockStudent(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		query := `UPDATE students SET deleted_at = ?1, updated_at = ?1, version = version + 1 WHERE id = ?2`
+		args := []any{now, id}
+		if version != 0 {
+			query += ` AND version = ?3`
+			args = append(args, version)
+		}
+		query += ` RETURNING ` + studentColumns
+
+		_, err = s.updateLocked(ctx, tx, AuditDelete, before, now, query, args...)
+		return err
+	})
+}
+
+func (s *SQLiteStorage) RestoreStudent(ctx context.Context, id int64) (*Student, error) {
+	var student *Student
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		before, err := s.lockStudent(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		query := `UPDATE students SET deleted_at = NULL, updated_at = ?1, version = version + 1 WHERE id = ?2 RETURNING ` + studentColumns
+		student, err = s.updateLocked(ctx, tx, AuditRestore, before, now, query, now, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return student, nil
+}
+
+// purge permanently deletes the students in the trash matched by where and
+// records each of them in the audit log.
+func (s *SQLiteStorage) purge(ctx context.Context, where string, args ...any) (int, error) {
+	purged := 0
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `DELETE FROM students WHERE deleted_at IS NOT NULL AND `+where+` RETURNING `+studentColumns, args...)
+		if err != nil {
+			return fmt.Errorf("failed to purge students: %w", fromSQLite(err))
+		}
+		defer rows.Close()
+
+		var deleted []*Student
+		for rows.Next() {
+			student, err := scanStudent(rows)
+			if err != nil {
+				return fmt.Errorf("failed to scan student: %w", err)
+			}
+			deleted = append(deleted, student)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating rows: %w", err)
+		}
+
+		now := time.Now().UTC()
+		for _, student := range deleted {
+			if err := insertAudit(ctx, tx, sqliteBind, newAuditEntry(ctx, AuditPurge, student, nil, now)); err != nil {
+				return err
+			}
+		}
+
+		purged = len(deleted)
+		return nil
+	})
+
+	return purged, err
+}
+
+func (s *SQLiteStorage) PurgeStudent(ctx context.Context, id int64) error {
+	purged, err := s.purge(ctx, `id = ?1`, id)
+	if err != nil {
+		return err
+	}
+	if purged == 0 {
+		return errDeletedStudentNotFound
+	}
+
+	return nil
+}
+
+func (s *SQLiteStorage) PurgeDeletedStudents(ctx context.Context, deletedBefore time.Time) (int, error) {
+	return s.purge(ctx, `deleted_at < ?1`, deletedBefore.UTC())
+}
+
+func (s *SQLiteStorage) ListStudents(ctx context.Context, opts ListOptions) ([]*Student, error) {
+	query, args, reversed := buildListQuery(opts, sqliteBind)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list students: %w", err)
+	}
+	defer rows.Close()
+
+	var students []*Student
+	for rows.Next() {
+		student, err := scanStudent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan student: %w", err)
+		}
+		students = append(students, student)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	if reversed {
+		reverseStudents(students)
+	}
+
+	return students, nil
+}
+
+func (s *SQLiteStorage) CountStudents(ctx context.Context, filter StudentFilter) (int, error) {
+	query, args := buildCountQuery(filter, sqliteBind)
+
+	var total int
+	err := s.db.QueryRowContext(ctx, query, args...).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count students: %w", err)
+	}
+
+	return total, nil
+}
+
+func (s *SQLiteStorage) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStorage) ExportStudents(ctx context.Context, opts ListOptions, fn func(*Student) error) error {
+	return exportPages(ctx, s.ListStudents, opts, fn)
+}
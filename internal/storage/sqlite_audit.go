@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+func (s *SQLiteStorage) ListAuditEntries(ctx context.Context, filter AuditFilter, limit, offset int) ([]*AuditEntry, error) {
+	query, args := buildAuditQuery(filter, limit, offset, sqliteBind)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (s *SQLiteStorage) CountAuditEntries(ctx context.Context, filter AuditFilter) (int, error) {
+	query, args := buildAuditCountQuery(filter, sqliteBind)
+
+	var total int
+	err := s.db.QueryRowContext(ctx, query, args...).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count audit log: %w", err)
+	}
+
+	return total, nil
+}
@@ -0,0 +1,27 @@
+package storage
+
+import "context"
+
+func (s *SQLiteStorage) CreateCourse(ctx context.Context, course *Course) error {
+	return insertCourse(ctx, s.db, sqliteDialect, course)
+}
+
+func (s *SQLiteStorage) GetCourse(ctx context.Context, id int64) (*Course, error) {
+	return getCourse(ctx, s.db, sqliteDialect, id)
+}
+
+func (s *SQLiteStorage) UpdateCourse(ctx context.Context, course *Course) error {
+	return updateCourse(ctx, s.db, sqliteDialect, course)
+}
+
+func (s *SQLiteStorage) DeleteCourse(ctx context.Context, id int64) error {
+	return deleteCourse(ctx, s.db, sqliteDialect, id)
+}
+
+func (s *SQLiteStorage) ListCourses(ctx context.Context, opts CourseListOptions) ([]*Course, error) {
+	return listCourses(ctx, s.db, sqliteDialect, opts)
+}
+
+func (s *SQLiteStorage) CountCourses(ctx context.Context, filter CourseFilter) (int, error) {
+	return countCourses(ctx, s.db, sqliteDialect, filter)
+}
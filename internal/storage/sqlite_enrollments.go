@@ -0,0 +1,39 @@
+package storage
+
+import "context"
+
+func (s *SQLiteStorage) CreateTerm(ctx context.Context, term *Term) error {
+	return insertTerm(ctx, s.db, sqliteDialect, term)
+}
+
+func (s *SQLiteStorage) ListTerms(ctx context.Context) ([]*Term, error) {
+	return queryTerms(ctx, s.db)
+}
+
+func (s *SQLiteStorage) CreateSection(ctx context.Context, section *Section) error {
+	return insertSection(ctx, s.db, sqliteDialect, section)
+}
+
+func (s *SQLiteStorage) GetSection(ctx context.Context, id int64) (*Section, error) {
+	return getSection(ctx, s.db, sqliteDialect, id)
+}
+
+func (s *SQLiteStorage) ListSections(ctx context.Context, courseID int64) ([]*Section, error) {
+	return querySections(ctx, s.db, sqliteDialect, courseID)
+}
+
+func (s *SQLiteStorage) Enroll(ctx context.Context, studentID, sectionID int64, waitlist bool) (*Enrollment, []*Enrollment, error) {
+	return enroll(ctx, s.db, sqliteDialect, studentID, sectionID, waitlist)
+}
+
+func (s *SQLiteStorage) DropEnrollment(ctx context.Context, studentID, enrollmentID int64) (*Enrollment, []*Enrollment, error) {
+	return dropEnrollment(ctx, s.db, sqliteDialect, studentID, enrollmentID)
+}
+
+func (s *SQLiteStorage) ListStudentEnrollments(ctx context.Context, studentID int64) ([]*Enrollment, error) {
+	return queryStudentEnrollments(ctx, s.db, sqliteDialect, studentID)
+}
+
+func (s *SQLiteStorage) ListSectionRoster(ctx context.Context, sectionID int64) ([]*RosterEntry, error) {
+	return queryRoster(ctx, s.db, sqliteDialect, sectionID)
+}
@@ -0,0 +1,19 @@
+package storage
+
+import "context"
+
+func (s *SQLiteStorage) SetPrerequisites(ctx context.Context, courseID int64, groups [][]Prerequisite) error {
+	return setPrerequisites(ctx, s.db, sqliteDialect, courseID, groups)
+}
+
+func (s *SQLiteStorage) ListPrerequisites(ctx context.Context, courseID int64) ([][]Prerequisite, error) {
+	return queryPrerequisites(ctx, s.db, sqliteDialect, courseID)
+}
+
+func (s *SQLiteStorage) SetSectionMeetings(ctx context.Context, sectionID int64, meetings []Meeting) error {
+	return setSectionMeetings(ctx, s.db, sqliteDialect, sectionID, meetings)
+}
+
+func (s *SQLiteStorage) GradeEnrollment(ctx context.Context, studentID, enrollmentID int64, grade Grade) (*Enrollment, error) {
+	return gradeEnrollment(ctx, s.db, sqliteDialect, studentID, enrollmentID, grade)
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+func (s *SQLiteStorage) CreateRefreshToken(ctx context.Context, token *RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (student_id, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+	now := time.Now().UTC()
+	result, err := s.db.ExecContext(ctx, query, token.StudentID, token.TokenHash, token.ExpiresAt.UTC(), now)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", fromSQLite(err))
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get refresh token id: %w", err)
+	}
+
+	token.ID = id
+	token.CreatedAt = now
+	return nil
+}
+
+func (s *SQLiteStorage) GetRefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	query := `
+		SELECT id, student_id, token_hash, expires_at, created_at, revoked_at
+		FROM refresh_tokens
+		WHERE token_hash = ?
+	`
+	var token RefreshToken
+	err := s.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.StudentID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&token.RevokedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return &token, nil
+}
+
+func (s *SQLiteStorage) RevokeRefreshToken(ctx context.Context, tokenHash string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = ? WHERE token_hash = ? AND revoked_at IS NULL`
+	result, err := s.db.ExecContext(ctx, query, time.Now().UTC(), tokenHash)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return errRefreshTokenNotFound
+	}
+
+	return nil
+}
+
+func (s *SQLiteStorage) RevokeStudentRefreshTokens(ctx context.Context, studentID int64) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		query := `UPDATE refresh_tokens SET revoked_at = ? WHERE student_id = ? AND revoked_at IS NULL`
+		if _, err := tx.ExecContext(ctx, query, time.Now().UTC(), studentID); err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+
+		query = `UPDATE students SET token_generation = token_generation + 1 WHERE id = ?`
+		if _, err := tx.ExecContext(ctx, query, studentID); err != nil {
+			return fmt.Errorf("failed to revoke access tokens: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *SQLiteStorage) CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_tokens (student_id, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+	now := time.Now().UTC()
+	result, err := s.db.ExecContext(ctx, query, token.StudentID, token.TokenHash, token.ExpiresAt.UTC(), now)
+	if err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", fromSQLite(err))
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get password reset token id: %w", err)
+	}
+
+	token.ID = id
+	token.CreatedAt = now
+	return nil
+}
+
+func (s *SQLiteStorage) ResetPassword(ctx context.Context, tokenHash string, passwordHash string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Claim the token, a concurrent reset with the same token finds no row
+	now := time.Now().UTC()
+	var studentID int64
+	err = tx.QueryRowContext(ctx, `
+		UPDATE password_reset_tokens SET used_at = ?1
+		WHERE token_hash = ?2 AND used_at IS NULL AND expires_at > ?1
+		RETURNING student_id
+	`, now, tokenHash).Scan(&studentID)
+	if err == sql.ErrNoRows {
+		return errResetTokenNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to use password reset token: %w", err)
+	}
+
+	// a student in the trash can't reset their password
+	before, err := s.lockStudent(ctx, tx, studentID, false)
+	if err == errStudentNotFound {
+		return errResetTokenNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE students SET password = ?, updated_at = ?, version = version + 1, token_generation = token_generation + 1 WHERE id = ? RETURNING ` + studentColumns
+	if _, err := s.updateLocked(ctx, tx, AuditUpdate, before, now, query, passwordHash, now, studentID); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE password_reset_tokens SET used_at = ? WHERE student_id = ? AND used_at IS NULL`, now, studentID)
+	if err != nil {
+		return fmt.Errorf("failed to invalidate password reset tokens: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = ? WHERE student_id = ? AND revoked_at IS NULL`, now, studentID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit password reset: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+func (s *SQLiteStorage) TransitionStudent(ctx context.Context, id int64, from, to StudentStatus, reason string) (*Student, *StatusTransition, error) {
+	if !from.CanTransitionTo(to) {
+		return nil, nil, ErrInvalidTransition
+	}
+
+	var student *Student
+	var transition *StatusTransition
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		// there is only one connection, so nothing else runs until the commit
+		before, err := s.lockStudent(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+		if before.Status != from {
+			return ErrStatusMismatch
+		}
+
+		now := time.Now().UTC()
+		query := `UPDATE students SET status = ?1, updated_at = ?2, version = version + 1 WHERE id = ?3 RETURNING ` + studentColumns
+		student, err = s.updateLocked(ctx, tx, AuditTransition, before, now, query, to, now, id)
+		if err != nil {
+			return err
+		}
+
+		transition = newStatusTransition(ctx, id, from, to, reason, now)
+		return insertTransition(ctx, tx, sqliteBind, transition)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return student, transition, nil
+}
+
+func (s *SQLiteStorage) ListStatusTransitions(ctx context.Context, studentID int64) ([]*StatusTransition, error) {
+	return queryTransitions(ctx, s.db, sqliteBind, studentID)
+}
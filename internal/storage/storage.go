@@ -2,26 +2,372 @@ package storage
 
 import (
 	"context"
+	"slices"
 	"time"
 )
 
+type Role string
+
+const (
+	RoleAdmin   Role = "admin"
+	RoleStaff   Role = "staff"
+	RoleStudent Role = "student"
+)
+
+func (r Role) Valid() bool {
+	return r == RoleAdmin || r == RoleStaff || r == RoleStudent
+}
+
+// StudentStatus is where a student is in their studies.
+type StudentStatus string
+
+const (
+	StatusApplicant StudentStatus = "applicant"
+	StatusActive    StudentStatus = "active"
+	StatusSuspended StudentStatus = "suspended"
+	StatusGraduated StudentStatus = "graduated"
+	StatusWithdrawn StudentStatus = "withdrawn"
+)
+
+func (s StudentStatus) Valid() bool {
+	switch s {
+	case StatusApplicant, StatusActive, StatusSuspended, StatusGraduated, StatusWithdrawn:
+		return true
+	}
+	return false
+}
+
+// statusTransitions are the status changes a student can go through.
+// Graduation is final, a withdrawn student has to apply again.
+var statusTransitions = map[StudentStatus][]StudentStatus{
+	StatusApplicant: {StatusActive, StatusWithdrawn},
+	StatusActive:    {StatusSuspended, StatusGraduated, StatusWithdrawn},
+	StatusSuspended: {StatusActive, StatusWithdrawn},
+	StatusWithdrawn: {StatusApplicant},
+}
+
+// NextStatuses returns the statuses a student in status s can move to.
+func (s StudentStatus) NextStatuses() []StudentStatus {
+	return slices.Clone(statusTransitions[s])
+}
+
+// CanTransitionTo reports whether a student can go from status s to to.
+func (s StudentStatus) CanTransitionTo(to StudentStatus) bool {
+	return slices.Contains(statusTransitions[s], to)
+}
+
+// StatusTransition is a change of status, kept as the history of a student.
+type StatusTransition struct {
+	ID        int64         `db:"id"`
+	StudentID int64         `db:"student_id"`
+	From      StudentStatus `db:"from_status"`
+	To        StudentStatus `db:"to_status"`
+	Reason    string        `db:"reason"`
+	// ActorID is nil for changes not made by a logged in student
+	ActorID   *int64    `db:"actor_id"`
+	Actor     string    `db:"actor"`
+	RequestID string    `db:"request_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Address is a postal address, every part of it is optional.
+type Address struct {
+	Line1      string `db:"address_line1"`
+	Line2      string `db:"address_line2"`
+	City       string `db:"address_city"`
+	Region     string `db:"address_region"`
+	PostalCode string `db:"address_postal_code"`
+	// Country is an ISO 3166-1 alpha-2 code
+	Country string `db:"address_country"`
+}
+
+// Profile holds the personal details of a student. Dates are midnight UTC.
+type Profile struct {
+	DateOfBirth   *time.Time `db:"date_of_birth"`
+	Gender        string     `db:"gender"`
+	Address       Address
+	AdmissionDate *time.Time `db:"admission_date"`
+}
+
+// Guardian is a parent, guardian or emergency contact of a student.
+type Guardian struct {
+	ID           int64  `db:"id"`
+	StudentID    int64  `db:"student_id"`
+	Name         string `db:"name"`
+	Relationship string `db:"relationship"`
+	PhoneNumber  string `db:"phone_number"`
+	Email        string `db:"email"`
+	// EmergencyContact marks the guardians to call in an emergency
+	EmergencyContact bool `db:"emergency_contact"`
+}
+
 type Student struct {
 	ID             int64     `db:"id"`
 	FirstName      string    `db:"first_name"`
 	LastName       string    `db:"last_name"`
 	RegistrationNo int       `db:"registration_no"`
-	PhoneNumber    int64     `db:"phone_number"`
+	PhoneNumber    string    `db:"phone_number"`
 	Email          string    `db:"email"`
 	Password       string    `db:"password"`
+	Role           Role      `db:"role"`
 	CreatedAt      time.Time `db:"created_at"`
 	UpdatedAt      time.Time `db:"updated_at"`
+	// Version goes up by one on every write to the row
+	Version int64 `db:"version"`
+	// DeletedAt is set while the student is in the trash
+	DeletedAt *time.Time `db:"deleted_at"`
+	// TokenGeneration is carried by access tokens, which stop working once it
+	// goes up
+	TokenGeneration int64 `db:"token_generation"`
+	Profile
+	Status StudentStatus `db:"status"`
+	// Guardians are written by CreateStudent. Reads leave them nil, they are
+	// loaded with ListGuardians.
+	Guardians []Guardian
+}
+
+// StudentUpdate lists the columns to change, nil fields are left as they are.
+type StudentUpdate struct {
+	FirstName      *string
+	LastName       *string
+	RegistrationNo *int
+	PhoneNumber    *string
+	Email          *string
+	// Profile replaces the whole profile when set
+	Profile *Profile
+	// Guardians replaces every guardian of the student when set
+	Guardians *[]Guardian
+	// Version makes the update conditional on the row still being at this
+	// version, ErrVersionMismatch is returned otherwise. 0 skips the check.
+	Version int64
+}
+
+// Empty reports whether the update doesn't change any column.
+func (u StudentUpdate) Empty() bool {
+	return u.FirstName == nil && u.LastName == nil && u.RegistrationNo == nil && u.PhoneNumber == nil && u.Email == nil &&
+		u.Profile == nil && u.Guardians == nil
+}
+
+// RefreshToken is a server-side record of an issued refresh token. Only the
+// SHA-256 hash of the token is stored.
+type RefreshToken struct {
+	ID        int64      `db:"id"`
+	StudentID int64      `db:"student_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	CreatedAt time.Time  `db:"created_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+}
+
+// PasswordResetToken is a single-use token for resetting a forgotten
+// password. Only the SHA-256 hash of the token is stored.
+type PasswordResetToken struct {
+	ID        int64      `db:"id"`
+	StudentID int64      `db:"student_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	CreatedAt time.Time  `db:"created_at"`
+	UsedAt    *time.Time `db:"used_at"`
+}
+
+type SortField string
+
+// Fields the student list can be sorted by, ties are broken by id.
+const (
+	SortByID             SortField = "id"
+	SortByFirstName      SortField = "first_name"
+	SortByLastName       SortField = "last_name"
+	SortByRegistrationNo SortField = "registration_no"
+	SortByEmail          SortField = "email"
+	SortByCreatedAt      SortField = "created_at"
+	SortByUpdatedAt      SortField = "updated_at"
+)
+
+func (f SortField) Valid() bool {
+	switch f {
+	case SortByID, SortByFirstName, SortByLastName, SortByRegistrationNo, SortByEmail, SortByCreatedAt, SortByUpdatedAt:
+		return true
+	}
+	return false
+}
+
+// SortValue returns the value of the field the list is sorted by, for building cursors.
+func (s *Student) SortValue(field SortField) any {
+	switch field {
+	case SortByFirstName:
+		return s.FirstName
+	case SortByLastName:
+		return s.LastName
+	case SortByRegistrationNo:
+		return int64(s.RegistrationNo)
+	case SortByEmail:
+		return s.Email
+	case SortByCreatedAt:
+		return s.CreatedAt
+	case SortByUpdatedAt:
+		return s.UpdatedAt
+	default:
+		return s.ID
+	}
+}
+
+// StudentFilter narrows down the student list. Zero values don't filter.
+type StudentFilter struct {
+	// NamePrefix matches the start of the first or last name, case-insensitive
+	NamePrefix        string
+	Status            StudentStatus
+	RegistrationNoMin *int
+	RegistrationNoMax *int
+	CreatedFrom       *time.Time
+	CreatedTo         *time.Time
+	UpdatedFrom       *time.Time
+	UpdatedTo         *time.Time
+	// Query matches anywhere in the first name, last name or email, case-insensitive
+	Query string
+	// Deleted lists the students in the trash instead of the live ones
+	Deleted bool
+}
+
+// Cursor is a position in the sorted student list: the sort field value
+// (string, int64 or time.Time) and the id of the row at that position.
+type Cursor struct {
+	Value any
+	ID    int64
+}
+
+type ListOptions struct {
+	Filter StudentFilter
+	// SortBy defaults to created_at
+	SortBy   SortField
+	SortDesc bool
+	// Limit caps the number of rows, 0 returns every matching row and
+	// ignores Offset
+	Limit  int
+	Offset int
+	// After returns the page following the cursor, Before the page preceding it.
+	// Offset is ignored when either is set.
+	After  *Cursor
+	Before *Cursor
 }
 
 type Storage interface {
 	CreateStudent(ctx context.Context, student *Student) error
+	// ImportStudents creates students in a single transaction and returns the
+	// error for each of them in order, nil for the ones created. Duplicates
+	// only fail their own row. If atomic is set and any row fails, nothing is
+	// created and the other rows get ErrRolledBack.
+	ImportStudents(ctx context.Context, students []*Student, atomic bool) ([]error, error)
 	GetStudentByID(ctx context.Context, id int64) (*Student, error)
 	GetStudentByEmail(ctx context.Context, email string) (*Student, error)
-	UpdateStudent(ctx context.Context, student *Student) error
-	DeleteStudent(ctx context.Context, id int64) error
-	ListStudents(ctx context.Context, limit, offset int) ([]*Student, error)
+	// ListGuardians returns the guardians of a student in the order they were given
+	ListGuardians(ctx context.Context, studentID int64) ([]Guardian, error)
+	// UpdateStudent only writes the columns set in update and returns the updated student
+	UpdateStudent(ctx context.Context, id int64, update StudentUpdate) (*Student, error)
+	SetStudentRole(ctx context.Context, id int64, role Role) error
+	// TransitionStudent moves a student from status from to status to and
+	// records the transition with reason. The status is checked in the same
+	// transaction: it returns ErrInvalidTransition if the statuses don't allow
+	// the change and ErrStatusMismatch if the student isn't in from anymore.
+	TransitionStudent(ctx context.Context, id int64, from, to StudentStatus, reason string) (*Student, *StatusTransition, error)
+	// ListStatusTransitions returns the transitions of a student, oldest first
+	ListStatusTransitions(ctx context.Context, studentID int64) ([]*StatusTransition, error)
+	UpdateStudentPassword(ctx context.Context, id int64, passwordHash string) error
+	// DeleteStudent moves the student to the trash. It returns ErrVersionMismatch
+	// if version isn't 0 and the row is at another version.
+	DeleteStudent(ctx context.Context, id int64, version int64) error
+	// RestoreStudent takes a student out of the trash
+	RestoreStudent(ctx context.Context, id int64) (*Student, error)
+	// PurgeStudent permanently deletes a student that is in the trash
+	PurgeStudent(ctx context.Context, id int64) error
+	// PurgeDeletedStudents permanently deletes the students trashed before
+	// deletedBefore and returns how many there were.
+	PurgeDeletedStudents(ctx context.Context, deletedBefore time.Time) (int, error)
+	ListStudents(ctx context.Context, opts ListOptions) ([]*Student, error)
+	CountStudents(ctx context.Context, filter StudentFilter) (int, error)
+	// ExportStudents calls fn for every student matched by opts.Filter, in
+	// sort order, without loading them all into memory. Limit, Offset and the
+	// cursors are ignored. It stops at the first error returned by fn.
+	ExportStudents(ctx context.Context, opts ListOptions, fn func(*Student) error) error
+
+	// The course catalogue isn't recorded in the audit log, which is about
+	// students. CreateCourse and UpdateCourse return ErrDuplicateCourseCode
+	// if another course has the code.
+	CreateCourse(ctx context.Context, course *Course) error
+	GetCourse(ctx context.Context, id int64) (*Course, error)
+	// UpdateCourse writes every field of course and reloads it from the stored row
+	UpdateCourse(ctx context.Context, course *Course) error
+	// DeleteCourse permanently deletes a course, it returns
+	// ErrCourseHasSections once the course has been offered in a term and
+	// ErrCourseIsPrerequisite while another course requires it
+	DeleteCourse(ctx context.Context, id int64) error
+	ListCourses(ctx context.Context, opts CourseListOptions) ([]*Course, error)
+	CountCourses(ctx context.Context, filter CourseFilter) (int, error)
+	// SetPrerequisites replaces the prerequisite groups of a course. It
+	// returns ErrNotFound if a course doesn't exist and ErrPrerequisiteCycle
+	// if a prerequisite requires the course.
+	SetPrerequisites(ctx context.Context, courseID int64, groups [][]Prerequisite) error
+	ListPrerequisites(ctx context.Context, courseID int64) ([][]Prerequisite, error)
+
+	CreateTerm(ctx context.Context, term *Term) error
+	// ListTerms returns every term, the latest to start first
+	ListTerms(ctx context.Context) ([]*Term, error)
+	// CreateSection stores a section with its Meetings. It returns
+	// ErrNotFound if the course or term doesn't exist and
+	// ErrDuplicateSectionCode if the course has a section with the code in
+	// the term already
+	CreateSection(ctx context.Context, section *Section) error
+	GetSection(ctx context.Context, id int64) (*Section, error)
+	// ListSections returns the sections of a course, the latest term first
+	ListSections(ctx context.Context, courseID int64) ([]*Section, error)
+	// SetSectionMeetings replaces the meetings of a section, the students
+	// already in it are not checked for clashes
+	SetSectionMeetings(ctx context.Context, sectionID int64, meetings []Meeting) error
+	// Enroll gives an active student a seat in a section. The seats are
+	// counted and taken in one transaction, so a section never goes over
+	// capacity. A full section puts the student on its waitlist, or returns
+	// ErrSectionFull if waitlist is false. If the student hasn't passed the
+	// prerequisites of the course, or is already in a section meeting at the
+	// same time in an overlapping term, it returns an
+	// *EnrollmentRejectedError with every reason. Seats that were free while
+	// others waited are given to the waitlist first, the enrollments promoted
+	// that way are returned along with the new one.
+	Enroll(ctx context.Context, studentID, sectionID int64, waitlist bool) (*Enrollment, []*Enrollment, error)
+	// DropEnrollment drops an enrollment of a student. If that frees a seat,
+	// the first student on the waitlist takes it in the same transaction; the
+	// enrollments promoted that way are returned along with the dropped one.
+	// A graded enrollment returns ErrEnrollmentGraded.
+	DropEnrollment(ctx context.Context, studentID, enrollmentID int64) (*Enrollment, []*Enrollment, error)
+	// ListStudentEnrollments returns every enrollment of a student with its
+	// Section, dropped ones included, oldest first
+	ListStudentEnrollments(ctx context.Context, studentID int64) ([]*Enrollment, error)
+	// GradeEnrollment sets the grade of an enrolled student, grading again
+	// replaces it. It returns ErrNotGradable unless the status is enrolled.
+	GradeEnrollment(ctx context.Context, studentID, enrollmentID int64, grade Grade) (*Enrollment, error)
+	// ListSectionRoster returns the students enrolled in a section, then its
+	// waitlist in order
+	ListSectionRoster(ctx context.Context, sectionID int64) ([]*RosterEntry, error)
+
+	// Every method that changes a student, ResetPassword included, appends an
+	// AuditEntry in the same transaction, attributed to the Actor and request
+	// id in ctx. ListAuditEntries returns the newest entries first.
+	ListAuditEntries(ctx context.Context, filter AuditFilter, limit, offset int) ([]*AuditEntry, error)
+	CountAuditEntries(ctx context.Context, filter AuditFilter) (int, error)
+
+	CreateRefreshToken(ctx context.Context, token *RefreshToken) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	// RevokeRefreshToken returns ErrNotFound if the token doesn't exist or is already revoked
+	RevokeRefreshToken(ctx context.Context, tokenHash string) error
+	// RevokeStudentRefreshTokens ends every session of the student: it revokes
+	// their refresh tokens and bumps TokenGeneration, which invalidates the
+	// access tokens issued so far. It isn't recorded in the audit log.
+	RevokeStudentRefreshTokens(ctx context.Context, studentID int64) error
+
+	CreatePasswordResetToken(ctx context.Context, token *PasswordResetToken) error
+	// ResetPassword uses up an unexpired reset token and, in the same transaction,
+	// sets the new password, invalidates the student's other reset tokens and
+	// ends their sessions like RevokeStudentRefreshTokens. It returns
+	// ErrNotFound for unknown, used or expired tokens.
+	ResetPassword(ctx context.Context, tokenHash string, passwordHash string) error
+
+	Close() error
 }
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const transitionColumns = "id, student_id, from_status, to_status, reason, actor_id, actor, request_id, created_at"
+
+// newStatusTransition describes a change of status made by the actor in ctx.
+func newStatusTransition(ctx context.Context, studentID int64, from, to StudentStatus, reason string, now time.Time) *StatusTransition {
+	actor := ActorFrom(ctx)
+	transition := &StatusTransition{
+		StudentID: studentID,
+		From:      from,
+		To:        to,
+		Reason:    reason,
+		Actor:     actor.Name,
+		RequestID: RequestIDFrom(ctx),
+		CreatedAt: now,
+	}
+	if actor.ID != 0 {
+		transition.ActorID = &actor.ID
+	}
+	return transition
+}
+
+// insertTransition writes transition in the transaction that changes the
+// status and sets its id.
+func insertTransition(ctx context.Context, tx *sql.Tx, bind func(n int) string, transition *StatusTransition) error {
+	query := fmt.Sprintf(
+		`INSERT INTO student_transitions (student_id, from_status, to_status, reason, actor_id, actor, request_id, created_at) VALUES (%s, %s, %s, %s, %s, %s, %s, %s) RETURNING id`,
+		bind(1), bind(2), bind(3), bind(4), bind(5), bind(6), bind(7), bind(8),
+	)
+	err := tx.QueryRowContext(ctx, query,
+		transition.StudentID,
+		transition.From,
+		transition.To,
+		transition.Reason,
+		transition.ActorID,
+		transition.Actor,
+		transition.RequestID,
+		transition.CreatedAt,
+	).Scan(&transition.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record transition: %w", err)
+	}
+	return nil
+}
+
+// queryTransitions lists the transitions of a student, oldest first.
+func queryTransitions(ctx context.Context, db queryer, bind func(n int) string, studentID int64) ([]*StatusTransition, error) {
+	rows, err := db.QueryContext(ctx, `SELECT `+transitionColumns+` FROM student_transitions WHERE student_id = `+bind(1)+` ORDER BY id`, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transitions: %w", err)
+	}
+	defer rows.Close()
+
+	transitions := []*StatusTransition{}
+	for rows.Next() {
+		var t StatusTransition
+		err := rows.Scan(&t.ID, &t.StudentID, &t.From, &t.To, &t.Reason, &t.ActorID, &t.Actor, &t.RequestID, &t.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transition: %w", err)
+		}
+		transitions = append(transitions, &t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return transitions, nil
+}
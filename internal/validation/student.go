@@ -0,0 +1,255 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/smartcraze/student-api/internal/config"
+	"golang.org/x/net/idna"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	// e164 is a + followed by up to 15 digits, the first one not 0
+	e164 = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+	// country calling codes have one to three digits
+	countryCode = regexp.MustCompile(`^[1-9][0-9]{0,2}$`)
+)
+
+// Violation is a rule a student field breaks.
+type Violation struct {
+	// Field is the JSON name of the field
+	Field string
+	// Rule is e164, reg_no_format, reg_no_checksum or past
+	Rule    string
+	Param   string
+	Message string
+}
+
+// Error lists every rule the student fields break.
+type Error struct {
+	Violations []Violation
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Field + " " + v.Message
+	}
+	return strings.Join(messages, ", ")
+}
+
+// StudentFields points at the fields of a request to normalize, nil ones are
+// skipped, e.g. the fields a PATCH leaves alone.
+type StudentFields struct {
+	FirstName      *string
+	LastName       *string
+	RegistrationNo *int
+	PhoneNumber    *string
+	Email          *string
+	// DateOfBirth is a 2006-01-02 date, the request checks the format
+	DateOfBirth *string
+	Gender      *string
+	// Country is the ISO 3166-1 alpha-2 code of the address
+	Country   *string
+	Guardians []GuardianFields
+}
+
+// GuardianFields points at the fields of a guardian to normalize.
+type GuardianFields struct {
+	Name        *string
+	PhoneNumber *string
+	Email       *string
+}
+
+// StudentRules normalizes student fields and checks the rules that depend on
+// the configuration. Create, update, patch and import all go through it.
+type StudentRules struct {
+	countryCode   string
+	regNoPattern  *regexp.Regexp
+	regNoChecksum string
+}
+
+func NewStudentRules(cfg config.StudentRules) (*StudentRules, error) {
+	rules := &StudentRules{
+		countryCode:   strings.TrimPrefix(cfg.DefaultCountryCode, "+"),
+		regNoChecksum: cfg.RegistrationNoChecksum,
+	}
+
+	if rules.countryCode != "" && !countryCode.MatchString(rules.countryCode) {
+		return nil, fmt.Errorf("invalid default country code %q", cfg.DefaultCountryCode)
+	}
+
+	if cfg.RegistrationNoPattern != "" {
+		pattern, err := regexp.Compile(cfg.RegistrationNoPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid registration number pattern: %w", err)
+		}
+		rules.regNoPattern = pattern
+	}
+
+	switch cfg.RegistrationNoChecksum {
+	case "", "luhn":
+	default:
+		return nil, fmt.Errorf("unknown registration number checksum %q, must be luhn or empty", cfg.RegistrationNoChecksum)
+	}
+
+	return rules, nil
+}
+
+// Normalize rewrites the fields in place to their canonical form and returns
+// an *Error if any of them breaks a rule. Empty fields are left to the
+// required checks of the request.
+func (r *StudentRules) Normalize(f StudentFields) error {
+	var violations []Violation
+	violate := func(field, rule, param, message string) {
+		violations = append(violations, Violation{Field: field, Rule: rule, Param: param, Message: message})
+	}
+
+	if f.FirstName != nil {
+		*f.FirstName = NormalizeName(*f.FirstName)
+	}
+	if f.LastName != nil {
+		*f.LastName = NormalizeName(*f.LastName)
+	}
+	if f.Email != nil {
+		*f.Email = NormalizeEmail(*f.Email)
+	}
+
+	if f.Gender != nil {
+		*f.Gender = strings.TrimSpace(*f.Gender)
+	}
+	if f.Country != nil {
+		*f.Country = strings.ToUpper(strings.TrimSpace(*f.Country))
+	}
+
+	normalizePhone := func(field string, phone *string) {
+		if phone == nil || strings.TrimSpace(*phone) == "" {
+			return
+		}
+		if normalized, ok := r.normalizePhone(*phone); ok {
+			*phone = normalized
+		} else {
+			violate(field, "e164", "", "must be a phone number in E.164 format, e.g. +14155552671")
+		}
+	}
+	normalizePhone("phone_number", f.PhoneNumber)
+
+	for i, g := range f.Guardians {
+		if g.Name != nil {
+			*g.Name = NormalizeName(*g.Name)
+		}
+		if g.Email != nil {
+			*g.Email = NormalizeEmail(*g.Email)
+		}
+		normalizePhone(fmt.Sprintf("guardians[%d].phone_number", i), g.PhoneNumber)
+	}
+
+	if f.DateOfBirth != nil {
+		*f.DateOfBirth = strings.TrimSpace(*f.DateOfBirth)
+		born, err := time.Parse(time.DateOnly, *f.DateOfBirth)
+		if err == nil && !born.Before(time.Now().UTC().Truncate(24*time.Hour)) {
+			violate("date_of_birth", "past", "", "must be in the past")
+		}
+	}
+
+	if f.RegistrationNo != nil && *f.RegistrationNo > 0 {
+		regNo := strconv.Itoa(*f.RegistrationNo)
+		if r.regNoPattern != nil && !r.regNoPattern.MatchString(regNo) {
+			violate("reg_no", "reg_no_format", r.regNoPattern.String(), "must match the format "+r.regNoPattern.String())
+		}
+		if r.regNoChecksum == "luhn" && !luhnValid(regNo) {
+			violate("reg_no", "reg_no_checksum", r.regNoChecksum, "has an invalid check digit")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &Error{Violations: violations}
+	}
+	return nil
+}
+
+// normalizePhone strips the spaces, dashes, dots and parentheses people write
+// phone numbers with. A leading 00 stands for +, and a number without either
+// gets the default country code in place of its trunk 0.
+func (r *StudentRules) normalizePhone(phone string) (string, bool) {
+	var b strings.Builder
+	for i, c := range strings.TrimSpace(phone) {
+		switch {
+		case c >= '0' && c <= '9':
+			b.WriteRune(c)
+		case c == '+' && i == 0:
+			b.WriteRune(c)
+		case c == ' ' || c == '-' || c == '.' || c == '(' || c == ')':
+		default:
+			return "", false
+		}
+	}
+
+	digits := b.String()
+	switch {
+	case strings.HasPrefix(digits, "+"):
+	case strings.HasPrefix(digits, "00"):
+		digits = "+" + digits[2:]
+	case r.countryCode != "":
+		digits = "+" + r.countryCode + strings.TrimPrefix(digits, "0")
+	default:
+		return "", false
+	}
+
+	return digits, e164.MatchString(digits)
+}
+
+// IsE164 reports whether phone is a phone number in E.164 format, the way
+// phone numbers are stored.
+func IsE164(phone string) bool {
+	return e164.MatchString(phone)
+}
+
+// NormalizeName trims the name, collapses runs of white space into one space
+// and puts it in Unicode NFC, so the same name typed on different keyboards
+// is stored the same way.
+func NormalizeName(name string) string {
+	return norm.NFC.String(strings.Join(strings.FieldsFunc(name, unicode.IsSpace), " "))
+}
+
+// NormalizeEmail lower-cases the address and converts an internationalized
+// domain to its ASCII form, so uniqueness checks and lookups by email don't
+// depend on how the address was typed. Addresses it can't make sense of are
+// returned trimmed, for the email check to reject.
+func NormalizeEmail(email string) string {
+	email = strings.TrimSpace(email)
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+
+	local := strings.ToLower(norm.NFC.String(email[:at]))
+	domain, err := idna.Lookup.ToASCII(strings.TrimSuffix(email[at+1:], "."))
+	if err != nil {
+		return email
+	}
+	return local + "@" + strings.ToLower(domain)
+}
+
+// luhnValid reports whether the last digit of number is its Luhn check digit.
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return len(number) > 1 && sum%10 == 0
+}
@@ -0,0 +1,109 @@
+package validation
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/smartcraze/student-api/internal/config"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		countryCode string
+		phone       string
+		want        string
+		ok          bool
+	}{
+		{"", "+1 (415) 555-0100", "+14155550100", true},
+		{"", "0044 20 7946 0958", "+442079460958", true},
+		{"", "415 555 0100", "", false},
+		{"44", "020 7946 0958", "+442079460958", true},
+		{"+1", "415.555.0100", "+14155550100", true},
+		{"", "+0123456", "", false},
+		{"", "+1234567890123456", "", false},
+		{"", "+1 415 CALL-NOW", "", false},
+		{"", "1+415", "", false},
+	}
+
+	for _, tt := range tests {
+		rules, err := NewStudentRules(config.StudentRules{DefaultCountryCode: tt.countryCode})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := rules.normalizePhone(tt.phone)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("country %q, %q: got %q, %v, want %q, %v", tt.countryCode, tt.phone, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"  John.Doe@Example.COM ", "john.doe@example.com"},
+		{"ana@bücher.example", "ana@xn--bcher-kva.example"},
+		{"ana@example.com.", "ana@example.com"},
+		{"not an email", "not an email"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeEmail(tt.email); got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	// "e" followed by a combining acute accent becomes a single "é"
+	if got := NormalizeName("  José   María\t"); got != "José María" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestRegistrationNoRules(t *testing.T) {
+	rules, err := NewStudentRules(config.StudentRules{RegistrationNoPattern: `^20[0-9]{4}$`, RegistrationNoChecksum: "luhn"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		regNo int
+		want  []string
+	}{
+		{200006, nil},
+		{200007, []string{"reg_no_checksum"}},
+		{300006, []string{"reg_no_format", "reg_no_checksum"}},
+		{1990001, []string{"reg_no_format"}},
+	}
+
+	for _, tt := range tests {
+		regNo := tt.regNo
+		err := rules.Normalize(StudentFields{RegistrationNo: &regNo})
+
+		var got []string
+		var rulesErr *Error
+		if errors.As(err, &rulesErr) {
+			for _, v := range rulesErr.Violations {
+				got = append(got, v.Rule)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%d: broke %v, want %v", tt.regNo, got, tt.want)
+		}
+	}
+}
+
+func TestNewStudentRulesRejectsBadConfig(t *testing.T) {
+	for _, cfg := range []config.StudentRules{
+		{DefaultCountryCode: "0"},
+		{RegistrationNoPattern: "("},
+		{RegistrationNoChecksum: "crc32"},
+	} {
+		if _, err := NewStudentRules(cfg); err == nil {
+			t.Errorf("%+v: expected an error", cfg)
+		}
+	}
+}
@@ -0,0 +1,411 @@
+package response
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Every format is rendered from the JSON encoding of the data, so field
+// names, omitempty and custom marshalers behave the same in all of them.
+
+// field is an object member, objects keep their keys in order.
+type field struct {
+	key   string
+	value any
+}
+
+type object []field
+
+// decodeTree decodes a JSON document into object, []any, string,
+// json.Number, bool and nil values.
+func decodeTree(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decodeValue(decoder)
+}
+
+func decodeValue(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		obj := object{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, field{key: key.(string), value: value})
+		}
+		_, err := decoder.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []any{}
+		for decoder.More() {
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err := decoder.Token()
+		return arr, err
+	default:
+		return token, nil
+	}
+}
+
+func toTree(data any) (any, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return decodeTree(raw)
+}
+
+// encodeXML writes the data under a <response> root, or the <problem> root
+// of RFC 7807 for a Problem. Array elements become <item> elements and null
+// values are marked with nil="true".
+func encodeXML(w io.Writer, data any) error {
+	tree, err := toTree(data)
+	if err != nil {
+		return err
+	}
+
+	root := xml.StartElement{Name: xml.Name{Local: "response"}}
+	if _, ok := data.(*Problem); ok {
+		root = xml.StartElement{Name: xml.Name{Local: "problem"}, Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: "urn:ietf:rfc:7807"}}}
+	}
+
+	io.WriteString(w, xml.Header)
+	encoder := xml.NewEncoder(w)
+	if err := encodeXMLElement(encoder, root, tree); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+func encodeXMLValue(encoder *xml.Encoder, name string, value any) error {
+	return encodeXMLElement(encoder, xml.StartElement{Name: xml.Name{Local: xmlName(name)}}, value)
+}
+
+func encodeXMLElement(encoder *xml.Encoder, start xml.StartElement, value any) error {
+	if value == nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "nil"}, Value: "true"})
+	}
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case object:
+		for _, f := range v {
+			if err := encodeXMLValue(encoder, f.key, f.value); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if err := encodeXMLValue(encoder, "item", item); err != nil {
+				return err
+			}
+		}
+	case nil:
+	default:
+		if err := encoder.EncodeToken(xml.CharData(fmt.Sprint(v))); err != nil {
+			return err
+		}
+	}
+
+	return encoder.EncodeToken(start.End())
+}
+
+// xmlName turns a JSON key into a valid element name.
+func xmlName(key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		valid := r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' ||
+			i > 0 && (r == '-' || r == '.' || r >= '0' && r <= '9')
+		if valid {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	if b.Len() == 0 || strings.HasPrefix(strings.ToLower(b.String()), "xml") {
+		return "_" + b.String()
+	}
+	return b.String()
+}
+
+func encodeYAML(w io.Writer, data any) error {
+	tree, err := toTree(data)
+	if err != nil {
+		return err
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(yamlNode(tree)); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func yamlNode(value any) *yaml.Node {
+	switch v := value.(type) {
+	case object:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, f := range v {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: f.key}, yamlNode(f.value))
+		}
+		return node
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range v {
+			node.Content = append(node.Content, yamlNode(item))
+		}
+		return node
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(v.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(v)}
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+}
+
+// errNotCollection means the data has no rows to render as CSV.
+var errNotCollection = errors.New("response is not a collection")
+
+// encodeCSV writes one row per item of a Collection, with a header line of
+// every key that appears in any item. Nested values are written as JSON.
+func encodeCSV(w io.Writer, data any) error {
+	collection, ok := data.(Collection)
+	if !ok {
+		return errNotCollection
+	}
+
+	tree, err := toTree(collection.Items())
+	if err != nil {
+		return err
+	}
+	items, _ := tree.([]any)
+
+	var columns []string
+	seen := make(map[string]bool)
+	for _, item := range items {
+		obj, ok := item.(object)
+		if !ok {
+			return errNotCollection
+		}
+		for _, f := range obj {
+			if !seen[f.key] {
+				seen[f.key] = true
+				columns = append(columns, f.key)
+			}
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	for _, item := range items {
+		record := make([]string, len(columns))
+		for _, f := range item.(object) {
+			i := slices.Index(columns, f.key)
+			record[i], err = csvCell(f.value)
+			if err != nil {
+				return err
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func csvCell(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number, bool:
+		return fmt.Sprint(v), nil
+	default:
+		var b bytes.Buffer
+		if err := encodeJSONTree(&b, v); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+}
+
+// encodeJSONTree writes a decoded tree back as compact JSON.
+func encodeJSONTree(b *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case object:
+		b.WriteByte('{')
+		for i, f := range v {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			key, _ := json.Marshal(f.key)
+			b.Write(key)
+			b.WriteByte(':')
+			if err := encodeJSONTree(b, f.value); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	case []any:
+		b.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := encodeJSONTree(b, item); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		b.Write(raw)
+	}
+	return nil
+}
+
+func encodeMsgpack(w io.Writer, data any) error {
+	tree, err := toTree(data)
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	if err := encodeMsgpackValue(&b, tree); err != nil {
+		return err
+	}
+	_, err = w.Write(b.Bytes())
+	return err
+}
+
+// encodeMsgpackValue writes value in the MessagePack format, see
+// https://github.com/msgpack/msgpack/blob/master/spec.md
+func encodeMsgpackValue(b *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case nil:
+		b.WriteByte(0xc0)
+	case bool:
+		if v {
+			b.WriteByte(0xc3)
+		} else {
+			b.WriteByte(0xc2)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			msgpackInt(b, n)
+			return nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		b.WriteByte(0xcb)
+		binary.Write(b, binary.BigEndian, math.Float64bits(f))
+	case string:
+		msgpackHeader(b, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		b.WriteString(v)
+	case []any:
+		msgpackHeader(b, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range v {
+			if err := encodeMsgpackValue(b, item); err != nil {
+				return err
+			}
+		}
+	case object:
+		msgpackHeader(b, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		for _, f := range v {
+			if err := encodeMsgpackValue(b, f.key); err != nil {
+				return err
+			}
+			if err := encodeMsgpackValue(b, f.value); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported value %T", value)
+	}
+	return nil
+}
+
+func msgpackInt(b *bytes.Buffer, n int64) {
+	switch {
+	case n >= 0 && n <= 127:
+		b.WriteByte(byte(n))
+	case n >= -32 && n < 0:
+		b.WriteByte(byte(int8(n)))
+	case n >= math.MinInt8 && n <= math.MaxInt8:
+		b.WriteByte(0xd0)
+		b.WriteByte(byte(int8(n)))
+	case n >= math.MinInt16 && n <= math.MaxInt16:
+		b.WriteByte(0xd1)
+		binary.Write(b, binary.BigEndian, int16(n))
+	case n >= math.MinInt32 && n <= math.MaxInt32:
+		b.WriteByte(0xd2)
+		binary.Write(b, binary.BigEndian, int32(n))
+	default:
+		b.WriteByte(0xd3)
+		binary.Write(b, binary.BigEndian, n)
+	}
+}
+
+// msgpackHeader writes the type and length of a string, array or map: the
+// fix variant below fixMax, then the 8 (strings only), 16 and 32 bit ones.
+func msgpackHeader(b *bytes.Buffer, n int, fix byte, fixMax int, code8, code16, code32 byte) {
+	switch {
+	case n < fixMax:
+		b.WriteByte(fix | byte(n))
+	case code8 != 0 && n <= math.MaxUint8:
+		b.WriteByte(code8)
+		b.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		b.WriteByte(code16)
+		binary.Write(b, binary.BigEndian, uint16(n))
+	default:
+		b.WriteByte(code32)
+		binary.Write(b, binary.BigEndian, uint32(n))
+	}
+}
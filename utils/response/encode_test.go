@@ -0,0 +1,434 @@
+package response
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+type testNested struct {
+	Code  string `json:"code"`
+	Count int    `json:"count"`
+}
+
+type testItem struct {
+	ID     int64       `json:"id"`
+	Name   string      `json:"name"`
+	Score  float64     `json:"score"`
+	Active bool        `json:"active"`
+	Note   *string     `json:"note"`
+	Tags   []string    `json:"tags,omitempty"`
+	Nested *testNested `json:"nested,omitempty"`
+}
+
+type testList struct {
+	Rows []testItem `json:"items"`
+}
+
+func (l testList) Items() any {
+	return l.Rows
+}
+
+var testData = testList{Rows: []testItem{
+	{ID: 1, Name: "Zoë", Score: 1.5, Active: true, Tags: []string{"a", "b"}},
+	// strings that read as other types in YAML must stay strings
+	{ID: -40000, Name: "true", Score: -0.25, Nested: &testNested{Code: "123", Count: 2}},
+	{ID: math.MaxInt64, Name: "null, \"quoted\"\nline"},
+}}
+
+// plainTree converts the json.Number values of a decoded tree into the
+// int64 and float64 values the MessagePack decoder returns.
+func plainTree(value any) any {
+	switch v := value.(type) {
+	case object:
+		obj := object{}
+		for _, f := range v {
+			obj = append(obj, field{f.key, plainTree(f.value)})
+		}
+		return obj
+	case []any:
+		arr := []any{}
+		for _, item := range v {
+			arr = append(arr, plainTree(item))
+		}
+		return arr
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	default:
+		return v
+	}
+}
+
+// decodeMsgpack reads back the subset of MessagePack encodeMsgpack writes.
+func decodeMsgpack(r *bytes.Reader) (any, error) {
+	code, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	read := func(v any) error { return binary.Read(r, binary.BigEndian, v) }
+	length := func(size int) (int, error) {
+		switch size {
+		case 8:
+			n, err := r.ReadByte()
+			return int(n), err
+		case 16:
+			var n uint16
+			err := read(&n)
+			return int(n), err
+		default:
+			var n uint32
+			err := read(&n)
+			return int(n), err
+		}
+	}
+
+	var n int
+	switch {
+	case code <= 0x7f:
+		return int64(code), nil
+	case code >= 0xe0:
+		return int64(int8(code)), nil
+	case code&0xe0 == 0xa0:
+		return readString(r, int(code&0x1f))
+	case code&0xf0 == 0x90:
+		return readArray(r, int(code&0x0f))
+	case code&0xf0 == 0x80:
+		return readMap(r, int(code&0x0f))
+	}
+
+	switch code {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xd0:
+		var v int8
+		err = read(&v)
+		return int64(v), err
+	case 0xd1:
+		var v int16
+		err = read(&v)
+		return int64(v), err
+	case 0xd2:
+		var v int32
+		err = read(&v)
+		return int64(v), err
+	case 0xd3:
+		var v int64
+		err = read(&v)
+		return v, err
+	case 0xcb:
+		var v uint64
+		err = read(&v)
+		return math.Float64frombits(v), err
+	case 0xd9, 0xda, 0xdb:
+		if n, err = length(map[byte]int{0xd9: 8, 0xda: 16, 0xdb: 32}[code]); err != nil {
+			return nil, err
+		}
+		return readString(r, n)
+	case 0xdc, 0xdd:
+		if n, err = length(map[byte]int{0xdc: 16, 0xdd: 32}[code]); err != nil {
+			return nil, err
+		}
+		return readArray(r, n)
+	case 0xde, 0xdf:
+		if n, err = length(map[byte]int{0xde: 16, 0xdf: 32}[code]); err != nil {
+			return nil, err
+		}
+		return readMap(r, n)
+	}
+	return nil, fmt.Errorf("unexpected code %#x", code)
+}
+
+func readString(r *bytes.Reader, n int) (any, error) {
+	b := make([]byte, n)
+	_, err := io.ReadFull(r, b)
+	return string(b), err
+}
+
+func readArray(r *bytes.Reader, n int) (any, error) {
+	arr := []any{}
+	for range n {
+		item, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, item)
+	}
+	return arr, nil
+}
+
+func readMap(r *bytes.Reader, n int) (any, error) {
+	obj := object{}
+	for range n {
+		key, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		value, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		obj = append(obj, field{key.(string), value})
+	}
+	return obj, nil
+}
+
+// testObject returns an object with n members named by their index.
+func testObject(n int) object {
+	obj := object{}
+	for i := range n {
+		obj = append(obj, field{fmt.Sprint(i), nil})
+	}
+	return obj
+}
+
+func TestEncodeMsgpackHeaders(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  []byte
+	}{
+		{"nil", nil, []byte{0xc0}},
+		{"false", false, []byte{0xc2}},
+		{"positive fixint", json.Number("127"), []byte{0x7f}},
+		{"negative fixint", json.Number("-32"), []byte{0xe0}},
+		{"int8", json.Number("-33"), []byte{0xd0, 0xdf}},
+		{"int16", json.Number("128"), []byte{0xd1, 0x00, 0x80}},
+		{"int32", json.Number("-40000"), []byte{0xd2, 0xff, 0xff, 0x63, 0xc0}},
+		{"int64", json.Number("1099511627776"), []byte{0xd3, 0, 0, 0x01, 0, 0, 0, 0, 0}},
+		{"float64", json.Number("1.5"), []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{"fixstr", strings.Repeat("x", 31), []byte{0xbf}},
+		{"str8", strings.Repeat("x", 32), []byte{0xd9, 32}},
+		{"str16", strings.Repeat("x", 256), []byte{0xda, 0x01, 0x00}},
+		{"str32", strings.Repeat("x", 70000), []byte{0xdb, 0, 0x01, 0x11, 0x70}},
+		{"fixarray", make([]any, 15), []byte{0x9f}},
+		{"array16", make([]any, 16), []byte{0xdc, 0, 16}},
+		{"fixmap", object{{"a", true}}, []byte{0x81, 0xa1, 'a', 0xc3}},
+		{"map16", testObject(16), []byte{0xde, 0, 16}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := encodeMsgpackValue(&b, tt.value); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(b.Bytes(), tt.want) {
+				t.Fatalf("encoded as % x, want it to start with % x", b.Bytes()[:min(b.Len(), 12)], tt.want)
+			}
+
+			decoded, err := decodeMsgpack(bytes.NewReader(b.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := plainTree(tt.value); !reflect.DeepEqual(decoded, want) {
+				t.Errorf("decoded %v, want %v", decoded, want)
+			}
+		})
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	raw, err := json.Marshal(testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := decodeTree(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("msgpack", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := encodeMsgpack(&b, testData); err != nil {
+			t.Fatal(err)
+		}
+		r := bytes.NewReader(b.Bytes())
+		decoded, err := decodeMsgpack(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Len() != 0 {
+			t.Errorf("%d bytes left over", r.Len())
+		}
+		// object keeps the keys in order, so this also checks their order
+		if want := plainTree(tree); !reflect.DeepEqual(decoded, want) {
+			t.Errorf("decoded %v, want %v", decoded, want)
+		}
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := encodeYAML(&b, testData); err != nil {
+			t.Fatal(err)
+		}
+
+		var decoded any
+		if err := yaml.Unmarshal(b.Bytes(), &decoded); err != nil {
+			t.Fatal(err)
+		}
+		// compare through JSON, YAML decodes integers as int where JSON has float64
+		viaYAML, err := json.Marshal(decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got, want any
+		json.Unmarshal(viaYAML, &got)
+		json.Unmarshal(raw, &want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("decoded %s, want %s", viaYAML, raw)
+		}
+
+		if !strings.Contains(b.String(), "name: \"true\"") || !strings.Contains(b.String(), "code: \"123\"") {
+			t.Errorf("strings that look like other types aren't quoted in\n%s", b.String())
+		}
+		if id, name := strings.Index(b.String(), "id:"), strings.Index(b.String(), "name:"); id > name {
+			t.Errorf("keys are out of order in\n%s", b.String())
+		}
+	})
+
+	t.Run("xml", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := encodeXML(&b, testData); err != nil {
+			t.Fatal(err)
+		}
+
+		var root xmlNode
+		if err := xml.Unmarshal(b.Bytes(), &root); err != nil {
+			t.Fatal(err)
+		}
+		if root.XMLName.Local != "response" {
+			t.Fatalf("root is <%s>", root.XMLName.Local)
+		}
+		items := root.child("items").Children
+		if len(items) != 3 || items[0].XMLName.Local != "item" {
+			t.Fatalf("items are %+v", items)
+		}
+		first := items[0]
+		if first.child("name").Text != "Zoë" || first.child("score").Text != "1.5" || first.child("active").Text != "true" {
+			t.Errorf("first item is %+v", first)
+		}
+		if tags := first.child("tags").Children; len(tags) != 2 || tags[1].Text != "b" {
+			t.Errorf("tags are %+v", tags)
+		}
+		if note := first.child("note"); len(note.Attrs) != 1 || note.Attrs[0].Name.Local != "nil" || note.Attrs[0].Value != "true" {
+			t.Errorf("null note is %+v", note)
+		}
+		if got := items[2].child("name").Text; got != testData.Rows[2].Name {
+			t.Errorf("name is %q, want %q", got, testData.Rows[2].Name)
+		}
+		if got := items[1].child("nested").child("count").Text; got != "2" {
+			t.Errorf("nested count is %q", got)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := encodeCSV(&b, testData); err != nil {
+			t.Fatal(err)
+		}
+
+		records, err := csv.NewReader(&b).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := [][]string{
+			// nested is only in the second item, it is still a column
+			{"id", "name", "score", "active", "note", "tags", "nested"},
+			{"1", "Zoë", "1.5", "true", "", `["a","b"]`, ""},
+			{"-40000", "true", "-0.25", "false", "", "", `{"code":"123","count":2}`},
+			{"9223372036854775807", "null, \"quoted\"\nline", "0", "false", "", "", ""},
+		}
+		if !reflect.DeepEqual(records, want) {
+			t.Errorf("records are\n%q\nwant\n%q", records, want)
+		}
+	})
+}
+
+func TestEncodeXMLNames(t *testing.T) {
+	var b bytes.Buffer
+	data := map[string]any{"2fa": 1, "xmlns": 2, "a b": 3, "": 4}
+	if err := encodeXML(&b, data); err != nil {
+		t.Fatal(err)
+	}
+
+	var root xmlNode
+	if err := xml.Unmarshal(b.Bytes(), &root); err != nil {
+		t.Fatalf("%v in %s", err, b.String())
+	}
+	var names []string
+	for _, c := range root.Children {
+		names = append(names, c.XMLName.Local)
+	}
+	// json.Marshal sorts map keys
+	if want := []string{"_", "_fa", "a_b", "_xmlns"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("element names are %q, want %q", names, want)
+	}
+}
+
+func TestEncodeXMLProblem(t *testing.T) {
+	var b bytes.Buffer
+	if err := encodeXML(&b, &Problem{Type: "about:blank", Title: "Not Found", Status: 404}); err != nil {
+		t.Fatal(err)
+	}
+
+	var root xmlNode
+	if err := xml.Unmarshal(b.Bytes(), &root); err != nil {
+		t.Fatal(err)
+	}
+	if root.XMLName.Local != "problem" || root.XMLName.Space != "urn:ietf:rfc:7807" || root.child("status").Text != "404" {
+		t.Errorf("problem is %s", b.String())
+	}
+}
+
+func TestEncodeCSVNotCollection(t *testing.T) {
+	var b bytes.Buffer
+	if err := encodeCSV(&b, testItem{}); !errors.Is(err, errNotCollection) {
+		t.Errorf("got %v, want errNotCollection", err)
+	}
+	if err := encodeCSV(&b, stringList{"a"}); !errors.Is(err, errNotCollection) {
+		t.Errorf("got %v for items that aren't objects, want errNotCollection", err)
+	}
+}
+
+type stringList []string
+
+func (l stringList) Items() any {
+	return []string(l)
+}
+
+// xmlNode is any element, to decode XML without knowing its shape.
+type xmlNode struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Text     string     `xml:",chardata"`
+	Children []xmlNode  `xml:",any"`
+}
+
+func (n xmlNode) child(name string) xmlNode {
+	for _, c := range n.Children {
+		if c.XMLName.Local == name {
+			return c
+		}
+	}
+	return xmlNode{}
+}
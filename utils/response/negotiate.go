@@ -0,0 +1,206 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Collection is implemented by responses that list items, which lets them
+// be rendered as CSV with one row per item.
+type Collection interface {
+	Items() any
+}
+
+// format is a representation Write can render.
+type format struct {
+	// types it is requested by, the first one is sent as Content-Type
+	types []string
+	// problemType is sent instead for a Problem, if the format has one
+	problemType string
+	encode      func(w io.Writer, data any) error
+}
+
+const (
+	problemJSON = "application/problem+json"
+	problemXML  = "application/problem+xml"
+)
+
+// formats in order of preference when the client accepts several equally.
+var formats = []format{
+	{[]string{"application/json", "application/problem+json"}, problemJSON, encodeJSON},
+	{[]string{"application/xml", "text/xml", "application/problem+xml"}, problemXML, encodeXML},
+	{[]string{"application/yaml", "application/x-yaml", "text/yaml"}, "", encodeYAML},
+	{[]string{"text/csv"}, "", encodeCSV},
+	{[]string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}, "", encodeMsgpack},
+}
+
+func encodeJSON(w io.Writer, data any) error {
+	return json.NewEncoder(w).Encode(data)
+}
+
+// Write renders data in the format the request's Accept header prefers:
+// JSON, XML, YAML, CSV (collections only) or MessagePack. JSON is used
+// without an Accept header. If nothing acceptable can be rendered it
+// responds 406, except for errors, which fall back to JSON so the client
+// still learns what went wrong.
+func Write(w http.ResponseWriter, r *http.Request, status int, data any) error {
+	w.Header().Add("Vary", "Accept")
+
+	for _, f := range negotiate(r.Header.Get("Accept")) {
+		var body bytes.Buffer
+		err := f.encode(&body, data)
+		if err == errNotCollection {
+			continue
+		}
+		if err != nil {
+			return writeJSON(w, http.StatusInternalServerError, problemJSON, internalError(w, r, err))
+		}
+
+		contentType := f.types[0]
+		if _, ok := data.(*Problem); ok && f.problemType != "" {
+			contentType = f.problemType
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		_, err = w.Write(body.Bytes())
+		return err
+	}
+
+	if p, ok := data.(*Problem); ok {
+		return writeJSON(w, status, problemJSON, p)
+	}
+	if status >= http.StatusBadRequest {
+		return Writejson(w, status, data)
+	}
+	return writeJSON(w, http.StatusNotAcceptable, problemJSON, &Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(http.StatusNotAcceptable),
+		Status:    http.StatusNotAcceptable,
+		Detail:    "none of the accepted media types can be produced, supported: " + strings.Join(Supported(), ", "),
+		Instance:  r.URL.Path,
+		RequestID: w.Header().Get("X-Request-ID"),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, contentType string, data any) error {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(data)
+}
+
+func internalError(w http.ResponseWriter, r *http.Request, err error) *Problem {
+	return &Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(http.StatusInternalServerError),
+		Status:    http.StatusInternalServerError,
+		Detail:    err.Error(),
+		Instance:  r.URL.Path,
+		RequestID: w.Header().Get("X-Request-ID"),
+	}
+}
+
+// Supported lists the media types Write can produce.
+func Supported() []string {
+	var types []string
+	for _, f := range formats {
+		types = append(types, f.types...)
+	}
+	return types
+}
+
+// negotiate returns the acceptable formats, best first.
+func negotiate(accept string) []format {
+	if strings.TrimSpace(accept) == "" {
+		return formats[:1]
+	}
+
+	ranges := parseAccept(accept)
+	type candidate struct {
+		format format
+		q      float64
+	}
+	var candidates []candidate
+	for _, f := range formats {
+		// the most specific range matching any of the types decides, so
+		// "*/*, application/json;q=0" rules out JSON altogether
+		q, specificity := 0.0, -1
+		for _, t := range f.types {
+			tq, ts := acceptQuality(ranges, t)
+			if ts > specificity || ts == specificity && tq > q {
+				q, specificity = tq, ts
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{f, q})
+		}
+	}
+
+	// stable, so equally acceptable formats stay in order of preference
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+
+	acceptable := make([]format, len(candidates))
+	for i, c := range candidates {
+		acceptable[i] = c.format
+	}
+	return acceptable
+}
+
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok {
+			continue
+		}
+
+		q := 1.0
+		if str, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(str, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, q: q})
+	}
+	return ranges
+}
+
+// acceptQuality is the q value of the most specific range matching
+// mediaType, and how specific that range is: 2 for the exact type, 1 for
+// type/*, 0 for */* and -1 when nothing matches.
+func acceptQuality(ranges []mediaRange, mediaType string) (float64, int) {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		s := -1
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*" && r.subtype == "*":
+			s = 0
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q, specificity
+}
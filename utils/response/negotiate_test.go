@@ -0,0 +1,129 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		// the first media type of each acceptable format, best first
+		want []string
+	}{
+		{"", []string{"application/json"}},
+		{"   ", []string{"application/json"}},
+		{"application/xml", []string{"application/xml"}},
+		{"text/xml", []string{"application/xml"}},
+		{"application/problem+json", []string{"application/json"}},
+		{"application/x-msgpack", []string{"application/msgpack"}},
+		{"APPLICATION/YAML", []string{"application/yaml"}},
+		// the highest q wins, whatever the order of the header
+		{"application/yaml;q=0.5, application/xml;q=0.9", []string{"application/xml", "application/yaml"}},
+		{"application/json; q=0.1, text/csv", []string{"text/csv", "application/json"}},
+		// equal q values keep the order of preference
+		{"application/msgpack, application/json", []string{"application/json", "application/msgpack"}},
+		{"*/*", []string{"application/json", "application/xml", "application/yaml", "text/csv", "application/msgpack"}},
+		{"text/*", []string{"application/xml", "application/yaml", "text/csv"}},
+		{"application/*;q=0.5, text/csv", []string{"text/csv", "application/json", "application/xml", "application/yaml", "application/msgpack"}},
+		// a more specific range overrides a wildcard, also with q=0
+		{"*/*;q=0.1, application/yaml", []string{"application/yaml", "application/json", "application/xml", "text/csv", "application/msgpack"}},
+		{"*/*, application/json;q=0", []string{"application/xml", "application/yaml", "text/csv", "application/msgpack"}},
+		// a format counts with the best q of its media types
+		{"text/xml;q=0.2, application/xml;q=0.8, application/json;q=0.5", []string{"application/xml", "application/json"}},
+		{"image/png", nil},
+		{"application/json;q=0", nil},
+		// malformed ranges and q values are skipped
+		{"garbage, application/xml", []string{"application/xml"}},
+		{"application/xml;q=2, application/yaml;q=abc, text/csv;q=-1", nil},
+		{"json", nil},
+		{";;;, application/json;q=0.3", []string{"application/json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			var got []string
+			for _, f := range negotiate(tt.accept) {
+				got = append(got, f.types[0])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("negotiate(%q) = %q, want %q", tt.accept, got, tt.want)
+			}
+		})
+	}
+}
+
+func write(t *testing.T, accept string, status int, data any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/things", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	if err := Write(rec, req, status, data); err != nil {
+		t.Fatal(err)
+	}
+	return rec
+}
+
+func TestWriteNegotiates(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		status      int
+		data        any
+		wantStatus  int
+		contentType string
+	}{
+		{"default", "", http.StatusOK, testData, http.StatusOK, "application/json"},
+		{"xml", "text/xml", http.StatusCreated, testData, http.StatusCreated, "application/xml"},
+		{"csv collection", "text/csv", http.StatusOK, testData, http.StatusOK, "text/csv"},
+		{"msgpack", "application/vnd.msgpack", http.StatusOK, testData, http.StatusOK, "application/msgpack"},
+		// CSV can't render a single object, the next acceptable format does
+		{"csv fallback", "text/csv, application/yaml;q=0.5", http.StatusOK, testItem{}, http.StatusOK, "application/yaml"},
+		{"not acceptable", "image/png", http.StatusOK, testData, http.StatusNotAcceptable, "application/problem+json"},
+		{"csv only", "text/csv", http.StatusOK, testItem{}, http.StatusNotAcceptable, "application/problem+json"},
+		// errors are still sent, as JSON
+		{"error", "image/png", http.StatusNotFound, Response{Error: "gone"}, http.StatusNotFound, "application/json"},
+		{"problem", "image/png", http.StatusConflict, &Problem{Title: "Conflict", Status: 409}, http.StatusConflict, "application/problem+json"},
+		{"problem xml", "application/xml", http.StatusConflict, &Problem{Title: "Conflict", Status: 409}, http.StatusConflict, "application/problem+xml"},
+		{"problem yaml", "application/yaml", http.StatusConflict, &Problem{Title: "Conflict", Status: 409}, http.StatusConflict, "application/yaml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := write(t, tt.accept, tt.status, tt.data)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type %q, want %q", got, tt.contentType)
+			}
+			if got := rec.Header().Get("Vary"); got != "Accept" {
+				t.Errorf("Vary %q, want Accept", got)
+			}
+		})
+	}
+}
+
+func TestWriteNotAcceptableProblem(t *testing.T) {
+	rec := write(t, "image/png, text/html", http.StatusOK, testData)
+
+	var p Problem
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != http.StatusNotAcceptable || p.Instance != "/api/things" {
+		t.Errorf("problem is %+v", p)
+	}
+	for _, typ := range Supported() {
+		if !strings.Contains(p.Detail, typ) {
+			t.Errorf("detail %q doesn't list %s", p.Detail, typ)
+		}
+	}
+}
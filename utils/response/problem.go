@@ -0,0 +1,200 @@
+package response
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Problem is an RFC 7807 problem details object, the body of every error
+// response.
+type Problem struct {
+	// Type is a URI for the kind of problem, about:blank when the status says it all
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// Instance is the path of the request that failed
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	// Errors lists the invalid fields of a validation problem
+	Errors []FieldError `json:"errors,omitempty"`
+	// Reasons lists the rules a valid request broke, when there can be
+	// more than one
+	Reasons []Reason `json:"reasons,omitempty"`
+}
+
+// Reason describes one rule a request broke.
+type Reason struct {
+	// Code names the rule, e.g. prerequisite
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Details says what broke the rule, its shape depends on the code
+	Details any `json:"details,omitempty"`
+}
+
+// FieldError describes one invalid field of a request body.
+type FieldError struct {
+	// Field is the JSON name of the field
+	Field string `json:"field"`
+	// Tag is the rule that failed, e.g. required or email
+	Tag string `json:"tag"`
+	// Param is the parameter of the rule, e.g. the 8 of min=8
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+const ValidationProblemType = "/problems/validation"
+
+// Error responds with a problem of the given status, with detail as its
+// human readable explanation.
+func Error(w http.ResponseWriter, r *http.Request, status int, detail string) error {
+	return WriteProblem(w, r, &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	})
+}
+
+// ValidationFailed responds 400 with the invalid fields.
+func ValidationFailed(w http.ResponseWriter, r *http.Request, errs []FieldError) error {
+	return WriteProblem(w, r, &Problem{
+		Type:   ValidationProblemType,
+		Title:  "Validation failed",
+		Status: http.StatusBadRequest,
+		Detail: "one or more fields are invalid",
+		Errors: errs,
+	})
+}
+
+// WriteProblem fills in the instance and request id of p, translates its
+// title and detail into the request's language and writes it.
+func WriteProblem(w http.ResponseWriter, r *http.Request, p *Problem) error {
+	t := translatorFrom(r.Context())
+	p.Title = t.Message(p.Title)
+	p.Detail = t.Message(p.Detail)
+	for i := range p.Reasons {
+		p.Reasons[i].Message = t.Message(p.Reasons[i].Message)
+	}
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+	if p.RequestID == "" {
+		p.RequestID = w.Header().Get("X-Request-ID")
+	}
+	return Write(w, r, p.Status, p)
+}
+
+// FieldErrors describes the fields that failed validation, in the language
+// of ctx. The field names are the ones registered with the validator's tag
+// name function, nested fields are named by their path, e.g.
+// guardians[0].phone_number.
+func FieldErrors(ctx context.Context, err error) []FieldError {
+	t := translatorFrom(ctx)
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return []FieldError{{Field: "", Tag: "invalid", Message: t.Message(err.Error())}}
+	}
+
+	errs := make([]FieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		msg, ok := t.FieldError(fe)
+		if !ok {
+			msg = fieldMessage(fe)
+		}
+		errs = append(errs, FieldError{
+			Field:   fieldPath(fe),
+			Tag:     fe.Tag(),
+			Param:   fe.Param(),
+			Message: msg,
+		})
+	}
+	return errs
+}
+
+// fieldPath is the namespace of fe without the name of the request struct.
+func fieldPath(fe validator.FieldError) string {
+	_, path, ok := strings.Cut(fe.Namespace(), ".")
+	if !ok {
+		return fe.Field()
+	}
+	return path
+}
+
+// NewFieldError describes a rule checked outside the validator, in the
+// language of ctx. message is the English explanation.
+func NewFieldError(ctx context.Context, field, tag, param, message string) FieldError {
+	if msg, ok := translatorFrom(ctx).Rule(tag, field, param); ok {
+		message = msg
+	}
+	return FieldError{Field: field, Tag: tag, Param: param, Message: message}
+}
+
+// fieldMessage explains a failed rule, there is a message for every tag the
+// API uses and the common ones a new field might use.
+func fieldMessage(fe validator.FieldError) string {
+	field, param := fe.Field(), fe.Param()
+
+	// size rules count characters for strings and items for lists
+	unit := ""
+	switch fe.Kind().String() {
+	case "string":
+		unit = " characters"
+	case "slice", "array", "map":
+		unit = " items"
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return field + " is required"
+	case "email":
+		return field + " must be a valid email address"
+	case "min":
+		return fmt.Sprintf("%s must be at least %s%s", field, param, unit)
+	case "max":
+		return fmt.Sprintf("%s must be at most %s%s", field, param, unit)
+	case "len":
+		return fmt.Sprintf("%s must be exactly %s%s", field, param, unit)
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, param)
+	case "gte":
+		return fmt.Sprintf("%s must be %s or more", field, param)
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s", field, param)
+	case "lte":
+		return fmt.Sprintf("%s must be %s or less", field, param)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, param)
+	case "numeric", "number":
+		return field + " must be a number"
+	case "alpha":
+		return field + " must contain only letters"
+	case "alphanum":
+		return field + " must contain only letters and digits"
+	case "url":
+		return field + " must be a valid URL"
+	case "uuid":
+		return field + " must be a valid UUID"
+	case "e164":
+		return field + " must be a phone number in E.164 format, e.g. +14155552671"
+	case "datetime":
+		return fmt.Sprintf("%s must be a date in the %s format", field, param)
+	case "eqfield":
+		return fmt.Sprintf("%s must match %s", field, param)
+	case "nefield":
+		return fmt.Sprintf("%s must differ from %s", field, param)
+	case "iso3166_1_alpha2":
+		return field + " must be a two-letter ISO 3166-1 country code"
+	default:
+		if param != "" {
+			return fmt.Sprintf("%s failed the %s=%s rule", field, fe.Tag(), param)
+		}
+		return fmt.Sprintf("%s failed the %s rule", field, fe.Tag())
+	}
+}
@@ -2,50 +2,19 @@ package response
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
-	"strings"
-
-	"github.com/go-playground/validator/v10"
 )
 
+// Response carries a confirmation message, errors are sent as a Problem.
 type Response struct {
 	Status string `json:"status"`
 	Error  string `json:"error"`
 }
 
-const (
-	StatusOK    = "OK"
-	StatusError = "Error"
-)
+const StatusOK = "OK"
 
 func Writejson(w http.ResponseWriter, status int, data interface{}) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	return json.NewEncoder(w).Encode(data)
 }
-
-func GeneralError(err error) Response {
-	return Response{
-		Status: StatusError,
-		Error:  err.Error(),
-	}
-}
-
-func ValidationError(err validator.ValidationErrors) Response {
-	var errMsgs []string
-
-	for _, err := range err {
-		switch err.ActualTag() {
-		case "required":
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is required field", err.Field()))
-		default:
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is  invalid", err.Field()))
-		}
-	}
-
-	return Response{
-		Status: StatusError,
-		Error:  strings.Join(errMsgs, ","),
-	}
-}
@@ -0,0 +1,51 @@
+package response
+
+import (
+	"context"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Translator localizes the messages of error responses.
+type Translator interface {
+	// Message translates an English message, returning it as is if it can't
+	Message(msg string) string
+	// Rule explains a rule checked outside the validator
+	Rule(tag, field, param string) (string, bool)
+	// FieldError explains an error of the validator
+	FieldError(fe validator.FieldError) (string, bool)
+}
+
+type translatorKey struct{}
+
+// WithTranslator makes the problems written for ctx use t.
+func WithTranslator(ctx context.Context, t Translator) context.Context {
+	return context.WithValue(ctx, translatorKey{}, t)
+}
+
+// Translate translates an English message into the language of ctx.
+func Translate(ctx context.Context, msg string) string {
+	return translatorFrom(ctx).Message(msg)
+}
+
+// english is used when nothing else is set, the messages are written in it.
+type english struct{}
+
+func (english) Message(msg string) string {
+	return msg
+}
+
+func (english) Rule(tag, field, param string) (string, bool) {
+	return "", false
+}
+
+func (english) FieldError(fe validator.FieldError) (string, bool) {
+	return fieldMessage(fe), true
+}
+
+func translatorFrom(ctx context.Context) Translator {
+	if t, ok := ctx.Value(translatorKey{}).(Translator); ok {
+		return t
+	}
+	return english{}
+}